@@ -1,8 +1,42 @@
 package main
 
-import "github.com/MaKcm14/sub-pub/internal/app"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MaKcm14/sub-pub/internal/app"
+	"github.com/MaKcm14/sub-pub/internal/config"
+)
 
 func main() {
-	s := app.NewService()
+	flags, err := config.ParseFlags(os.Args[0], os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	} else if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	conf, err := config.New(flags)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if flags.PrintConfig {
+		if err := conf.Print(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	s, err := app.NewService(flags, conf)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	s.Start()
 }
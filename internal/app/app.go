@@ -1,63 +1,202 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"os/signal"
+	"slices"
+	"sync"
 	"syscall"
 
 	"github.com/MaKcm14/sub-pub/internal/config"
 	"github.com/MaKcm14/sub-pub/internal/controller/spserv"
+	"github.com/MaKcm14/sub-pub/internal/logger"
+	"github.com/MaKcm14/sub-pub/internal/metrics"
 	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"google.golang.org/grpc"
 )
 
 // Service defines the main sub-pub service's builder.
 type Service struct {
-	log     *slog.Logger
-	logFile *os.File
+	log *slog.Logger
 
-	serv spserv.SubPubService
+	// logCloser releases the log's output.
+	logCloser io.Closer
+
+	// level defines the log's min level that is changed by the reloading.
+	level *slog.LevelVar
+
+	// flags defines the command-line flags that the config is reloaded with.
+	flags config.Flags
+
+	// conf defines the effective config.
+	conf config.Config
+
+	// reloadMut serializes the config's reloadings.
+	reloadMut sync.Mutex
+
+	serv   spserv.SubPubService
+	subPub subpub.SubPub
+
+	// auth and accounts are nil if the AuthFile isn't set.
+	auth     *spserv.Auth
+	accounts *subpub.Accounts
+
+	// rates is nil if the RateLimitsFile isn't set.
+	rates *spserv.RateLimits
+
+	// policies is nil if the PoliciesFile isn't set.
+	policies *spserv.SubjectPolicies
+
+	// metricsServ defines the server of the metrics' endpoint (nil means no metrics).
+	metricsServ *metrics.Server
 }
 
-func NewService() Service {
+// NewService builds the sub-pub service of the config, the flags are used for the config's reloading.
+// The resources that were built before the error are released.
+func NewService(flags config.Flags, conf config.Config) (*Service, error) {
 	const op = "app.NewService"
 
-	logFile, err := os.Create("../../logs/main_log_file.txt")
+	s := &Service{
+		flags: flags,
+		conf:  conf,
+		level: new(slog.LevelVar),
+	}
+	s.level.Set(conf.LogLevel)
 
+	log, logCloser, err := logger.New(logger.Config{
+		Output:     conf.LogOutput,
+		Format:     conf.LogFormat,
+		Level:      s.level,
+		MaxSize:    conf.LogMaxSize,
+		MaxAge:     conf.LogMaxAge,
+		MaxBackups: conf.LogMaxBackups,
+		Retention:  conf.LogRetention,
+	})
 	if err != nil {
-		panic(fmt.Sprintf("error of the %s: %s", op, err))
+		return nil, fmt.Errorf("error of the %s: %w", op, err)
 	}
-	log := slog.New(slog.NewTextHandler(logFile, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	s.log, s.logCloser = log, logCloser
 
-	log.Info("configuring the service begun")
+	log.Info("configuring the sub-pub service started")
 
-	conf, err := config.New(
-		config.ConfigSocket,
-	)
-	if err != nil {
-		critErr := fmt.Errorf("error of the %s: %s", op, err)
-		log.Error(critErr.Error())
-		panic(critErr)
+	subPubOpts := []subpub.SubPubOpt{
+		subpub.WithDefaultPolicy(subpub.SubjectPolicy{
+			DedupWindow: conf.DedupWindow,
+			DedupCount:  conf.DedupCount,
+		}),
+	}
+	if conf.ScheduleStore != "" {
+		store, err := subpub.NewFileScheduleStore(conf.ScheduleStore)
+		if err != nil {
+			return nil, s.fail(op, err)
+		}
+		subPubOpts = append(subPubOpts, subpub.WithScheduleStore(store))
 	}
 
-	log.Info("configuring the sub-pub service started")
+	var servMetrics *metrics.Metrics
+	if conf.MetricsSocket != "" {
+		servMetrics = metrics.New(conf.MetricsMaxSubjects)
+		subPubOpts = append(subPubOpts, subpub.WithObserver(servMetrics, metrics.EventKinds...))
+	}
 
-	service, err := spserv.NewSubPubService(log, conf.Socket,
-		spserv.NewSubPubServer(log, subpub.NewSubPub()),
-	)
+	serviceOpts := make([]spserv.ServiceOpt, 0, 5)
+	if servMetrics != nil {
+		serviceOpts = append(serviceOpts, spserv.WithServerOptions(
+			grpc.ChainUnaryInterceptor(servMetrics.UnaryInterceptor),
+			grpc.ChainStreamInterceptor(servMetrics.StreamInterceptor),
+		))
+	}
+	serverOpts := []spserv.ServerOpt{spserv.WithReload(s.reload), spserv.WithHeartbeat(conf.HeartbeatInterval)}
+	if conf.AdminSocket != "" {
+		serviceOpts = append(serviceOpts, spserv.WithAdminSocket(conf.AdminSocket))
+	}
+	serviceOpts = append(serviceOpts, spserv.WithLimits(spserv.LimitsConfig{
+		MaxRecvMsgSize:               conf.GRPCMaxRecvMsgSize,
+		MaxSendMsgSize:               conf.GRPCMaxSendMsgSize,
+		MaxConcurrentStreams:         uint32(conf.GRPCMaxConcurrentStreams),
+		MaxConnections:               conf.GRPCMaxConnections,
+		KeepaliveTime:                conf.GRPCKeepaliveTime,
+		KeepaliveTimeout:             conf.GRPCKeepaliveTimeout,
+		KeepaliveMinTime:             conf.GRPCKeepaliveMinTime,
+		KeepalivePermitWithoutStream: conf.GRPCKeepalivePermitWithoutStream,
+		MaxConnectionIdle:            conf.GRPCMaxConnectionIdle,
+		MaxConnectionAge:             conf.GRPCMaxConnectionAge,
+		MaxConnectionAgeGrace:        conf.GRPCMaxConnectionAgeGrace,
+	}))
+	if conf.TLSCert != "" {
+		serviceOpts = append(serviceOpts, spserv.WithTLS(spserv.TLSConfig{
+			CertFile:          conf.TLSCert,
+			KeyFile:           conf.TLSKey,
+			ClientCAFile:      conf.TLSClientCA,
+			RequireClientCert: conf.TLSRequireClientCert,
+		}))
+	}
+	subPub := subpub.NewSubPub(subPubOpts...)
+	s.subPub = subPub
+
+	if conf.PoliciesFile != "" {
+		policies, err := spserv.NewSubjectPolicies(conf.PoliciesFile, subPub)
+		if err != nil {
+			return nil, s.fail(op, err)
+		}
+		s.policies = policies
+	}
+
+	if conf.AuthFile != "" {
+		auth, err := spserv.NewAuth(log, conf.AuthFile)
+		if err != nil {
+			return nil, s.fail(op, err)
+		}
+		serviceOpts = append(serviceOpts, spserv.WithAuthentication(auth))
+		serverOpts = append(serverOpts, spserv.WithAuthorization(auth))
+		s.auth = auth
+
+		if len(auth.Accounts()) != 0 {
+			accounts, err := subpub.NewAccounts(subPub, subpub.NewRealClock(), auth.Accounts()...)
+			if err != nil {
+				return nil, s.fail(op, err)
+			}
+			serverOpts = append(serverOpts, spserv.WithAccounts(accounts))
+			s.accounts = accounts
+		}
+	}
+
+	if conf.RateLimitsFile != "" {
+		rates, err := spserv.NewRateLimits(conf.RateLimitsFile)
+		if err != nil {
+			return nil, s.fail(op, err)
+		}
+		serverOpts = append(serverOpts, spserv.WithRateLimits(rates))
+		s.rates = rates
+	}
+
+	server := spserv.NewSubPubServer(log, subPub, serverOpts...)
+	service, err := spserv.NewSubPubService(log, conf.Socket, server, serviceOpts...)
 
 	if err != nil {
-		critErr := fmt.Errorf("error of the %s: %s", op, err)
-		log.Error(critErr.Error())
-		panic(critErr)
+		return nil, s.fail(op, err)
 	}
 
-	return Service{
-		log:     log,
-		logFile: logFile,
-		serv:    service,
+	var metricsServ *metrics.Server
+	if servMetrics != nil {
+		servMetrics.WatchSubPub(subPub)
+		servMetrics.WatchSubscriptions(server.ActiveSubscriptions)
+
+		metricsServ, err = metrics.NewServer(log, conf.MetricsSocket, servMetrics)
+		if err != nil {
+			service.Close()
+			return nil, s.fail(op, err)
+		}
 	}
+
+	s.serv = service
+	s.metricsServ = metricsServ
+
+	return s, nil
 }
 
 // Start starts the sub-pub service.
@@ -67,15 +206,144 @@ func (s *Service) Start() {
 	s.log.Info("starting the service")
 	go s.serv.Run()
 
+	if s.metricsServ != nil {
+		go s.metricsServ.Run()
+	}
+
 	sig := make(chan os.Signal, 3)
-	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT, syscall.SIGHUP)
+
+	for <-sig == syscall.SIGHUP {
+		s.log.Info("the config's reloading was requested by the SIGHUP")
+		s.reload()
+	}
+}
+
+// reload applies the changes of the config that are safe at runtime: the log's level, the default
+// subjects' policy, the subjects' own policies, the auth file's ACLs and accounts' limits, the rate limits
+// and the TLS certificates. All the files are validated before any change is applied, so the failed
+// reloading keeps the previous config. The other changed settings are rejected and stay the same untill the restart.
+func (s *Service) reload() (spserv.ReloadReport, error) {
+	const op = "app.Service.reload"
+
+	s.reloadMut.Lock()
+	defer s.reloadMut.Unlock()
+
+	conf, err := config.New(s.flags)
+	if err != nil {
+		s.log.Error("the config's reloading failed", slog.String("op", op), slog.Any("error", err))
+		return spserv.ReloadReport{}, err
+	}
+
+	files := s.reloadedFiles()
+	steps := make([]spserv.ReloadStep, 0, len(files))
+
+	for _, file := range files {
+		step, err := file.prepare()
+		if err != nil {
+			s.log.Error("the config's reloading failed", slog.String("op", op), slog.String("setting", file.key), slog.Any("error", err))
+			return spserv.ReloadReport{}, err
+		}
+		steps = append(steps, step)
+	}
+
+	changed := config.Changed(s.conf, conf)
+	report := spserv.ReloadReport{}
+
+	// the files of the same paths are re-read, the changed paths are rejected below
+	for i, step := range steps {
+		step.Apply()
+
+		if step.Changed && !slices.Contains(changed, files[i].key) {
+			report.Applied = append(report.Applied, files[i].key)
+		}
+	}
+
+	policyChanged := false
+	for _, key := range changed {
+		switch key {
+		case "log_level":
+			s.level.Set(conf.LogLevel)
+			s.conf.LogLevel = conf.LogLevel
 
-	<-sig
+		case "dedup_window", "dedup_count":
+			s.conf.DedupWindow, s.conf.DedupCount = conf.DedupWindow, conf.DedupCount
+			policyChanged = true
+
+		default:
+			report.Rejected = append(report.Rejected, key)
+			continue
+		}
+		report.Applied = append(report.Applied, key)
+	}
+
+	if policyChanged {
+		s.subPub.SetPolicy("", subpub.SubjectPolicy{
+			DedupWindow: s.conf.DedupWindow,
+			DedupCount:  s.conf.DedupCount,
+		})
+	}
+
+	if len(report.Rejected) != 0 {
+		s.log.Warn("the settings can't be changed without the restart", slog.Any("rejected", report.Rejected))
+	}
+	s.log.Info("the config was reloaded", slog.Any("applied", report.Applied))
+
+	return report, nil
+}
+
+// reloadedFile defines the file that is re-read by the config's reloading.
+type reloadedFile struct {
+	// key defines the setting of the file's path.
+	key string
+
+	prepare func() (spserv.ReloadStep, error)
+}
+
+// reloadedFiles returns the files of the service that are re-read by the config's reloading.
+func (s *Service) reloadedFiles() []reloadedFile {
+	files := make([]reloadedFile, 0, 4)
+
+	if s.conf.TLSCert != "" {
+		files = append(files, reloadedFile{key: "tls_cert", prepare: s.serv.PrepareTLS})
+	}
+
+	if s.auth != nil {
+		files = append(files, reloadedFile{key: "auth_file", prepare: func() (spserv.ReloadStep, error) {
+			return s.auth.Prepare(s.accounts)
+		}})
+	}
+
+	if s.rates != nil {
+		files = append(files, reloadedFile{key: "rate_limits_file", prepare: s.rates.Prepare})
+	}
+
+	if s.policies != nil {
+		files = append(files, reloadedFile{key: "policies_file", prepare: s.policies.Prepare})
+	}
+	return files
 }
 
 // сlose calls the close funcs for releasing the resources.
 func (s *Service) close() {
 	s.serv.Close()
+
+	if s.metricsServ != nil {
+		s.metricsServ.Close(context.Background())
+	}
 	s.log.Info("the service was FULLY STOPPED")
-	s.logFile.Close()
+	s.logCloser.Close()
+}
+
+// fail logs the critical error of the service's building, releases the built resources and returns the error.
+func (s *Service) fail(op string, err error) error {
+	critErr := fmt.Errorf("error of the %s: %w", op, err)
+	s.log.Error("the service can't be built", slog.Any("error", critErr))
+
+	if s.subPub != nil {
+		s.subPub.Close(context.Background())
+	}
+	s.logCloser.Close()
+
+	return critErr
 }
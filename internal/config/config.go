@@ -1,28 +1,333 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"os"
+	"time"
 
 	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
 )
 
 // Config defines the main storage of service's configuration settings.
 type Config struct {
 	// Socket defines the socket that will be used for starting the GRPC-server.
 	Socket string
+
+	// AdminSocket defines the separate socket of the Admin service (empty means the Socket is used).
+	AdminSocket string
+
+	// ScheduleStore defines the path of the scheduled messages' file (empty means no durable store).
+	ScheduleStore string
+
+	// DedupWindow defines the time during which the messages with the same id are deduplicated.
+	DedupWindow time.Duration
+
+	// DedupCount defines the count of the last messages' ids remembered for the deduplication.
+	DedupCount int
+
+	// PoliciesFile defines the path of the subjects' own policies' YAML file (empty means the default policy only).
+	PoliciesFile string
+
+	// TLSCert and TLSKey define the paths of the server's certificate and its key (empty means no TLS).
+	TLSCert string
+	TLSKey  string
+
+	// TLSClientCA defines the path of the CA bundle for the clients' certificates verifying.
+	TLSClientCA string
+
+	// TLSRequireClientCert defines whether the clients must present the valid certificate.
+	TLSRequireClientCert bool
+
+	// AuthFile defines the path of the clients' tokens and ACL's YAML file (empty means no authentication).
+	AuthFile string
+
+	// HeartbeatInterval defines the interval of the Subscribe streams' heartbeats (0 means no heartbeats).
+	HeartbeatInterval time.Duration
+
+	// RateLimitsFile defines the path of the publishing's rate limits' YAML file (empty means no limits).
+	RateLimitsFile string
+
+	// MetricsSocket defines the socket of the HTTP metrics' endpoint (empty means no metrics).
+	MetricsSocket string
+
+	// MetricsMaxSubjects defines the max count of the subjects that get their own metrics' label.
+	MetricsMaxSubjects int
+
+	// LogOutput defines the stdout, the stderr or the log file's path.
+	LogOutput string
+
+	// LogFormat defines the text or the json format of the log's lines.
+	LogFormat string
+
+	// LogLevel defines the min level of the log's lines.
+	LogLevel slog.Level
+
+	// LogMaxSize defines the size of the log file in bytes after which it's rotated (0 means no limit).
+	LogMaxSize int64
+
+	// LogMaxAge defines the age of the log file after which it's rotated (0 means no limit).
+	LogMaxAge time.Duration
+
+	// LogMaxBackups defines the count of the kept rotated log files (0 means all of them).
+	LogMaxBackups int
+
+	// LogRetention defines the age of the rotated log files after which they're removed (0 means no limit).
+	LogRetention time.Duration
+
+	// GRPCMaxRecvMsgSize and GRPCMaxSendMsgSize define the max sizes of the messages in bytes (0 means the grpc's default).
+	GRPCMaxRecvMsgSize int
+	GRPCMaxSendMsgSize int
+
+	// GRPCMaxConcurrentStreams defines the max count of the concurrent streams of the connection (0 means no limit).
+	GRPCMaxConcurrentStreams int
+
+	// GRPCMaxConnections defines the max count of the clients' connections (0 means no limit).
+	GRPCMaxConnections int
+
+	// GRPCKeepaliveTime and GRPCKeepaliveTimeout define the idle time after which the client is pinged
+	// and the time of the ping's answer waiting after which the client is treated as dead.
+	GRPCKeepaliveTime    time.Duration
+	GRPCKeepaliveTimeout time.Duration
+
+	// GRPCKeepaliveMinTime defines the min interval of the clients' pings (0 means the grpc's default).
+	GRPCKeepaliveMinTime time.Duration
+
+	// GRPCKeepalivePermitWithoutStream defines whether the clients may ping the connections without the streams.
+	GRPCKeepalivePermitWithoutStream bool
+
+	// GRPCMaxConnectionIdle defines the time after which the connection without the streams is closed (0 means no limit).
+	GRPCMaxConnectionIdle time.Duration
+
+	// GRPCMaxConnectionAge and GRPCMaxConnectionAgeGrace define the time after which the connection is closed
+	// and the time given to its streams to finish (0 means no limit).
+	GRPCMaxConnectionAge      time.Duration
+	GRPCMaxConnectionAgeGrace time.Duration
 }
 
-func New(opts ...ConfigOpt) (Config, error) {
+// defaultEnvFile defines the path of the .env file that is loaded if it exists.
+const defaultEnvFile = "../../.env"
+
+// New returns the config of the layers applied in the order: the defaults, the YAML config file,
+// the env vars (the process's ones take precedence over the .env file's ones) and the flags.
+// The resulting config is validated.
+func New(flags Flags) (Config, error) {
 	const op = "config.New"
 
-	conf := Config{}
-	godotenv.Load("../../.env")
+	conf := Config{
+		HeartbeatInterval:    defaultHeartbeatInterval,
+		MetricsMaxSubjects:   defaultMetricsMaxSubjects,
+		LogLevel:             slog.LevelInfo,
+		GRPCKeepaliveTime:    defaultKeepaliveTime,
+		GRPCKeepaliveTimeout: defaultKeepaliveTimeout,
+	}
 
-	for _, opt := range opts {
-		if err := opt(&conf); err != nil {
+	// the .env file is read on every call, so its changes are seen by the config's reloading
+	envFile := flags.EnvFile
+	if envFile == "" {
+		envFile = defaultEnvFile
+	}
+
+	dotEnv, err := godotenv.Read(envFile)
+	if err != nil && flags.EnvFile != "" {
+		return Config{}, fmt.Errorf("error of the %s: the .env file can't be loaded: %s", op, err)
+	}
+
+	getEnv := func(name string) string {
+		if val := os.Getenv(name); val != "" {
+			return val
+		}
+		return dotEnv[name]
+	}
+
+	path := flags.ConfigFile
+	if path == "" {
+		path = getEnv("CONFIG_FILE")
+	}
+	if path != "" {
+		if err := conf.loadFile(path); err != nil {
 			return Config{}, fmt.Errorf("error of the %s: %s", op, err)
 		}
 	}
 
+	for _, s := range settings {
+		if val := getEnv(s.env()); val != "" {
+			if err := s.set(&conf, val); err != nil {
+				return Config{}, fmt.Errorf("error of the %s: the ENV '%s' %s", op, s.env(), err)
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if val, ok := flags.values[s.key]; ok {
+			if err := s.set(&conf, val); err != nil {
+				return Config{}, fmt.Errorf("error of the %s: the flag '-%s' %s", op, s.flag(), err)
+			}
+		}
+	}
+
+	if err := conf.validate(); err != nil {
+		return Config{}, fmt.Errorf("error of the %s: %s", op, err)
+	}
+
 	return conf, nil
 }
+
+// loadFile applies the settings of the YAML config file, the unknown keys aren't allowed.
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("the config file can't be read: %s", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("the config file '%s' can't be parsed: %s", path, err)
+	}
+
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("the config file '%s' must be the mapping of the settings", path)
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, val := root.Content[i], root.Content[i+1]
+
+		s, ok := lookupSetting(key.Value)
+		if !ok {
+			return fmt.Errorf("the config file's key '%s' at the line %d isn't supported", key.Value, key.Line)
+		}
+
+		if val.Kind != yaml.ScalarNode {
+			return fmt.Errorf("the config file's key '%s' at the line %d must be the scalar", key.Value, key.Line)
+		}
+
+		if err := s.set(c, val.Value); err != nil {
+			return fmt.Errorf("the config file's key '%s' at the line %d %s", key.Value, key.Line, err)
+		}
+	}
+
+	return nil
+}
+
+// validate checks the settings and their combinations.
+func (c *Config) validate() error {
+	if c.Socket == "" {
+		return errors.New("the 'socket' must be set")
+	}
+
+	sockets := map[string]string{"socket": c.Socket, "admin_socket": c.AdminSocket, "metrics_socket": c.MetricsSocket}
+	for _, key := range []string{"socket", "admin_socket", "metrics_socket"} {
+		if sockets[key] == "" {
+			continue
+		}
+
+		if _, _, err := net.SplitHostPort(sockets[key]); err != nil {
+			return fmt.Errorf("the '%s' must be the 'host:port': %s", key, err)
+		}
+	}
+
+	if c.AdminSocket != "" && c.AdminSocket == c.Socket {
+		return errors.New("the 'admin_socket' must differ from the 'socket'")
+	}
+	if c.MetricsSocket != "" && (c.MetricsSocket == c.Socket || c.MetricsSocket == c.AdminSocket) {
+		return errors.New("the 'metrics_socket' must differ from the service's sockets")
+	}
+
+	if (c.TLSCert == "") != (c.TLSKey == "") {
+		return errors.New("the 'tls_cert' and the 'tls_key' must be set together")
+	}
+	if c.TLSCert == "" && c.TLSClientCA != "" {
+		return errors.New("the 'tls_client_ca' can't be set without the 'tls_cert'")
+	}
+	if c.TLSRequireClientCert && c.TLSClientCA == "" {
+		return errors.New("the 'tls_require_client_cert' requires the 'tls_client_ca'")
+	}
+
+	if c.LogFormat != "" && c.LogFormat != "text" && c.LogFormat != "json" {
+		return errors.New("the 'log_format' must be the 'text' or the 'json'")
+	}
+
+	// the settings reject the negative values, but the Config may be built without them
+	numbers := []struct {
+		key string
+		val int64
+	}{
+		{"dedup_window", int64(c.DedupWindow)},
+		{"dedup_count", int64(c.DedupCount)},
+		{"heartbeat_interval", int64(c.HeartbeatInterval)},
+		{"metrics_max_subjects", int64(c.MetricsMaxSubjects)},
+		{"log_max_size", c.LogMaxSize},
+		{"log_max_age", int64(c.LogMaxAge)},
+		{"log_max_backups", int64(c.LogMaxBackups)},
+		{"log_retention", int64(c.LogRetention)},
+		{"grpc_max_recv_msg_size", int64(c.GRPCMaxRecvMsgSize)},
+		{"grpc_max_send_msg_size", int64(c.GRPCMaxSendMsgSize)},
+		{"grpc_max_concurrent_streams", int64(c.GRPCMaxConcurrentStreams)},
+		{"grpc_max_connections", int64(c.GRPCMaxConnections)},
+		{"grpc_keepalive_time", int64(c.GRPCKeepaliveTime)},
+		{"grpc_keepalive_timeout", int64(c.GRPCKeepaliveTimeout)},
+		{"grpc_keepalive_min_time", int64(c.GRPCKeepaliveMinTime)},
+		{"grpc_max_connection_idle", int64(c.GRPCMaxConnectionIdle)},
+		{"grpc_max_connection_age", int64(c.GRPCMaxConnectionAge)},
+		{"grpc_max_connection_age_grace", int64(c.GRPCMaxConnectionAgeGrace)},
+	}
+	for _, n := range numbers {
+		if n.val < 0 {
+			return fmt.Errorf("the '%s' mustn't be negative", n.key)
+		}
+	}
+
+	if int64(c.GRPCMaxConcurrentStreams) > math.MaxUint32 {
+		return fmt.Errorf("the 'grpc_max_concurrent_streams' mustn't be greater than %d", uint32(math.MaxUint32))
+	}
+	if c.GRPCKeepaliveTime != 0 && c.GRPCKeepaliveTime < time.Second {
+		return errors.New("the 'grpc_keepalive_time' mustn't be less than 1s")
+	}
+	if c.GRPCMaxConnectionAgeGrace != 0 && c.GRPCMaxConnectionAge == 0 {
+		return errors.New("the 'grpc_max_connection_age_grace' can't be set without the 'grpc_max_connection_age'")
+	}
+
+	return nil
+}
+
+// Changed returns the keys of the settings that differ in the configs.
+func Changed(prev, next Config) []string {
+	keys := make([]string, 0, 1)
+
+	for _, s := range settings {
+		if s.get(&prev) != s.get(&next) {
+			keys = append(keys, s.key)
+		}
+	}
+	return keys
+}
+
+// Print writes the effective config in the format of the YAML config file.
+func (c Config) Print(w io.Writer) error {
+	const op = "config.Print"
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, s := range settings {
+		val := &yaml.Node{Kind: yaml.ScalarNode, Value: s.get(&c)}
+		if val.Value == "" {
+			val.Style = yaml.DoubleQuotedStyle
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: s.key}, val)
+	}
+
+	enc := yaml.NewEncoder(w)
+	if err := enc.Encode(root); err != nil {
+		return fmt.Errorf("error of the %s: %s", op, err)
+	}
+	return enc.Close()
+}
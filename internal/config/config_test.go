@@ -0,0 +1,162 @@
+package config
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFlags returns the flags of the args with the empty .env file, so the project's one isn't loaded.
+func testFlags(t *testing.T, args ...string) Flags {
+	envFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFile, nil, 0644), "expected nil error of the .env file's writing")
+
+	flags, err := ParseFlags("test", append([]string{"-env-file", envFile}, args...))
+	require.NoError(t, err, "expected nil error of the flags' parsing")
+
+	return flags
+}
+
+// testConfigFile writes the config file and returns its path.
+func testConfigFile(t *testing.T, data string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644), "expected nil error of the config file's writing")
+
+	return path
+}
+
+func TestNewPositiveCases(t *testing.T) {
+	t.Run("TestNewPositiveCases_Layers", func(t *testing.T) {
+		path := testConfigFile(t, "socket: 127.0.0.1:1000\ndedup_window: 5m\ndedup_count: 10\nlog_level: debug\n")
+
+		t.Setenv("DEDUP_COUNT", "20")
+		t.Setenv("LOG_MAX_SIZE", "2")
+
+		conf, err := New(testFlags(t, "-config", path, "-socket", "127.0.0.1:2000"))
+		require.NoError(t, err, "expected nil error of the config's creating")
+
+		assert.Equal(t, "127.0.0.1:2000", conf.Socket, "expected the flag to override the file")
+		assert.Equal(t, 5*time.Minute, conf.DedupWindow, "expected the file's value")
+		assert.Equal(t, 20, conf.DedupCount, "expected the env var to override the file")
+		assert.Equal(t, slog.LevelDebug, conf.LogLevel, "expected the file's level")
+		assert.Equal(t, int64(2<<20), conf.LogMaxSize, "expected the size in megabytes")
+		assert.Equal(t, defaultMetricsMaxSubjects, conf.MetricsMaxSubjects, "expected the default value")
+	})
+
+	t.Run("TestNewPositiveCases_ConfigFileEnv", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", testConfigFile(t, "socket: 127.0.0.1:1000\n"))
+
+		conf, err := New(testFlags(t))
+		require.NoError(t, err, "expected nil error of the config's creating")
+
+		assert.Equal(t, "127.0.0.1:1000", conf.Socket, "expected the file of the CONFIG_FILE")
+	})
+
+	t.Run("TestNewPositiveCases_BoolFlag", func(t *testing.T) {
+		conf, err := New(testFlags(t, "-socket", "127.0.0.1:1000", "-tls-cert", "c", "-tls-key", "k",
+			"-tls-client-ca", "ca", "-tls-require-client-cert"))
+		require.NoError(t, err, "expected nil error of the config's creating")
+
+		assert.True(t, conf.TLSRequireClientCert, "expected the flag without the value to be true")
+	})
+
+	t.Run("TestNewPositiveCases_Print", func(t *testing.T) {
+		conf, err := New(testFlags(t, "-socket", "127.0.0.1:1000", "-dedup-window", "1m", "-log-format", "json"))
+		require.NoError(t, err, "expected nil error of the config's creating")
+
+		var buf bytes.Buffer
+		require.NoError(t, conf.Print(&buf), "expected nil error of the config's printing")
+
+		printed, err := New(testFlags(t, "-config", testConfigFile(t, buf.String())))
+		require.NoError(t, err, "expected the printed config to be the valid config file")
+
+		assert.Equal(t, conf, printed, "expected the same config after the printing")
+	})
+}
+
+func TestNewNegativeCases(t *testing.T) {
+	cases := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+	}{
+		{name: "TestNewNegativeCases_NoSocket"},
+		{name: "TestNewNegativeCases_UnknownKey", file: "socket: 127.0.0.1:1000\nsockett: 127.0.0.1:1000\n"},
+		{name: "TestNewNegativeCases_NotScalar", file: "socket: [127.0.0.1:1000]\n"},
+		{name: "TestNewNegativeCases_FileValue", file: "socket: 127.0.0.1:1000\ndedup_window: soon\n"},
+		{name: "TestNewNegativeCases_EnvValue", env: map[string]string{"DEDUP_COUNT": "-1"}, args: []string{"-socket", "127.0.0.1:1000"}},
+		{name: "TestNewNegativeCases_FlagValue", args: []string{"-socket", "127.0.0.1:1000", "-log-level", "loud"}},
+		{name: "TestNewNegativeCases_BadSocket", args: []string{"-socket", "localhost"}},
+		{name: "TestNewNegativeCases_SameSockets", args: []string{"-socket", "127.0.0.1:1000", "-admin-socket", "127.0.0.1:1000"}},
+		{name: "TestNewNegativeCases_TLSKey", args: []string{"-socket", "127.0.0.1:1000", "-tls-cert", "c"}},
+		{name: "TestNewNegativeCases_LogFormat", args: []string{"-socket", "127.0.0.1:1000", "-log-format", "xml"}},
+		{name: "TestNewNegativeCases_NegativeStreams", file: "socket: 127.0.0.1:1000\ngrpc_max_concurrent_streams: -1\n"},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			args := test.args
+			if test.file != "" {
+				args = append(args, "-config", testConfigFile(t, test.file))
+			}
+			for name, val := range test.env {
+				t.Setenv(name, val)
+			}
+
+			_, err := New(testFlags(t, args...))
+			assert.Error(t, err, "expected the config's error")
+		})
+	}
+}
+
+func TestValidateNegativeValues(t *testing.T) {
+	cases := []struct {
+		name  string
+		key   string
+		apply func(c *Config)
+	}{
+		{name: "TestValidateNegativeValues_Streams", key: "grpc_max_concurrent_streams", apply: func(c *Config) { c.GRPCMaxConcurrentStreams = -1 }},
+		{name: "TestValidateNegativeValues_Connections", key: "grpc_max_connections", apply: func(c *Config) { c.GRPCMaxConnections = -1 }},
+		{name: "TestValidateNegativeValues_RecvSize", key: "grpc_max_recv_msg_size", apply: func(c *Config) { c.GRPCMaxRecvMsgSize = -1 }},
+		{name: "TestValidateNegativeValues_SendSize", key: "grpc_max_send_msg_size", apply: func(c *Config) { c.GRPCMaxSendMsgSize = -1 }},
+		{name: "TestValidateNegativeValues_Idle", key: "grpc_max_connection_idle", apply: func(c *Config) { c.GRPCMaxConnectionIdle = -time.Second }},
+		{name: "TestValidateNegativeValues_Age", key: "grpc_max_connection_age", apply: func(c *Config) { c.GRPCMaxConnectionAge = -time.Second }},
+		{name: "TestValidateNegativeValues_MetricsSubjects", key: "metrics_max_subjects", apply: func(c *Config) { c.MetricsMaxSubjects = -1 }},
+	}
+
+	for _, test := range cases {
+		t.Run(test.name, func(t *testing.T) {
+			conf := Config{Socket: "127.0.0.1:1000"}
+			test.apply(&conf)
+
+			err := conf.validate()
+			if assert.Error(t, err, "expected the error of the negative value") {
+				assert.Contains(t, err.Error(), test.key, "expected the error to name the setting")
+			}
+		})
+	}
+}
+
+func TestParseFlagsNegativeCases(t *testing.T) {
+	_, err := ParseFlags("test", []string{"-unknown"})
+	assert.Error(t, err, "expected the error of the unknown flag")
+
+	_, err = ParseFlags("test", []string{"-socket", "127.0.0.1:1000", "extra"})
+	assert.Error(t, err, "expected the error of the unexpected arg")
+}
+
+func TestChanged(t *testing.T) {
+	prev := Config{Socket: "127.0.0.1:1000", LogLevel: slog.LevelInfo, DedupCount: 10}
+	next := prev
+	next.LogLevel, next.DedupCount = slog.LevelDebug, 20
+
+	assert.Equal(t, []string{"dedup_count", "log_level"}, Changed(prev, next), "expected the keys of the changed settings")
+	assert.Empty(t, Changed(prev, prev), "expected no keys of the same configs")
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+)
+
+// Flags defines the command-line flags of the service.
+type Flags struct {
+	// ConfigFile defines the path of the YAML config file (empty means the CONFIG_FILE env var is used).
+	ConfigFile string
+
+	// EnvFile defines the path of the .env file (empty means the default one is used if it exists).
+	EnvFile string
+
+	// PrintConfig defines whether the effective config must be printed instead of the service's starting.
+	PrintConfig bool
+
+	// values defines the values of the settings' flags by the settings' keys.
+	values map[string]string
+}
+
+// ParseFlags parses the command-line args: every setting has its own flag, e.g. the -dedup-window.
+func ParseFlags(name string, args []string) (Flags, error) {
+	const op = "config.ParseFlags"
+
+	flags := Flags{values: make(map[string]string)}
+	set := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	set.StringVar(&flags.ConfigFile, "config", "", "the path of the YAML config file")
+	set.StringVar(&flags.EnvFile, "env-file", "", "the path of the .env file")
+	set.BoolVar(&flags.PrintConfig, "print-config", false, "print the effective config and exit")
+
+	for _, s := range settings {
+		key := s.key
+		value := func(val string) error {
+			flags.values[key] = val
+			return nil
+		}
+
+		if s.isBool {
+			set.BoolFunc(s.flag(), s.usage, value)
+		} else {
+			set.Func(s.flag(), s.usage, value)
+		}
+	}
+
+	if err := set.Parse(args); err != nil {
+		return Flags{}, fmt.Errorf("error of the %s: %w", op, err)
+	}
+
+	if set.NArg() != 0 {
+		return Flags{}, fmt.Errorf("error of the %s: the unexpected args %v", op, set.Args())
+	}
+
+	return flags, nil
+}
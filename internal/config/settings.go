@@ -0,0 +1,189 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultMetricsMaxSubjects defines the default max count of the subjects' metrics' labels.
+	defaultMetricsMaxSubjects = 100
+
+	// defaultHeartbeatInterval defines the default interval of the Subscribe streams' heartbeats.
+	defaultHeartbeatInterval = 30 * time.Second
+
+	// defaultKeepaliveTime and defaultKeepaliveTimeout define the default detecting of the dead clients.
+	defaultKeepaliveTime    = 2 * time.Minute
+	defaultKeepaliveTimeout = 20 * time.Second
+)
+
+// setting defines the single config's value that can be set by the config file's key,
+// by the env var of the key in the upper case and by the flag of the key with the dashes.
+type setting struct {
+	key   string
+	usage string
+
+	// isBool defines whether the flag can be set without the value.
+	isBool bool
+
+	set func(conf *Config, val string) error
+	get func(conf *Config) string
+}
+
+// env returns the name of the setting's env var.
+func (s setting) env() string {
+	return strings.ToUpper(s.key)
+}
+
+// flag returns the name of the setting's flag.
+func (s setting) flag() string {
+	return strings.ReplaceAll(s.key, "_", "-")
+}
+
+// settings defines all the config's settings in the order of their printing.
+var settings = []setting{
+	stringSetting("socket", "the socket of the grpc-server", func(c *Config) *string { return &c.Socket }),
+	stringSetting("admin_socket", "the separate socket of the Admin service", func(c *Config) *string { return &c.AdminSocket }),
+	stringSetting("schedule_store", "the path of the scheduled messages' file", func(c *Config) *string { return &c.ScheduleStore }),
+	durationSetting("dedup_window", "the time of the messages' deduplication", func(c *Config) *time.Duration { return &c.DedupWindow }),
+	intSetting("dedup_count", "the count of the remembered messages' ids", func(c *Config) *int { return &c.DedupCount }),
+	stringSetting("policies_file", "the path of the subjects' own policies' file", func(c *Config) *string { return &c.PoliciesFile }),
+	stringSetting("tls_cert", "the path of the server's certificate", func(c *Config) *string { return &c.TLSCert }),
+	stringSetting("tls_key", "the path of the server certificate's key", func(c *Config) *string { return &c.TLSKey }),
+	stringSetting("tls_client_ca", "the path of the clients' certificates CA bundle", func(c *Config) *string { return &c.TLSClientCA }),
+	boolSetting("tls_require_client_cert", "whether the clients must present the certificate", func(c *Config) *bool { return &c.TLSRequireClientCert }),
+	stringSetting("auth_file", "the path of the clients' tokens and ACL's file", func(c *Config) *string { return &c.AuthFile }),
+	durationSetting("heartbeat_interval", "the interval of the Subscribe streams' heartbeats", func(c *Config) *time.Duration { return &c.HeartbeatInterval }),
+	stringSetting("rate_limits_file", "the path of the publishing's rate limits' file", func(c *Config) *string { return &c.RateLimitsFile }),
+	stringSetting("metrics_socket", "the socket of the HTTP metrics' endpoint", func(c *Config) *string { return &c.MetricsSocket }),
+	intSetting("metrics_max_subjects", "the max count of the subjects' metrics' labels", func(c *Config) *int { return &c.MetricsMaxSubjects }),
+	stringSetting("log_output", "the stdout, the stderr or the log file's path", func(c *Config) *string { return &c.LogOutput }),
+	stringSetting("log_format", "the text or the json format of the log", func(c *Config) *string { return &c.LogFormat }),
+	{
+		key:   "log_level",
+		usage: "the debug, info, warn or error min level of the log",
+		set: func(conf *Config, val string) error {
+			if err := conf.LogLevel.UnmarshalText([]byte(strings.ToUpper(val))); err != nil {
+				return errors.New("must be the 'debug', 'info', 'warn' or 'error'")
+			}
+			return nil
+		},
+		get: func(conf *Config) string {
+			return strings.ToLower(conf.LogLevel.String())
+		},
+	},
+	{
+		key:   "log_max_size",
+		usage: "the size of the log file in megabytes after which it's rotated",
+		set: func(conf *Config, val string) error {
+			n, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || n < 0 {
+				return errors.New("must be the non-negative count of megabytes")
+			}
+			conf.LogMaxSize = n << 20
+
+			return nil
+		},
+		get: func(conf *Config) string {
+			return strconv.FormatInt(conf.LogMaxSize>>20, 10)
+		},
+	},
+	durationSetting("log_max_age", "the age of the log file after which it's rotated", func(c *Config) *time.Duration { return &c.LogMaxAge }),
+	intSetting("log_max_backups", "the count of the kept rotated log files", func(c *Config) *int { return &c.LogMaxBackups }),
+	durationSetting("log_retention", "the age of the rotated log files after which they're removed", func(c *Config) *time.Duration { return &c.LogRetention }),
+	intSetting("grpc_max_recv_msg_size", "the max size of the received message in bytes", func(c *Config) *int { return &c.GRPCMaxRecvMsgSize }),
+	intSetting("grpc_max_send_msg_size", "the max size of the sent message in bytes", func(c *Config) *int { return &c.GRPCMaxSendMsgSize }),
+	intSetting("grpc_max_concurrent_streams", "the max count of the connection's concurrent streams", func(c *Config) *int { return &c.GRPCMaxConcurrentStreams }),
+	intSetting("grpc_max_connections", "the max count of the clients' connections", func(c *Config) *int { return &c.GRPCMaxConnections }),
+	durationSetting("grpc_keepalive_time", "the idle time after which the client is pinged", func(c *Config) *time.Duration { return &c.GRPCKeepaliveTime }),
+	durationSetting("grpc_keepalive_timeout", "the time of the ping's answer after which the client is dead", func(c *Config) *time.Duration { return &c.GRPCKeepaliveTimeout }),
+	durationSetting("grpc_keepalive_min_time", "the min interval of the clients' pings", func(c *Config) *time.Duration { return &c.GRPCKeepaliveMinTime }),
+	boolSetting("grpc_keepalive_permit_without_stream", "whether the clients may ping without the streams", func(c *Config) *bool { return &c.GRPCKeepalivePermitWithoutStream }),
+	durationSetting("grpc_max_connection_idle", "the time after which the connection without the streams is closed", func(c *Config) *time.Duration { return &c.GRPCMaxConnectionIdle }),
+	durationSetting("grpc_max_connection_age", "the time after which the connection is closed", func(c *Config) *time.Duration { return &c.GRPCMaxConnectionAge }),
+	durationSetting("grpc_max_connection_age_grace", "the time given to the old connection's streams to finish", func(c *Config) *time.Duration { return &c.GRPCMaxConnectionAgeGrace }),
+}
+
+// lookupSetting returns the setting by its key.
+func lookupSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+func stringSetting(key, usage string, field func(c *Config) *string) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		set: func(conf *Config, val string) error {
+			*field(conf) = val
+			return nil
+		},
+		get: func(conf *Config) string {
+			return *field(conf)
+		},
+	}
+}
+
+func durationSetting(key, usage string, field func(c *Config) *time.Duration) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		set: func(conf *Config, val string) error {
+			d, err := time.ParseDuration(val)
+			if err != nil || d < 0 {
+				return errors.New("must be the non-negative duration")
+			}
+			*field(conf) = d
+
+			return nil
+		},
+		get: func(conf *Config) string {
+			return field(conf).String()
+		},
+	}
+}
+
+func intSetting(key, usage string, field func(c *Config) *int) setting {
+	return setting{
+		key:   key,
+		usage: usage,
+		set: func(conf *Config, val string) error {
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 0 {
+				return errors.New("must be the non-negative integer")
+			}
+			*field(conf) = n
+
+			return nil
+		},
+		get: func(conf *Config) string {
+			return strconv.Itoa(*field(conf))
+		},
+	}
+}
+
+func boolSetting(key, usage string, field func(c *Config) *bool) setting {
+	return setting{
+		key:    key,
+		usage:  usage,
+		isBool: true,
+		set: func(conf *Config, val string) error {
+			flag, err := strconv.ParseBool(val)
+			if err != nil {
+				return errors.New("must be the boolean")
+			}
+			*field(conf) = flag
+
+			return nil
+		},
+		get: func(conf *Config) string {
+			return strconv.FormatBool(*field(conf))
+		},
+	}
+}
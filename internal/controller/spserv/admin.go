@@ -0,0 +1,192 @@
+package spserv
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// adminServer defines the logic of handling the administrative grpc's requests.
+type adminServer struct {
+	sprpc.UnimplementedAdminServer
+	kern *SubPubServer
+	log  *slog.Logger
+}
+
+// Admin returns the administrative server of the SubPubServer.
+func (s *SubPubServer) Admin() sprpc.AdminServer {
+	return &adminServer{
+		kern: s,
+		log:  s.log,
+	}
+}
+
+// ListSubjects defines the logic of the handling the requests of the subjects' list.
+// The admin's subjects are the shared system's names: with the accounts they have the form '<account>:<subject>'.
+func (a *adminServer) ListSubjects(context.Context, *emptypb.Empty) (*sprpc.SubjectList, error) {
+	return &sprpc.SubjectList{Keys: a.kern.serv.Subjects()}, nil
+}
+
+// GetSubjectStats defines the logic of the handling the requests of the subject's stats.
+func (a *adminServer) GetSubjectStats(_ context.Context, request *sprpc.SubjectRequest) (*sprpc.SubjectStats, error) {
+	stats := a.kern.serv.Stats(request.Key)
+
+	subs := uint32(0)
+	a.kern.subs.Range(func(sub *remoteSub) {
+		if sub.shared == request.Key {
+			subs++
+		}
+	})
+
+	return &sprpc.SubjectStats{
+		Key:           request.Key,
+		Published:     stats.Published,
+		Delivered:     stats.Delivered,
+		Expired:       stats.Expired,
+		Dropped:       stats.Dropped,
+		Duplicates:    stats.Duplicates,
+		Subscriptions: subs,
+		Pending:       stats.Pending,
+	}, nil
+}
+
+// ListSubscriptions defines the logic of the handling the requests of the active subscriptions' list.
+func (a *adminServer) ListSubscriptions(context.Context, *emptypb.Empty) (*sprpc.SubscriptionList, error) {
+	list := &sprpc.SubscriptionList{
+		Subscriptions: make([]*sprpc.SubscriptionInfo, 0, 10),
+	}
+
+	a.kern.subs.Range(func(sub *remoteSub) {
+		list.Subscriptions = append(list.Subscriptions, sub.info())
+	})
+
+	sort.Slice(list.Subscriptions, func(i, j int) bool {
+		return list.Subscriptions[i].Id < list.Subscriptions[j].Id
+	})
+	return list, nil
+}
+
+// PauseSubscription defines the logic of the handling the subscription's pause requests.
+func (a *adminServer) PauseSubscription(ctx context.Context, request *sprpc.SubscriptionRequest) (*emptypb.Empty, error) {
+	const op = "spserv.PauseSubscription"
+
+	sub, err := a.subscription(op, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	sub.pause()
+
+	requestLog(a.log, ctx).Info("the subscription was paused", slog.Int64("subscription_id", request.Id))
+
+	return &emptypb.Empty{}, nil
+}
+
+// ResumeSubscription defines the logic of the handling the subscription's resume requests.
+func (a *adminServer) ResumeSubscription(ctx context.Context, request *sprpc.SubscriptionRequest) (*emptypb.Empty, error) {
+	const op = "spserv.ResumeSubscription"
+
+	sub, err := a.subscription(op, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	sub.resume()
+
+	requestLog(a.log, ctx).Info("the subscription was resumed", slog.Int64("subscription_id", request.Id))
+
+	return &emptypb.Empty{}, nil
+}
+
+// KickSubscription defines the logic of the handling the subscription's forced disconnecting requests.
+func (a *adminServer) KickSubscription(ctx context.Context, request *sprpc.SubscriptionRequest) (*emptypb.Empty, error) {
+	const op = "spserv.KickSubscription"
+
+	sub, err := a.subscription(op, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	a.kern.subs.Delete(request.Id)
+	sub.kick()
+
+	requestLog(a.log, ctx).Info("the subscription was kicked", slog.Int64("subscription_id", request.Id))
+
+	return &emptypb.Empty{}, nil
+}
+
+// PurgeSubject defines the logic of the handling the requests of the subject's queues purging.
+func (a *adminServer) PurgeSubject(ctx context.Context, request *sprpc.SubjectRequest) (*sprpc.PurgeResponse, error) {
+	purged := a.kern.serv.Purge(request.Key)
+
+	requestLog(a.log, ctx).Info("the subject's queues were purged", slog.String("subject", request.Key), slog.Int("purged", purged))
+
+	return &sprpc.PurgeResponse{Purged: uint64(purged)}, nil
+}
+
+// DrainServer defines the logic of the handling the server's draining requests.
+func (a *adminServer) DrainServer(ctx context.Context, request *sprpc.DrainRequest) (*emptypb.Empty, error) {
+	const op = "spserv.DrainServer"
+
+	if request.Timeout != nil {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, request.Timeout.AsDuration())
+		defer cancel()
+	}
+
+	log := requestLog(a.log, ctx)
+	log.Info("the server's draining started")
+
+	if err := a.kern.drain(ctx); err != nil {
+		drainErr := fmt.Errorf("%w: %s", ErrServiceCondition, err)
+		log.Error("the server's draining failed", slog.String("op", op), slog.Any("error", drainErr))
+
+		return nil, status.Error(codes.DeadlineExceeded, drainErr.Error())
+	}
+
+	log.Info("the server was drained")
+
+	return &emptypb.Empty{}, nil
+}
+
+// ReloadConfig defines the logic of the handling the config's reloading requests.
+func (a *adminServer) ReloadConfig(ctx context.Context, _ *emptypb.Empty) (*sprpc.ReloadResponse, error) {
+	const op = "spserv.ReloadConfig"
+
+	log := requestLog(a.log, ctx)
+
+	if a.kern.reload == nil {
+		err := fmt.Errorf("%w: the config's reloading isn't supported", ErrReload)
+		log.Error("the config's reloading failed", slog.String("op", op), slog.Any("error", err))
+
+		return nil, status.Error(codes.Unimplemented, err.Error())
+	}
+
+	report, err := a.kern.reload()
+	if err != nil {
+		reloadErr := fmt.Errorf("%w: %s", ErrReload, err)
+		log.Error("the config's reloading failed", slog.String("op", op), slog.Any("error", reloadErr))
+
+		return nil, status.Error(codes.FailedPrecondition, reloadErr.Error())
+	}
+
+	log.Info("the config's reloading was requested", slog.Any("applied", report.Applied), slog.Any("rejected", report.Rejected))
+
+	return &sprpc.ReloadResponse{Applied: report.Applied, Rejected: report.Rejected}, nil
+}
+
+// subscription returns the registered subscription by its id.
+func (a *adminServer) subscription(op string, id int64) (*remoteSub, error) {
+	sub, ok := a.kern.subs.Get(id)
+	if !ok {
+		err := fmt.Errorf("%w: the subscription %d doesn't exist", ErrSubNotFound, id)
+		a.log.Error("the subscription wasn't found", slog.String("op", op), slog.Int64("subscription_id", id), slog.Any("error", err))
+
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	return sub, nil
+}
@@ -0,0 +1,453 @@
+package spserv
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
+)
+
+// anyIdentity defines the ACL's identity whose grants are applied to every client.
+const anyIdentity = "*"
+
+// authFile defines the structure of the authentication and authorization's config file.
+type authFile struct {
+	// AllowAnonymous defines whether the clients without the credentials are accepted (they get the "*" grants only).
+	AllowAnonymous bool `yaml:"allow_anonymous"`
+
+	Tokens []struct {
+		Identity string `yaml:"identity"`
+		Token    string `yaml:"token"`
+	} `yaml:"tokens"`
+
+	JWT *struct {
+		HMACSecret    string `yaml:"hmac_secret"`
+		PublicKeyFile string `yaml:"public_key_file"`
+		Issuer        string `yaml:"issuer"`
+		Audience      string `yaml:"audience"`
+	} `yaml:"jwt"`
+
+	ACL []struct {
+		Identity  string   `yaml:"identity"`
+		Publish   []string `yaml:"publish"`
+		Subscribe []string `yaml:"subscribe"`
+		Admin     bool     `yaml:"admin"`
+	} `yaml:"acl"`
+
+	Accounts []struct {
+		Name    string   `yaml:"name"`
+		Members []string `yaml:"members"`
+		Exports []string `yaml:"exports"`
+
+		Imports []struct {
+			Account string `yaml:"account"`
+			Subject string `yaml:"subject"`
+			As      string `yaml:"as"`
+		} `yaml:"imports"`
+
+		Limits struct {
+			MaxSubjects      int     `yaml:"max_subjects"`
+			MaxSubscriptions int     `yaml:"max_subscriptions"`
+			MsgRate          float64 `yaml:"msg_rate"`
+			MsgBurst         int     `yaml:"msg_burst"`
+		} `yaml:"limits"`
+	} `yaml:"accounts"`
+}
+
+// grants defines the identity's rights: the subjects' patterns may contain the '*' that matches any sequence of chars.
+type grants struct {
+	publish   []string
+	subscribe []string
+	admin     bool
+}
+
+// right defines the kind of the subject's access.
+type right string
+
+const (
+	rightPublish   right = "publish"
+	rightSubscribe right = "subscribe"
+)
+
+// Auth defines the logic of the clients' authentication through the grpc's metadata
+// and the authorization of their access to the subjects.
+type Auth struct {
+	log  *slog.Logger
+	path string
+
+	// rules defines the current settings of the file, they are replaced entirely by the reloading.
+	rules atomic.Pointer[authRules]
+}
+
+// authRules defines the settings of the authentication and authorization's file.
+type authRules struct {
+	allowAnonymous bool
+
+	// tokens stores the static tokens' identities by the tokens' hashes.
+	tokens map[[sha256.Size]byte]string
+
+	// jwt defines the verifier of the signed tokens (nil means the JWTs aren't accepted).
+	jwt *jwtVerifier
+
+	acl map[string]*grants
+
+	accounts []subpub.Account
+
+	// members stores the accounts' names by their members' identities.
+	members map[string]string
+
+	// digest defines the hash of the file's content and of the JWT's public key.
+	digest [sha256.Size]byte
+}
+
+// NewAuth loads the authentication and authorization's settings from the YAML file.
+func NewAuth(log *slog.Logger, path string) (*Auth, error) {
+	rules, err := loadAuthRules(path)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &Auth{
+		log:  log,
+		path: path,
+	}
+	auth.rules.Store(rules)
+
+	return auth, nil
+}
+
+// Reload re-reads the file replacing the tokens, the ACLs and the accounts' limits at once,
+// the accounts' members, exports and imports can't be changed without the restart.
+func (a *Auth) Reload(accounts *subpub.Accounts) error {
+	step, err := a.Prepare(accounts)
+	if err != nil {
+		return err
+	}
+	step.Apply()
+
+	return nil
+}
+
+// Prepare re-reads and validates the file without applying it: the step's Apply replaces
+// the tokens, the ACLs and the accounts' limits at once.
+func (a *Auth) Prepare(accounts *subpub.Accounts) (ReloadStep, error) {
+	const op = "spserv.Auth.Prepare"
+
+	rules, err := loadAuthRules(a.path)
+	if err != nil {
+		return ReloadStep{}, err
+	}
+
+	current := a.rules.Load()
+	if !rules.sameAccounts(current) {
+		return ReloadStep{}, fmt.Errorf("error of the %s: %w: the accounts' members, exports and imports can't be changed at runtime", op, ErrAuthConfig)
+	}
+
+	if accounts != nil {
+		for _, acc := range rules.accounts {
+			if _, err := accounts.Account(acc.Name); err != nil {
+				return ReloadStep{}, fmt.Errorf("error of the %s: %w: %s", op, ErrAuthConfig, err)
+			}
+		}
+	}
+	changed := rules.digest != current.digest
+
+	return ReloadStep{
+		Changed: changed,
+		Apply: func() {
+			if accounts != nil {
+				// the accounts' existence was checked by the preparing
+				for _, acc := range rules.accounts {
+					accounts.SetLimits(acc.Name, acc.Limits)
+				}
+			}
+			a.rules.Store(rules)
+
+			if changed {
+				a.log.Info("the auth file was reloaded", slog.String("path", a.path))
+			}
+		},
+	}, nil
+}
+
+// loadAuthRules reads the settings of the file.
+func loadAuthRules(path string) (*authRules, error) {
+	const op = "spserv.loadAuthRules"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error of the %s: %w: %s", op, ErrAuthConfig, err)
+	}
+
+	var file authFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("error of the %s: %w: %s", op, ErrAuthConfig, err)
+	}
+
+	rules := &authRules{
+		allowAnonymous: file.AllowAnonymous,
+		tokens:         make(map[[sha256.Size]byte]string, len(file.Tokens)),
+		acl:            make(map[string]*grants, len(file.ACL)),
+		accounts:       make([]subpub.Account, 0, len(file.Accounts)),
+		members:        make(map[string]string),
+	}
+
+	hash := sha256.New()
+	hash.Write(data)
+
+	for _, token := range file.Tokens {
+		if token.Token == "" || token.Identity == "" {
+			return nil, fmt.Errorf("error of the %s: %w: the token and its identity must be set", op, ErrAuthConfig)
+		}
+		rules.tokens[sha256.Sum256([]byte(token.Token))] = token.Identity
+	}
+
+	if file.JWT != nil {
+		rules.jwt, err = newJWTVerifier(file.JWT.HMACSecret, file.JWT.PublicKeyFile, file.JWT.Issuer, file.JWT.Audience)
+		if err != nil {
+			return nil, fmt.Errorf("error of the %s: %w: %s", op, ErrAuthConfig, err)
+		}
+
+		if file.JWT.PublicKeyFile != "" {
+			key, err := os.ReadFile(file.JWT.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("error of the %s: %w: %s", op, ErrAuthConfig, err)
+			}
+			hash.Write(key)
+		}
+	}
+	hash.Sum(rules.digest[:0])
+
+	for _, entry := range file.ACL {
+		if entry.Identity == "" {
+			return nil, fmt.Errorf("error of the %s: %w: the ACL's identity must be set", op, ErrAuthConfig)
+		}
+
+		g, ok := rules.acl[entry.Identity]
+		if !ok {
+			g = &grants{}
+			rules.acl[entry.Identity] = g
+		}
+		g.publish = append(g.publish, entry.Publish...)
+		g.subscribe = append(g.subscribe, entry.Subscribe...)
+		g.admin = g.admin || entry.Admin
+	}
+
+	for _, entry := range file.Accounts {
+		acc := subpub.Account{
+			Name:    entry.Name,
+			Exports: entry.Exports,
+			Limits: subpub.AccountLimits{
+				MaxSubjects:      entry.Limits.MaxSubjects,
+				MaxSubscriptions: entry.Limits.MaxSubscriptions,
+				MsgRate:          entry.Limits.MsgRate,
+				MsgBurst:         entry.Limits.MsgBurst,
+			},
+		}
+		for _, imp := range entry.Imports {
+			acc.Imports = append(acc.Imports, subpub.Import{Account: imp.Account, Subject: imp.Subject, As: imp.As})
+		}
+
+		for _, member := range entry.Members {
+			if other, ok := rules.members[member]; ok {
+				return nil, fmt.Errorf("error of the %s: %w: the identity '%s' belongs to the accounts '%s' and '%s'",
+					op, ErrAuthConfig, member, other, entry.Name)
+			}
+			rules.members[member] = entry.Name
+		}
+		rules.accounts = append(rules.accounts, acc)
+	}
+
+	return rules, nil
+}
+
+// sameAccounts checks whether the accounts of the rules differ from the other ones only by their limits.
+func (r *authRules) sameAccounts(other *authRules) bool {
+	withoutLimits := func(accounts []subpub.Account) []subpub.Account {
+		res := make([]subpub.Account, 0, len(accounts))
+		for _, acc := range accounts {
+			acc.Limits = subpub.AccountLimits{}
+			res = append(res, acc)
+		}
+		return res
+	}
+
+	return reflect.DeepEqual(withoutLimits(r.accounts), withoutLimits(other.accounts)) && reflect.DeepEqual(r.members, other.members)
+}
+
+// Accounts returns the accounts configured by the file.
+func (a *Auth) Accounts() []subpub.Account {
+	return a.rules.Load().accounts
+}
+
+// withAccount returns the ctx with the identity that belongs to its account.
+func (a *Auth) withAccount(ctx context.Context, id Identity) context.Context {
+	id.Account = a.rules.Load().members[id.Name]
+	return withIdentity(ctx, id)
+}
+
+// authenticate returns the request's ctx with the client's identity: the metadata's bearer token
+// (the static or the signed one) takes precedence over the TLS certificate.
+func (a *Auth) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if vals := md.Get("authorization"); len(vals) != 0 {
+		token, ok := strings.CutPrefix(vals[0], "Bearer ")
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, fmt.Sprintf("%s: the bearer token is expected", ErrAuthentication))
+		}
+
+		rules := a.rules.Load()
+		if name, ok := rules.tokens[sha256.Sum256([]byte(token))]; ok {
+			return a.withAccount(ctx, Identity{Name: name}), nil
+		}
+
+		if rules.jwt == nil {
+			return nil, status.Error(codes.Unauthenticated, fmt.Sprintf("%s: the token is unknown", ErrAuthentication))
+		}
+
+		id, err := rules.jwt.verify(token)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, fmt.Sprintf("%s: %s", ErrAuthentication, err))
+		}
+		return a.withAccount(ctx, id), nil
+	}
+
+	if id, ok := tlsIdentity(ctx); ok {
+		return a.withAccount(ctx, id), nil
+	}
+
+	if !a.rules.Load().allowAnonymous {
+		return nil, status.Error(codes.Unauthenticated, fmt.Sprintf("%s: the credentials are required", ErrAuthentication))
+	}
+	return ctx, nil
+}
+
+// authorize checks the client's right on the subject. The nil Auth allows everything.
+func (a *Auth) authorize(ctx context.Context, r right, subject string) error {
+	if a.permits(ctx, r, subject) {
+		return nil
+	}
+	requestLog(a.log, ctx).Warn("the request was denied", slog.String("right", string(r)), slog.String("subject", subject))
+	return status.Error(codes.PermissionDenied, fmt.Sprintf("%s: the %s into the '%s' isn't allowed", ErrPermission, r, subject))
+}
+
+// permits checks the client's right on the subject without the denial's logging.
+func (a *Auth) permits(ctx context.Context, r right, subject string) bool {
+	if a == nil {
+		return true
+	}
+	id, _ := IdentityFromContext(ctx)
+
+	return a.allowed(id, func(g *grants) bool {
+		patterns := g.publish
+		if r == rightSubscribe {
+			patterns = g.subscribe
+		}
+
+		for _, pattern := range patterns {
+			if subpub.MatchSubject(pattern, subject) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// authorizeOwner checks whether the client owns the subscription or is the administrator.
+// The anonymous clients own no subscriptions: they are indistinguishable from each other.
+func (a *Auth) authorizeOwner(ctx context.Context, sub *remoteSub) error {
+	if a == nil {
+		return nil
+	}
+	id, _ := IdentityFromContext(ctx)
+
+	if (id.Name != "" && sub.identity == id.Name) || a.allowed(id, func(g *grants) bool { return g.admin }) {
+		return nil
+	}
+
+	requestLog(a.log, ctx).Warn("the unsubscribing was denied", slog.Int64("subscription_id", sub.id))
+	return status.Error(codes.PermissionDenied, fmt.Sprintf("%s: the subscription %d belongs to the other client", ErrPermission, sub.id))
+}
+
+// authorizeAdmin checks the client's right on the administrative requests. The nil Auth allows everything.
+func (a *Auth) authorizeAdmin(ctx context.Context, method string) error {
+	if a == nil {
+		return nil
+	}
+	id, _ := IdentityFromContext(ctx)
+
+	if !a.allowed(id, func(g *grants) bool { return g.admin }) {
+		requestLog(a.log, ctx).Warn("the administrative request was denied", slog.String("method", method))
+		return status.Error(codes.PermissionDenied, fmt.Sprintf("%s: the administrative requests aren't allowed", ErrPermission))
+	}
+	return nil
+}
+
+// allowed checks the grants of the identity and the common ones.
+func (a *Auth) allowed(id Identity, check func(g *grants) bool) bool {
+	acl := a.rules.Load().acl
+
+	if g, ok := acl[id.Name]; ok && id.Name != "" && check(g) {
+		return true
+	}
+
+	g, ok := acl[anyIdentity]
+	return ok && check(g)
+}
+
+// exempt checks whether the method is available without the credentials.
+func exempt(method string) bool {
+	return strings.HasPrefix(method, "/grpc.health.v1.") || strings.HasPrefix(method, "/grpc.reflection.")
+}
+
+// interceptIdentity returns the ctx with the client's identity, the admin's methods are authorized here as well.
+// The nil Auth only takes the identity of the TLS certificate.
+func (a *Auth) interceptIdentity(ctx context.Context, method string) (context.Context, error) {
+	if a == nil || exempt(method) {
+		return identify(ctx), nil
+	}
+
+	authCtx, err := a.authenticate(ctx)
+	if err != nil {
+		requestLog(a.log, identify(ctx)).Warn("the request was unauthenticated", slog.String("method", method), slog.Any("error", err))
+		return nil, err
+	}
+
+	if strings.HasPrefix(method, "/sprpc.Admin/") {
+		if err := a.authorizeAdmin(authCtx, method); err != nil {
+			return nil, err
+		}
+	}
+
+	return authCtx, nil
+}
+
+// unaryInterceptor defines the interceptor that authenticates the unary requests.
+func (a *Auth) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.interceptIdentity(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamInterceptor defines the interceptor that authenticates the streams.
+func (a *Auth) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.interceptIdentity(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &identityStream{ServerStream: stream, ctx: ctx})
+}
@@ -0,0 +1,433 @@
+package spserv
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+const testAuthFile = `
+allow_anonymous: true
+tokens:
+  - identity: publisher
+    token: publisher-token
+  - identity: admin
+    token: admin-token
+jwt:
+  hmac_secret: test-secret
+  issuer: test-issuer
+acl:
+  - identity: publisher
+    publish: ["orders.*"]
+    subscribe: ["orders.created"]
+  - identity: jwt-client
+    subscribe: ["events.*"]
+  - identity: admin
+    admin: true
+  - identity: "*"
+    publish: ["public"]
+    subscribe: ["public"]
+`
+
+const testAccountsFile = `
+tokens:
+  - identity: client-a
+    token: token-a
+  - identity: client-b
+    token: token-b
+  - identity: admin
+    token: admin-token
+acl:
+  - identity: "*"
+    publish: ["*"]
+    subscribe: ["*"]
+  - identity: admin
+    admin: true
+accounts:
+  - name: team-a
+    members: [client-a]
+    exports: ["orders"]
+    limits:
+      max_subscriptions: 2
+  - name: team-b
+    members: [client-b]
+    imports:
+      - account: team-a
+        subject: orders
+        as: a-orders
+`
+
+// startAuthService starts the service with the authentication on the local socket and returns the client's connection.
+func startAuthService(t *testing.T, authFile string) *grpc.ClientConn {
+	path := filepath.Join(t.TempDir(), "auth.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(authFile), 0600), "expected no error after the file's writing")
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	auth, err := NewAuth(log, path)
+	require.NoError(t, err, "expected no error after the auth's loading")
+
+	sp := subpub.NewSubPub()
+	accounts, err := subpub.NewAccounts(sp, nil, auth.Accounts()...)
+	require.NoError(t, err, "expected no error after the accounts' creating")
+
+	server := NewSubPubServer(log, sp, WithAuthorization(auth), WithAccounts(accounts))
+	service, err := NewSubPubService(log, "127.0.0.1:0", server, WithAuthentication(auth))
+	require.NoError(t, err, "expected no error after the service's creating")
+
+	go service.Run()
+	t.Cleanup(service.Close)
+
+	conn, err := grpc.NewClient(service.conn.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err, "expected no error after the client's creating")
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// withToken returns the ctx that carries the bearer token.
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// signHS256 returns the JWT of the claims signed with the secret.
+func signHS256(t *testing.T, secret string, claims map[string]any) string {
+	signed := jwtSegment(t, map[string]any{"alg": "HS256", "typ": "JWT"}) + "." + jwtSegment(t, claims)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(signed))
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// jwtSegment returns the base64url JSON segment of the token.
+func jwtSegment(t *testing.T, val any) string {
+	data, err := json.Marshal(val)
+	require.NoError(t, err, "expected no error after the segment's marshaling")
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// subscribeFirst subscribes on the key and returns the error of the first event's receiving.
+func subscribeFirst(ctx context.Context, client sprpc.PubSubClient, key string) (*sprpc.Event, error) {
+	stream, err := client.Subscribe(ctx, &sprpc.SubscribeRequest{Key: key})
+	if err != nil {
+		return nil, err
+	}
+	return stream.Recv()
+}
+
+func TestAuthPositiveCases(t *testing.T) {
+	conn := startAuthService(t, testAuthFile)
+	client := sprpc.NewPubSubClient(conn)
+
+	t.Run("TestAuthPositiveCases_StaticToken", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(withToken("publisher-token"))
+		defer cancel()
+
+		_, err := subscribeFirst(ctx, client, "orders.created")
+		require.NoError(t, err, "expected the subscribing to be allowed")
+
+		_, err = client.Publish(ctx, &sprpc.PublishRequest{Key: "orders.created", Data: "test-message"})
+		assert.NoError(t, err, "expected the publishing to be allowed")
+	})
+
+	t.Run("TestAuthPositiveCases_SignedToken", func(t *testing.T) {
+		token := signHS256(t, "test-secret", map[string]any{
+			"sub": "jwt-client",
+			"iss": "test-issuer",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+
+		ctx, cancel := context.WithCancel(withToken(token))
+		defer cancel()
+
+		_, err := subscribeFirst(ctx, client, "events.test")
+		assert.NoError(t, err, "expected the subscribing to be allowed")
+	})
+
+	t.Run("TestAuthPositiveCases_Anonymous", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		_, err := subscribeFirst(ctx, client, "public")
+		require.NoError(t, err, "expected the common grants to be applied to the anonymous client")
+
+		_, err = client.Publish(ctx, &sprpc.PublishRequest{Key: "public", Data: "test-message"})
+		assert.NoError(t, err, "expected the common grants to be applied to the anonymous client")
+	})
+
+	t.Run("TestAuthPositiveCases_Admin", func(t *testing.T) {
+		_, err := sprpc.NewAdminClient(conn).ListSubjects(withToken("admin-token"), &emptypb.Empty{})
+		assert.NoError(t, err, "expected the administrative request to be allowed")
+	})
+}
+
+func TestAuthNegativeCases(t *testing.T) {
+	conn := startAuthService(t, testAuthFile)
+	client := sprpc.NewPubSubClient(conn)
+
+	t.Run("TestAuthNegativeCases_UnknownToken", func(t *testing.T) {
+		_, err := client.Publish(withToken("unknown-token"), &sprpc.PublishRequest{Key: "public", Data: "test-message"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err), "expected the unknown token to be rejected")
+	})
+
+	t.Run("TestAuthNegativeCases_PublishDenied", func(t *testing.T) {
+		_, err := client.Publish(withToken("publisher-token"), &sprpc.PublishRequest{Key: "payments.created", Data: "test-message"})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "expected the publishing out of the grants to be denied")
+
+		_, err = client.PublishTx(withToken("publisher-token"), &sprpc.PublishTxRequest{Messages: []*sprpc.PublishRequest{
+			{Key: "orders.created", Data: "test-message"},
+			{Key: "payments.created", Data: "test-message"},
+		}})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "expected the transaction with the denied subject to be denied")
+	})
+
+	t.Run("TestAuthNegativeCases_SubscribeDenied", func(t *testing.T) {
+		_, err := subscribeFirst(withToken("publisher-token"), client, "orders.deleted")
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "expected the subscribing out of the grants to be denied")
+	})
+
+	t.Run("TestAuthNegativeCases_SignedToken", func(t *testing.T) {
+		expired := signHS256(t, "test-secret", map[string]any{
+			"sub": "jwt-client",
+			"iss": "test-issuer",
+			"exp": time.Now().Add(-time.Minute).Unix(),
+		})
+		foreign := signHS256(t, "foreign-secret", map[string]any{"sub": "jwt-client", "iss": "test-issuer"})
+		wrongIssuer := signHS256(t, "test-secret", map[string]any{"sub": "jwt-client", "iss": "foreign-issuer"})
+		unsigned := jwtSegment(t, map[string]any{"alg": "none"}) + "." + jwtSegment(t, map[string]any{"sub": "jwt-client"}) + "."
+
+		for _, token := range []string{expired, foreign, wrongIssuer, unsigned} {
+			_, err := subscribeFirst(withToken(token), client, "events.test")
+			assert.Equal(t, codes.Unauthenticated, status.Code(err), "expected the invalid token to be rejected")
+		}
+	})
+
+	t.Run("TestAuthNegativeCases_AdminDenied", func(t *testing.T) {
+		_, err := sprpc.NewAdminClient(conn).ListSubjects(withToken("publisher-token"), &emptypb.Empty{})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "expected the administrative request to be denied")
+	})
+
+	t.Run("TestAuthNegativeCases_ForeignUnsubscribe", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(withToken("publisher-token"))
+		defer cancel()
+
+		event, err := subscribeFirst(ctx, client, "orders.created")
+		require.NoError(t, err, "expected the subscribing to be allowed")
+
+		_, err = client.Unsubscribe(context.Background(), &sprpc.SubscriptionRequest{Id: event.SubscriptionId})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "expected the foreign subscription's unsubscribing to be denied")
+	})
+
+	t.Run("TestAuthNegativeCases_AnonymousUnsubscribe", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		event, err := subscribeFirst(ctx, client, "public")
+		require.NoError(t, err, "expected the anonymous subscribing to be allowed")
+
+		_, err = client.Unsubscribe(context.Background(), &sprpc.SubscriptionRequest{Id: event.SubscriptionId})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err), "expected the anonymous client not to own the subscription")
+
+		_, err = client.Unsubscribe(withToken("admin-token"), &sprpc.SubscriptionRequest{Id: event.SubscriptionId})
+		assert.NoError(t, err, "expected the administrator to unsubscribe the anonymous subscription")
+	})
+}
+
+func TestAuthNegativeCases_NoCredentials(t *testing.T) {
+	conn := startAuthService(t, "acl:\n  - identity: \"*\"\n    publish: [\"public\"]\n")
+
+	_, err := sprpc.NewPubSubClient(conn).Publish(context.Background(), &sprpc.PublishRequest{Key: "public", Data: "test-message"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err), "expected the anonymous client to be rejected")
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err, "expected the health checking to be available without the credentials")
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status, "expected the server to be serving")
+}
+
+func TestAccountsIsolation(t *testing.T) {
+	conn := startAuthService(t, testAccountsFile)
+	client := sprpc.NewPubSubClient(conn)
+
+	ctxA, cancelA := context.WithCancel(withToken("token-a"))
+	defer cancelA()
+	ctxB, cancelB := context.WithCancel(withToken("token-b"))
+	defer cancelB()
+
+	_, err := subscribeFirst(ctxA, client, "orders")
+	require.NoError(t, err, "expected no error after the subscribing")
+
+	_, err = client.Publish(ctxB, &sprpc.PublishRequest{Key: "orders", Data: "test-message"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "expected the other account's subject to be invisible")
+
+	stream, err := client.Subscribe(ctxB, &sprpc.SubscribeRequest{Key: "a-orders"})
+	require.NoError(t, err, "expected no error after the import's subscribing")
+	_, err = stream.Recv()
+	require.NoError(t, err, "expected the subscription's id to be received")
+
+	_, err = client.Publish(ctxA, &sprpc.PublishRequest{Key: "orders", Data: "test-message"})
+	require.NoError(t, err, "expected no error after the publishing")
+
+	event, err := stream.Recv()
+	require.NoError(t, err, "expected the exported message to be received")
+	assert.Equal(t, "test-message", event.Data, "expected the exported message's data")
+
+	_, err = subscribeFirst(ctxA, client, "billing")
+	require.NoError(t, err, "expected no error after the subscribing")
+
+	_, err = subscribeFirst(ctxA, client, "payments")
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "expected the account's subscriptions' limit to be enforced")
+
+	list, err := sprpc.NewAdminClient(conn).ListSubscriptions(withToken("admin-token"), &emptypb.Empty{})
+	require.NoError(t, err, "expected no error after the subscriptions' listing")
+
+	accounts := make([]string, 0, len(list.Subscriptions))
+	for _, sub := range list.Subscriptions {
+		accounts = append(accounts, sub.Account)
+	}
+	assert.ElementsMatch(t, []string{"team-a", "team-a", "team-b"}, accounts, "expected the subscriptions' accounts")
+}
+
+func TestAccountsSubjectStats(t *testing.T) {
+	conn := startAuthService(t, testAccountsFile)
+	client := sprpc.NewPubSubClient(conn)
+	admin := sprpc.NewAdminClient(conn)
+
+	ctxA, cancelA := context.WithCancel(withToken("token-a"))
+	defer cancelA()
+	ctxB, cancelB := context.WithCancel(withToken("token-b"))
+	defer cancelB()
+
+	_, err := subscribeFirst(ctxA, client, "orders")
+	require.NoError(t, err, "expected no error after the subscribing")
+
+	_, err = subscribeFirst(ctxB, client, "a-orders")
+	require.NoError(t, err, "expected no error after the import's subscribing")
+
+	_, err = client.Publish(ctxA, &sprpc.PublishRequest{Key: "orders", Data: "test-message"})
+	require.NoError(t, err, "expected no error after the publishing")
+
+	list, err := admin.ListSubjects(withToken("admin-token"), &emptypb.Empty{})
+	require.NoError(t, err, "expected no error after the subjects' listing")
+	assert.Equal(t, []string{"team-a:orders"}, list.Keys, "expected the shared name of the account's subject")
+
+	stats, err := admin.GetSubjectStats(withToken("admin-token"), &sprpc.SubjectRequest{Key: "team-a:orders"})
+	require.NoError(t, err, "expected no error after the stats' getting")
+	assert.Equal(t, uint64(1), stats.Published, "expected the published message to be counted")
+	assert.Equal(t, uint32(2), stats.Subscriptions, "expected the subscriptions of the account and of its importer")
+
+	stats, err = admin.GetSubjectStats(withToken("admin-token"), &sprpc.SubjectRequest{Key: "orders"})
+	require.NoError(t, err, "expected no error after the stats' getting")
+	assert.Equal(t, uint32(0), stats.Subscriptions, "expected the local name not to match the account's subscriptions")
+}
+
+func TestAuthReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testAccountsFile), 0600), "expected no error after the file's writing")
+
+	auth, err := NewAuth(slog.New(slog.NewTextHandler(io.Discard, nil)), path)
+	require.NoError(t, err, "expected no error after the auth's loading")
+
+	sp := subpub.NewSubPub()
+	defer sp.Close(context.Background())
+
+	accounts, err := subpub.NewAccounts(sp, nil, auth.Accounts()...)
+	require.NoError(t, err, "expected no error after the accounts' creating")
+
+	clientA := withIdentity(context.Background(), Identity{Name: "client-a", Account: "team-a"})
+	teamA, _ := accounts.Account("team-a")
+
+	for _, subject := range []string{"orders", "billing"} {
+		_, err := teamA.Subscribe(subject, func(interface{}) {})
+		require.NoError(t, err, "expected no error inside the account's limit")
+	}
+
+	t.Run("TestAuthReloadPositiveCases_RulesAndLimits", func(t *testing.T) {
+		changed := strings.Replace(testAccountsFile, `publish: ["*"]`, `publish: ["orders"]`, 1)
+		changed = strings.Replace(changed, "max_subscriptions: 2", "max_subscriptions: 3", 1)
+		require.NoError(t, os.WriteFile(path, []byte(changed), 0600), "expected no error after the file's writing")
+
+		require.NoError(t, auth.Reload(accounts), "expected no error after the reloading")
+
+		assert.NoError(t, auth.authorize(clientA, rightPublish, "orders"), "expected the reloaded grant")
+		assert.Error(t, auth.authorize(clientA, rightPublish, "billing"), "expected the grant to be narrowed by the reloading")
+
+		_, err := teamA.Subscribe("events", func(interface{}) {})
+		assert.NoError(t, err, "expected the subscription inside the reloaded limit")
+	})
+
+	t.Run("TestAuthReloadNegativeCases_Accounts", func(t *testing.T) {
+		changed := strings.Replace(testAccountsFile, "members: [client-b]", "members: [client-b, client-c]", 1)
+		require.NoError(t, os.WriteFile(path, []byte(changed), 0600), "expected no error after the file's writing")
+
+		assert.ErrorIs(t, auth.Reload(accounts), ErrAuthConfig, "expected the error of the accounts' changing")
+		assert.Error(t, auth.authorize(clientA, rightPublish, "billing"), "expected the previous rules to be kept")
+
+		require.NoError(t, os.WriteFile(path, []byte("acl: ["), 0600), "expected no error after the file's writing")
+		assert.ErrorIs(t, auth.Reload(accounts), ErrAuthConfig, "expected the error of the broken file")
+	})
+}
+
+func TestJWTVerifierES256(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "expected no error after the key's generating")
+
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err, "expected no error after the key's marshaling")
+
+	path := filepath.Join(t.TempDir(), "jwt.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	verifier, err := newJWTVerifier("", path, "", "test-audience")
+	require.NoError(t, err, "expected no error after the verifier's creating")
+
+	sign := func(header, claims map[string]any) string {
+		signed := jwtSegment(t, header) + "." + jwtSegment(t, claims)
+		digest := sha256.Sum256([]byte(signed))
+
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		require.NoError(t, err, "expected no error after the signing")
+
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+
+		return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+	}
+
+	id, err := verifier.verify(sign(map[string]any{"alg": "ES256"}, map[string]any{"sub": "test-client", "aud": []string{"test-audience"}}))
+	require.NoError(t, err, "expected the valid token to be verified")
+	assert.Equal(t, "test-client", id.Name, "expected the token's subject as the identity")
+
+	_, err = verifier.verify(sign(map[string]any{"alg": "ES256"}, map[string]any{"sub": "test-client", "aud": "foreign-audience"}))
+	assert.Error(t, err, "expected the token of the foreign audience to be rejected")
+
+	_, err = verifier.verify(signHS256(t, "test-secret", map[string]any{"sub": "test-client", "aud": "test-audience"}))
+	assert.Error(t, err, "expected the algorithm that doesn't match the key to be rejected")
+}
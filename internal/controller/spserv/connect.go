@@ -0,0 +1,363 @@
+package spserv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// connWindow defines the default max count of the unacknowledged events of the single connection.
+const connWindow = 64
+
+// flowControl defines the logic of the connection's credits: the event is sent only
+// when the count of the unacknowledged events is less than the window.
+type flowControl struct {
+	mut  sync.Mutex
+	cond *sync.Cond
+
+	window int
+
+	// unacked defines the ids of the sent but not acknowledged events.
+	unacked map[uint64]struct{}
+
+	// lastID defines the last assigned delivery's id.
+	lastID uint64
+
+	closed bool
+}
+
+func newFlowControl(window int) *flowControl {
+	f := &flowControl{
+		window:  window,
+		unacked: make(map[uint64]struct{}),
+	}
+	f.cond = sync.NewCond(&f.mut)
+
+	return f
+}
+
+// acquire waits for the free credit and returns the id of the new delivery.
+// The false is returned if the flow control was closed or the done was closed while waiting.
+func (f *flowControl) acquire(done <-chan struct{}) (uint64, bool) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if !f.closed && len(f.unacked) >= f.window {
+		waiting := make(chan struct{})
+		defer close(waiting)
+
+		go func() {
+			select {
+			case <-done:
+				f.mut.Lock()
+				f.cond.Broadcast()
+				f.mut.Unlock()
+			case <-waiting:
+			}
+		}()
+	}
+
+	for !f.closed && !isDone(done) && len(f.unacked) >= f.window {
+		f.cond.Wait()
+	}
+	if f.closed || isDone(done) {
+		return 0, false
+	}
+
+	f.lastID++
+	f.unacked[f.lastID] = struct{}{}
+
+	return f.lastID, true
+}
+
+// ack releases the credit of the delivery and returns false if the delivery is unknown.
+func (f *flowControl) ack(id uint64) bool {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	if _, ok := f.unacked[id]; !ok {
+		return false
+	}
+	delete(f.unacked, id)
+	f.cond.Signal()
+
+	return true
+}
+
+// setWindow changes the max count of the unacknowledged events.
+func (f *flowControl) setWindow(window int) {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	f.window = window
+	f.cond.Broadcast()
+}
+
+// close wakes up all the waiters making them refuse the sending.
+func (f *flowControl) close() {
+	f.mut.Lock()
+	defer f.mut.Unlock()
+
+	f.closed = true
+	f.cond.Broadcast()
+}
+
+// isDone checks whether the done is closed without the blocking.
+func isDone(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// connection defines the state of the single Connect stream.
+type connection struct {
+	serv   *SubPubServer
+	stream grpc.BidiStreamingServer[sprpc.ClientFrame, sprpc.ServerFrame]
+	flow   *flowControl
+
+	// sendMut serializes the stream's sending between the handlers and the operations' results.
+	sendMut sync.Mutex
+
+	// closed defines whether the stream mustn't be used for the sending anymore.
+	closed atomic.Bool
+
+	// done is closed when the connection is closed by the server.
+	done     chan struct{}
+	doneOnce sync.Once
+
+	// subs defines the connection's subscriptions by their ids.
+	subs syncMap[int64, *remoteSub]
+}
+
+// Connect defines the logic of the handling the multiplexed bidirectional streams.
+func (s *SubPubServer) Connect(stream grpc.BidiStreamingServer[sprpc.ClientFrame, sprpc.ServerFrame]) error {
+	const op = "spserv.Connect"
+
+	conn := &connection{
+		serv:   s,
+		stream: stream,
+		flow:   newFlowControl(connWindow),
+		subs:   newSyncMap[int64, *remoteSub](),
+		done:   make(chan struct{}),
+	}
+
+	connID := s.lastConnID.Add(1)
+	s.conns.Add(connID, conn)
+	defer s.conns.Delete(connID)
+	defer conn.close()
+
+	log := requestLog(s.log, stream.Context()).With(slog.Int64("connection_id", connID))
+	log.Info("the connection was opened")
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- conn.serve()
+	}()
+
+	select {
+	case <-conn.done:
+		return status.Error(codes.Aborted, ErrServiceCondition.Error())
+
+	case err := <-errCh:
+		if err != nil {
+			connErr := fmt.Errorf("%w: %s", ErrSendingMsg, err)
+			log.Error("the connection failed", slog.String("op", op), slog.Any("error", connErr))
+
+			return status.Error(codes.Aborted, connErr.Error())
+		}
+
+		log.Info("the connection was closed")
+		return nil
+	}
+}
+
+// serve handles the client's frames untill the stream's end.
+func (c *connection) serve() error {
+	for {
+		frame, err := c.stream.Recv()
+		if err != nil {
+			if status.Code(err) == codes.Canceled || errors.Is(err, context.Canceled) || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		if err := c.handle(frame); err != nil {
+			return err
+		}
+	}
+}
+
+// handle executes the client's operation and sends its result.
+func (c *connection) handle(frame *sprpc.ClientFrame) error {
+	result := &sprpc.OpResult{CorrelationId: frame.CorrelationId}
+
+	switch op := frame.Op.(type) {
+	case *sprpc.ClientFrame_Subscribe:
+		id, ready, err := c.subscribe(op.Subscribe.Key)
+		result.SubscriptionId = id
+		setResultErr(result, err)
+
+		if ready != nil {
+			// the subscription's deliveries wait for its id to be sent to the client
+			defer close(ready)
+		}
+
+	case *sprpc.ClientFrame_Unsubscribe:
+		sub, ok := c.subs.Get(op.Unsubscribe.SubscriptionId)
+		if !ok {
+			setResultErr(result, status.Error(codes.NotFound,
+				fmt.Errorf("%w: the subscription %d doesn't exist", ErrSubNotFound, op.Unsubscribe.SubscriptionId).Error()))
+			break
+		}
+		c.subs.Delete(sub.id)
+		c.serv.subs.Delete(sub.id)
+		sub.stop()
+
+	case *sprpc.ClientFrame_Publish:
+		resp, err := c.serv.Publish(c.stream.Context(), op.Publish)
+		result.Publish = resp
+		setResultErr(result, err)
+
+	case *sprpc.ClientFrame_Ack:
+		if !c.flow.ack(op.Ack.DeliveryId) {
+			setResultErr(result, status.Error(codes.NotFound,
+				fmt.Sprintf("%s: the delivery %d isn't waiting for the ack", ErrDataRequest, op.Ack.DeliveryId)))
+		} else if frame.CorrelationId == "" {
+			return nil
+		}
+
+	case *sprpc.ClientFrame_Flow:
+		if op.Flow.Window == 0 {
+			setResultErr(result, status.Error(codes.InvalidArgument,
+				fmt.Sprintf("%s: the window must be positive", ErrDataRequest)))
+			break
+		}
+		c.flow.setWindow(int(op.Flow.Window))
+
+	default:
+		setResultErr(result, status.Error(codes.InvalidArgument,
+			fmt.Sprintf("%s: the operation is empty or unknown", ErrDataRequest)))
+	}
+
+	return c.send(&sprpc.ServerFrame{Kind: &sprpc.ServerFrame_Result{Result: result}})
+}
+
+// subscribe creates the connection's subscription on the key and returns its id.
+// The subscription's deliveries aren't sent untill the returned ready chan is closed.
+func (c *connection) subscribe(key string) (int64, chan struct{}, error) {
+	if err := c.serv.auth.authorize(c.stream.Context(), rightSubscribe, key); err != nil {
+		return 0, nil, err
+	}
+
+	sp, err := c.serv.subPub(c.stream.Context())
+	if err != nil {
+		return 0, nil, err
+	}
+
+	remote := newRemoteSub(c.stream.Context(), c.serv.lastSubID.Add(1), key, c.serv.sharedSubject(c.stream.Context(), key))
+	ready := make(chan struct{})
+
+	sub, err := sp.Subscribe(key, func(msg interface{}) {
+		data, ok := msg.(string)
+		if !ok {
+			c.serv.log.Warn("the message that isn't the string was skipped", slog.String("subject", key),
+				slog.Int64("subscription_id", remote.id), slog.String("type", fmt.Sprintf("%T", msg)))
+			return
+		}
+
+		select {
+		case <-ready:
+		case <-remote.done:
+			return
+		}
+
+		deliveryID, ok := c.flow.acquire(remote.done)
+		if !ok {
+			return
+		} else if isDone(remote.done) {
+			c.flow.ack(deliveryID)
+			return
+		}
+
+		err := c.send(&sprpc.ServerFrame{Kind: &sprpc.ServerFrame_Event{Event: &sprpc.Delivery{
+			DeliveryId:     deliveryID,
+			SubscriptionId: remote.id,
+			Key:            key,
+			Data:           data,
+		}}})
+		if err == nil {
+			remote.delivered.Add(1)
+		}
+	}, subpub.WithCapacity(subCapacity, subpub.DropOldest))
+
+	if err != nil {
+		return 0, nil, statusOf(err)
+	}
+	remote.sub = sub
+
+	c.subs.Add(remote.id, remote)
+	c.serv.subs.Add(remote.id, remote)
+
+	return remote.id, ready, nil
+}
+
+// send sends the frame into the stream.
+func (c *connection) send(frame *sprpc.ServerFrame) error {
+	if c.closed.Load() {
+		return ErrServiceCondition
+	}
+
+	c.sendMut.Lock()
+	defer c.sendMut.Unlock()
+
+	if c.closed.Load() {
+		return ErrServiceCondition
+	}
+	return c.stream.Send(frame)
+}
+
+// close unsubscribes all the connection's subscriptions and releases their blocked handlers.
+// It doesn't wait for the sending that is in progress: the stalled client's sending is released
+// by the stream's ending after the Connect's return, so the closing never blocks the server's shutdown.
+func (c *connection) close() {
+	c.closed.Store(true)
+	c.doneOnce.Do(func() {
+		close(c.done)
+	})
+	c.flow.close()
+
+	c.subs.Range(func(sub *remoteSub) {
+		c.serv.subs.Delete(sub.id)
+		sub.stop()
+	})
+}
+
+// setResultErr puts the err's code and description into the result.
+func setResultErr(result *sprpc.OpResult, err error) {
+	if err == nil {
+		return
+	}
+	st := status.Convert(err)
+
+	result.Code = int32(st.Code())
+	result.Error = st.Message()
+
+	if delay, ok := retryDelay(err); ok {
+		result.RetryAfter = durationpb.New(delay)
+	}
+}
@@ -0,0 +1,125 @@
+package spserv
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectSubscribeResultFirst(t *testing.T) {
+	sp := subpub.NewSubPub()
+	_, client := startTestServer(t, sp)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Connect(ctx)
+	require.NoError(t, err, "expected no error after the connecting")
+
+	go func() {
+		for ctx.Err() == nil {
+			sp.Publish("orders", "order")
+		}
+	}()
+
+	require.NoError(t, stream.Send(&sprpc.ClientFrame{CorrelationId: "subscribe", Op: &sprpc.ClientFrame_Subscribe{
+		Subscribe: &sprpc.SubscribeRequest{Key: "orders"},
+	}}), "expected no error after the frame's sending")
+
+	frame, err := stream.Recv()
+	require.NoError(t, err, "expected no error after the frame's receiving")
+	require.NotNil(t, frame.GetResult(), "expected the subscription's result before its deliveries")
+
+	frame, err = stream.Recv()
+	require.NoError(t, err, "expected no error after the frame's receiving")
+	assert.Equal(t, "order", frame.GetEvent().GetData(), "expected the delivery after the subscription's result")
+}
+
+func TestConnectCloseStalledClient(t *testing.T) {
+	sp := subpub.NewSubPub()
+	server, client := startTestServer(t, sp)
+
+	stream, err := client.Connect(context.Background())
+	require.NoError(t, err, "expected no error after the connecting")
+
+	require.NoError(t, stream.Send(&sprpc.ClientFrame{Op: &sprpc.ClientFrame_Subscribe{
+		Subscribe: &sprpc.SubscribeRequest{Key: "orders"},
+	}}), "expected no error after the frame's sending")
+
+	_, err = stream.Recv()
+	require.NoError(t, err, "expected the subscription's result to be received")
+
+	// the client doesn't read the deliveries, so the server's sending is blocked by the transport's flow control
+	data := strings.Repeat("x", 1024*1024)
+	for i := 0; i < connWindow; i++ {
+		require.NoError(t, sp.Publish("orders", data), "expected no error after the publishing")
+	}
+	time.Sleep(time.Millisecond * 200)
+
+	closed := make(chan struct{})
+	go func() {
+		server.Close()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the server's closing not to be blocked by the stalled client")
+	}
+}
+
+func TestConnectUnsubscribeReleasesCredit(t *testing.T) {
+	sp := subpub.NewSubPub()
+	_, client := startTestServer(t, sp)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	stream, err := client.Connect(ctx)
+	require.NoError(t, err, "expected no error after the connecting")
+
+	// request sends the frame with the correlation id and returns its result skipping the deliveries.
+	request := func(frame *sprpc.ClientFrame) *sprpc.OpResult {
+		frame.CorrelationId = "request"
+		require.NoError(t, stream.Send(frame), "expected no error after the frame's sending")
+
+		for {
+			reply, err := stream.Recv()
+			require.NoError(t, err, "expected no error after the frame's receiving")
+
+			if result := reply.GetResult(); result != nil {
+				return result
+			}
+			require.NotEqual(t, "orders", reply.GetEvent().GetKey(), "expected no delivery of the unsubscribed subscription")
+		}
+	}
+
+	request(&sprpc.ClientFrame{Op: &sprpc.ClientFrame_Flow{Flow: &sprpc.FlowOp{Window: 1}}})
+	orders := request(&sprpc.ClientFrame{Op: &sprpc.ClientFrame_Subscribe{Subscribe: &sprpc.SubscribeRequest{Key: "orders"}}})
+
+	require.NoError(t, sp.Publish("orders", "order-0"), "expected no error after the publishing")
+	require.NoError(t, sp.Publish("orders", "order-1"), "expected no error after the publishing")
+
+	frame, err := stream.Recv()
+	require.NoError(t, err, "expected no error after the frame's receiving")
+	first := frame.GetEvent()
+	require.Equal(t, "order-0", first.GetData(), "expected the first delivery to use the only credit")
+
+	// the second delivery waits for the credit while the subscription is cancelled
+	request(&sprpc.ClientFrame{Op: &sprpc.ClientFrame_Unsubscribe{Unsubscribe: &sprpc.UnsubscribeOp{SubscriptionId: orders.SubscriptionId}}})
+	request(&sprpc.ClientFrame{Op: &sprpc.ClientFrame_Subscribe{Subscribe: &sprpc.SubscribeRequest{Key: "billing"}}})
+	request(&sprpc.ClientFrame{Op: &sprpc.ClientFrame_Ack{Ack: &sprpc.AckOp{DeliveryId: first.DeliveryId}}})
+
+	require.NoError(t, sp.Publish("billing", "bill-0"), "expected no error after the publishing")
+
+	frame, err = stream.Recv()
+	require.NoError(t, err, "expected the credit to be left for the active subscription")
+	assert.Equal(t, "bill-0", frame.GetEvent().GetData(), "expected the delivery of the active subscription")
+}
@@ -1,10 +1,44 @@
 package spserv
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
 var (
-	ErrNetOpenConn      = errors.New("error of opening the connection")
-	ErrSendingMsg       = errors.New("error of sending the message")
-	ErrServiceCondition = errors.New("error of service's condition")
-	ErrDataRequest      = errors.New("error of the request's data")
+	ErrNetOpenConn       = errors.New("error of opening the connection")
+	ErrSendingMsg        = errors.New("error of sending the message")
+	ErrServiceCondition  = errors.New("error of service's condition")
+	ErrDataRequest       = errors.New("error of the request's data")
+	ErrSubNotFound       = errors.New("error of the subscription's search")
+	ErrScheduledNotFound = errors.New("error of the scheduled message's search")
+	ErrTLSConfig         = errors.New("error of the TLS configuration")
+	ErrAuthConfig        = errors.New("error of the authentication's configuration")
+	ErrAuthentication    = errors.New("error of the client's authentication")
+	ErrPermission        = errors.New("error of the client's permission")
+	ErrLimitExceeded     = errors.New("error of the limits")
+	ErrRateConfig        = errors.New("error of the rate limits' configuration")
+	ErrPolicyConfig      = errors.New("error of the subjects' policies' configuration")
+	ErrReload            = errors.New("error of the config's reloading")
+	ErrSubKicked         = errors.New("error of the subscription's condition: it was kicked by the administrator")
 )
+
+// statusOf converts the sub-pub system's err into the grpc's error with the code of the err's kind.
+// The RetryInfo's details are added if the err exceeds the rate's limit.
+func statusOf(err error) error {
+	code := codes.Unavailable
+	srvErr := fmt.Errorf("%w: %s", ErrServiceCondition, err)
+
+	if errors.Is(err, subpub.ErrInputData) {
+		code = codes.InvalidArgument
+		srvErr = fmt.Errorf("%w: %s", ErrDataRequest, err)
+	} else if errors.Is(err, subpub.ErrLimit) {
+		code = codes.ResourceExhausted
+		srvErr = fmt.Errorf("%w: %s", ErrLimitExceeded, err)
+	}
+	return withRetryInfo(status.New(code, srvErr.Error()), err)
+}
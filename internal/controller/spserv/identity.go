@@ -0,0 +1,101 @@
+package spserv
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Identity defines the authenticated client's identity.
+type Identity struct {
+	// Name defines the client's name, e.g. the common name of its certificate.
+	Name string
+
+	// Account defines the account that the client belongs to (empty means the default one).
+	Account string
+}
+
+// account returns the name of the identity's account.
+func (id Identity) account() string {
+	if id.Account == "" {
+		return subpub.DefaultAccount
+	}
+	return id.Account
+}
+
+type identityKey struct{}
+
+// withIdentity returns the copy of the ctx that stores the client's identity.
+func withIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns the client's identity stored in the request's ctx.
+func IdentityFromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// tlsIdentity returns the identity of the client's verified certificate:
+// the common name or, if it's empty, the first of the certificate's SANs.
+func tlsIdentity(ctx context.Context) (Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return Identity{}, false
+	}
+
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return Identity{}, false
+	}
+	cert := info.State.VerifiedChains[0][0]
+
+	switch {
+	case cert.Subject.CommonName != "":
+		return Identity{Name: cert.Subject.CommonName}, true
+	case len(cert.URIs) != 0:
+		return Identity{Name: cert.URIs[0].String()}, true
+	case len(cert.DNSNames) != 0:
+		return Identity{Name: cert.DNSNames[0]}, true
+	case len(cert.EmailAddresses) != 0:
+		return Identity{Name: cert.EmailAddresses[0]}, true
+	}
+
+	return Identity{}, false
+}
+
+// identify returns the request's ctx with the client's identity if it was authenticated.
+func identify(ctx context.Context) context.Context {
+	if id, ok := tlsIdentity(ctx); ok {
+		return withIdentity(ctx, id)
+	}
+	return ctx
+}
+
+// requestLog returns the logger with the attributes of the request's peer and identity.
+func requestLog(log *slog.Logger, ctx context.Context) *slog.Logger {
+	attrs := make([]any, 0, 3)
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		attrs = append(attrs, slog.String("peer", p.Addr.String()))
+	}
+	if id, ok := IdentityFromContext(ctx); ok {
+		attrs = append(attrs, slog.String("identity", id.Name), slog.String("account", id.account()))
+	}
+
+	return log.With(attrs...)
+}
+
+// identityStream defines the server's stream with the context that stores the client's identity.
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,224 @@
+package spserv
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+)
+
+// jwtVerifier defines the logic of the signed JWTs' local verifying.
+// Only the algorithm that matches the configured key is accepted.
+type jwtVerifier struct {
+	// hmacSecret defines the shared secret of the HS256 tokens.
+	hmacSecret []byte
+
+	// publicKey defines the RSA (RS256) or the ECDSA P-256 (ES256) key of the signed tokens.
+	publicKey crypto.PublicKey
+
+	// issuer and audience define the required claims' values (empty means any).
+	issuer   string
+	audience string
+
+	now func() time.Time
+}
+
+// jwtClaims defines the verified claims of the token.
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+}
+
+// audience defines the aud claim that may be either the string or the array of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+
+	return nil
+}
+
+// newJWTVerifier creates the verifier of the HMAC secret or the PEM public key's file.
+func newJWTVerifier(hmacSecret, publicKeyFile, issuer, audience string) (*jwtVerifier, error) {
+	if (hmacSecret == "") == (publicKeyFile == "") {
+		return nil, errors.New("exactly one of the jwt's hmac_secret and public_key_file must be set")
+	}
+
+	v := &jwtVerifier{
+		issuer:   issuer,
+		audience: audience,
+		now:      time.Now,
+	}
+
+	if hmacSecret != "" {
+		v.hmacSecret = []byte(hmacSecret)
+		return v, nil
+	}
+
+	data, err := os.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data was found in the %s", publicKeyFile)
+	}
+
+	var key any
+	if block.Type == "CERTIFICATE" {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = cert.PublicKey
+	} else if key, err = x509.ParsePKIXPublicKey(block.Bytes); err != nil {
+		return nil, err
+	}
+
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+	case *ecdsa.PublicKey:
+		if k.Curve != elliptic.P256() {
+			return nil, errors.New("only the P-256 curve is supported for the ECDSA keys")
+		}
+	default:
+		return nil, fmt.Errorf("the key's type %T isn't supported", key)
+	}
+	v.publicKey = key
+
+	return v, nil
+}
+
+// verify checks the token's signature and claims and returns the identity of its subject.
+func (v *jwtVerifier) verify(token string) (Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Identity{}, errors.New("the token is malformed")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Identity{}, fmt.Errorf("the token's header is malformed: %s", err)
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, errors.New("the token's signature is malformed")
+	}
+
+	if err := v.verifySignature(header.Alg, parts[0]+"."+parts[1], sig); err != nil {
+		return Identity{}, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, fmt.Errorf("the token's claims are malformed: %s", err)
+	}
+
+	now := v.now().Unix()
+	switch {
+	case claims.Subject == "":
+		return Identity{}, errors.New("the token's subject is empty")
+	case claims.ExpiresAt != nil && now >= *claims.ExpiresAt:
+		return Identity{}, errors.New("the token is expired")
+	case claims.NotBefore != nil && now < *claims.NotBefore:
+		return Identity{}, errors.New("the token isn't valid yet")
+	case v.issuer != "" && claims.Issuer != v.issuer:
+		return Identity{}, errors.New("the token's issuer is unexpected")
+	case v.audience != "" && !claims.Audience.contains(v.audience):
+		return Identity{}, errors.New("the token's audience is unexpected")
+	}
+
+	return Identity{Name: claims.Subject}, nil
+}
+
+// verifySignature checks the signature of the signed part with the configured key.
+func (v *jwtVerifier) verifySignature(alg, signed string, sig []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+
+	switch key := v.publicKey.(type) {
+	case nil:
+		if alg != "HS256" {
+			break
+		}
+		mac := hmac.New(sha256.New, v.hmacSecret)
+		mac.Write([]byte(signed))
+
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return errors.New("the token's signature is invalid")
+		}
+		return nil
+
+	case *rsa.PublicKey:
+		if alg != "RS256" {
+			break
+		}
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) != nil {
+			return errors.New("the token's signature is invalid")
+		}
+		return nil
+
+	case *ecdsa.PublicKey:
+		if alg != "ES256" {
+			break
+		}
+		if len(sig) != 64 {
+			return errors.New("the token's signature is malformed")
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return errors.New("the token's signature is invalid")
+		}
+		return nil
+	}
+
+	return fmt.Errorf("the token's algorithm '%s' isn't accepted", alg)
+}
+
+// decodeSegment decodes the token's base64url JSON segment into the dst.
+func decodeSegment(segment string, dst any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// contains checks whether the audience includes the aud.
+func (a audience) contains(aud string) bool {
+	for _, val := range a {
+		if val == aud {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+package spserv
+
+import (
+	"log/slog"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// LimitsConfig defines the limits of the service's grpc-servers, the zero value means the grpc's default.
+type LimitsConfig struct {
+	// MaxRecvMsgSize and MaxSendMsgSize define the max sizes of the messages in bytes.
+	MaxRecvMsgSize int
+	MaxSendMsgSize int
+
+	// MaxConcurrentStreams defines the max count of the concurrent streams of the single connection.
+	MaxConcurrentStreams uint32
+
+	// MaxConnections defines the max count of the main socket's connections, the extra ones are closed at once.
+	MaxConnections int
+
+	// KeepaliveTime defines the idle time of the connection after which the server pings the client,
+	// the client that doesn't answer during the KeepaliveTimeout is treated as dead and its streams are closed.
+	KeepaliveTime    time.Duration
+	KeepaliveTimeout time.Duration
+
+	// KeepaliveMinTime defines the min interval of the clients' pings, the clients that ping
+	// more frequently are disconnected.
+	KeepaliveMinTime time.Duration
+
+	// KeepalivePermitWithoutStream defines whether the clients may ping the connections without the streams.
+	KeepalivePermitWithoutStream bool
+
+	// MaxConnectionIdle defines the time after which the connection without the streams is closed.
+	MaxConnectionIdle time.Duration
+
+	// MaxConnectionAge defines the time after which the connection is closed gracefully,
+	// its streams are given the MaxConnectionAgeGrace to finish before the forced closing.
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+}
+
+// serverOptions returns the grpc-server's options of the limits.
+func (c LimitsConfig) serverOptions() []grpc.ServerOption {
+	opts := []grpc.ServerOption{
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			MaxConnectionIdle:     c.MaxConnectionIdle,
+			MaxConnectionAge:      c.MaxConnectionAge,
+			MaxConnectionAgeGrace: c.MaxConnectionAgeGrace,
+			Time:                  c.KeepaliveTime,
+			Timeout:               c.KeepaliveTimeout,
+		}),
+	}
+
+	if c.KeepaliveMinTime > 0 || c.KeepalivePermitWithoutStream {
+		opts = append(opts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             c.KeepaliveMinTime,
+			PermitWithoutStream: c.KeepalivePermitWithoutStream,
+		}))
+	}
+
+	if c.MaxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(c.MaxRecvMsgSize))
+	}
+	if c.MaxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(c.MaxSendMsgSize))
+	}
+	if c.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+
+	return opts
+}
+
+// limitListener defines the listener that closes the accepted connections over the limit.
+type limitListener struct {
+	net.Listener
+	log *slog.Logger
+
+	limit  int64
+	active atomic.Int64
+}
+
+func newLimitListener(log *slog.Logger, lis net.Listener, limit int) *limitListener {
+	return &limitListener{
+		Listener: lis,
+		log:      log,
+		limit:    int64(limit),
+	}
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+
+		if l.active.Add(1) <= l.limit {
+			return &limitConn{Conn: conn, release: func() { l.active.Add(-1) }}, nil
+		}
+		l.active.Add(-1)
+
+		l.log.Warn("the connection over the limit was closed", slog.String("peer", conn.RemoteAddr().String()), slog.Int64("limit", l.limit))
+		conn.Close()
+	}
+}
+
+// limitConn defines the connection that releases its place in the limit after the closing.
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+
+	return err
+}
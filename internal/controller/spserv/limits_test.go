@@ -0,0 +1,173 @@
+package spserv
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// startLimitsService starts the service with the limits on the local socket and returns its server and address.
+func startLimitsService(t *testing.T, conf LimitsConfig) (*SubPubServer, string) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := NewSubPubServer(log, subpub.NewSubPub())
+
+	service, err := NewSubPubService(log, "127.0.0.1:0", server, WithLimits(conf))
+	require.NoError(t, err, "expected no error after the service's creating")
+
+	go service.Run()
+	t.Cleanup(service.Close)
+
+	return server, service.conn.Addr().String()
+}
+
+// dialInsecure returns the client's connection to the addr.
+func dialInsecure(t *testing.T, addr string) *grpc.ClientConn {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err, "expected no error after the client's creating")
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// freezingProxy defines the TCP proxy that can stop forwarding the data keeping the connections open,
+// like the network that lost the peer without the connection's closing.
+type freezingProxy struct {
+	lis    net.Listener
+	target string
+	frozen atomic.Bool
+}
+
+func startFreezingProxy(t *testing.T, target string) *freezingProxy {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "expected no error after the proxy's listening")
+	t.Cleanup(func() { lis.Close() })
+
+	p := &freezingProxy{lis: lis, target: target}
+
+	go func() {
+		for {
+			client, err := lis.Accept()
+			if err != nil {
+				return
+			}
+
+			server, err := net.Dial("tcp", target)
+			if err != nil {
+				client.Close()
+				return
+			}
+			t.Cleanup(func() {
+				client.Close()
+				server.Close()
+			})
+
+			go p.forward(client, server)
+			go p.forward(server, client)
+		}
+	}()
+
+	return p
+}
+
+// forward copies the data until the proxy is frozen.
+func (p *freezingProxy) forward(dst, src net.Conn) {
+	buf := make([]byte, 32*1024)
+
+	for {
+		n, err := src.Read(buf)
+		if err != nil || p.frozen.Load() {
+			return
+		}
+
+		if _, err := dst.Write(buf[:n]); err != nil {
+			return
+		}
+	}
+}
+
+func TestLimitsMsgSize(t *testing.T) {
+	_, addr := startLimitsService(t, LimitsConfig{MaxRecvMsgSize: 1024})
+	client := sprpc.NewPubSubClient(dialInsecure(t, addr))
+
+	_, err := client.Publish(context.Background(), &sprpc.PublishRequest{Key: "test-channel", Data: strings.Repeat("x", 2048)})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err), "expected the error of the message over the max size")
+}
+
+func TestLimitListener(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err, "expected no error after the listening")
+
+	limited := newLimitListener(slog.New(slog.NewTextHandler(io.Discard, nil)), lis, 1)
+	defer limited.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := limited.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	first, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err, "expected no error after the dialing")
+	defer first.Close()
+	firstServer := <-accepted
+
+	second, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err, "expected no error after the dialing")
+	defer second.Close()
+
+	second.SetReadDeadline(time.Now().Add(time.Second * 5))
+	_, err = second.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "expected the connection over the limit to be closed")
+
+	firstServer.Close()
+
+	third, err := net.Dial("tcp", lis.Addr().String())
+	require.NoError(t, err, "expected no error after the dialing")
+	defer third.Close()
+
+	select {
+	case conn := <-accepted:
+		conn.Close()
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the connection to be accepted after the place's releasing")
+	}
+}
+
+func TestKeepaliveReapsDeadSubscriber(t *testing.T) {
+	server, addr := startLimitsService(t, LimitsConfig{KeepaliveTime: time.Second, KeepaliveTimeout: time.Second})
+	proxy := startFreezingProxy(t, addr)
+
+	client := sprpc.NewPubSubClient(dialInsecure(t, proxy.lis.Addr().String()))
+
+	stream, err := client.Subscribe(context.Background(), &sprpc.SubscribeRequest{Key: "test-channel"})
+	require.NoError(t, err, "expected no error after the subscribing")
+
+	_, err = stream.Recv()
+	require.NoError(t, err, "expected the subscription's id to be received")
+	require.Equal(t, 1, server.ActiveSubscriptions(), "expected the active subscription")
+
+	proxy.frozen.Store(true)
+
+	assert.Eventually(t, func() bool {
+		return server.ActiveSubscriptions() == 0
+	}, time.Second*10, time.Millisecond*100, "expected the subscription of the dead client to be reaped")
+}
@@ -0,0 +1,133 @@
+package spserv
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"gopkg.in/yaml.v3"
+)
+
+// policiesFile defines the structure of the subjects' policies' config file.
+type policiesFile struct {
+	Subjects []struct {
+		Subject     string        `yaml:"subject"`
+		TTL         time.Duration `yaml:"ttl"`
+		DedupWindow time.Duration `yaml:"dedup_window"`
+		DedupCount  int           `yaml:"dedup_count"`
+	} `yaml:"subjects"`
+}
+
+// SubjectPolicies defines the subjects' own policies of the sub-pub system that are loaded from the YAML file.
+// The subjects are the shared system's names: with the accounts they have the form '<account>:<subject>'.
+type SubjectPolicies struct {
+	path string
+	sp   subpub.SubPub
+
+	mut sync.Mutex
+
+	// policies defines the applied policies by their subjects.
+	policies map[string]subpub.SubjectPolicy
+
+	// digest defines the hash of the applied file's content.
+	digest [sha256.Size]byte
+}
+
+// NewSubjectPolicies loads the subjects' policies from the YAML file and applies them to the sp.
+func NewSubjectPolicies(path string, sp subpub.SubPub) (*SubjectPolicies, error) {
+	p := &SubjectPolicies{
+		path: path,
+		sp:   sp,
+	}
+
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload re-reads the file replacing the policies, the subjects removed from the file get the default policy.
+func (p *SubjectPolicies) Reload() error {
+	step, err := p.Prepare()
+	if err != nil {
+		return err
+	}
+	step.Apply()
+
+	return nil
+}
+
+// Prepare re-reads and validates the file without applying it: the step's Apply replaces the policies.
+func (p *SubjectPolicies) Prepare() (ReloadStep, error) {
+	policies, digest, err := loadPolicies(p.path)
+	if err != nil {
+		return ReloadStep{}, err
+	}
+
+	p.mut.Lock()
+	changed := p.policies == nil || digest != p.digest
+	p.mut.Unlock()
+
+	return ReloadStep{
+		Changed: changed,
+		Apply: func() {
+			p.mut.Lock()
+			defer p.mut.Unlock()
+
+			// the subjects were validated by the loading, so the shared system accepts them
+			for subject := range p.policies {
+				if _, ok := policies[subject]; !ok {
+					p.sp.ResetPolicy(subject)
+				}
+			}
+
+			for subject, policy := range policies {
+				p.sp.SetPolicy(subject, policy)
+			}
+			p.policies, p.digest = policies, digest
+		},
+	}, nil
+}
+
+// loadPolicies reads the subjects' policies from the YAML file, the digest is the hash of the file's content.
+func loadPolicies(path string) (map[string]subpub.SubjectPolicy, [sha256.Size]byte, error) {
+	const op = "spserv.loadPolicies"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, [sha256.Size]byte{}, fmt.Errorf("error of the %s: %w: %s", op, ErrPolicyConfig, err)
+	}
+
+	var file policiesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, [sha256.Size]byte{}, fmt.Errorf("error of the %s: %w: %s", op, ErrPolicyConfig, err)
+	}
+
+	policies := make(map[string]subpub.SubjectPolicy, len(file.Subjects))
+	for _, entry := range file.Subjects {
+		if entry.Subject == "" {
+			return nil, [sha256.Size]byte{}, fmt.Errorf("error of the %s: %w: the subject is empty", op, ErrPolicyConfig)
+		}
+
+		if entry.TTL < 0 || entry.DedupWindow < 0 || entry.DedupCount < 0 {
+			return nil, [sha256.Size]byte{}, fmt.Errorf("error of the %s: %w: the policy of the '%s' must be non-negative",
+				op, ErrPolicyConfig, entry.Subject)
+		}
+
+		if _, ok := policies[entry.Subject]; ok {
+			return nil, [sha256.Size]byte{}, fmt.Errorf("error of the %s: %w: the policy of the '%s' is duplicated",
+				op, ErrPolicyConfig, entry.Subject)
+		}
+
+		policies[entry.Subject] = subpub.SubjectPolicy{
+			TTL:         entry.TTL,
+			DedupWindow: entry.DedupWindow,
+			DedupCount:  entry.DedupCount,
+		}
+	}
+
+	return policies, sha256.Sum256(data), nil
+}
@@ -0,0 +1,81 @@
+package spserv
+
+import (
+	"testing"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testPoliciesFile = `
+subjects:
+  - subject: orders
+    dedup_count: 10
+    ttl: 1m
+`
+
+// newTestPolicies returns the sub-pub system with the subscription on the orders and the policies loaded from the data.
+func newTestPolicies(t *testing.T, data string) (subpub.SubPub, *SubjectPolicies, string) {
+	sp := subpub.NewSubPub()
+	_, err := sp.Subscribe("orders", func(interface{}) {})
+	require.NoError(t, err, "expected no error after the subscribing")
+
+	path := writeConfigFile(t, "", data)
+
+	policies, err := NewSubjectPolicies(path, sp)
+	require.NoError(t, err, "expected no error after the policies' loading")
+
+	return sp, policies, path
+}
+
+// publishDuplicate publishes the order with the id and returns whether it was skipped as the duplicate.
+func publishDuplicate(t *testing.T, sp subpub.SubPub, id string) bool {
+	var dup bool
+	require.NoError(t, sp.Publish("orders", "order", subpub.WithMsgID(id), subpub.WithDuplicate(&dup)), "expected no error after the publishing")
+
+	return dup
+}
+
+func TestSubjectPoliciesPositiveCases(t *testing.T) {
+	sp, policies, path := newTestPolicies(t, testPoliciesFile)
+
+	assert.False(t, publishDuplicate(t, sp, "id-0"), "expected the first message not to be the duplicate")
+	assert.True(t, publishDuplicate(t, sp, "id-0"), "expected the subject's own policy to be applied")
+
+	step, err := policies.Prepare()
+	require.NoError(t, err, "expected no error after the preparing")
+	assert.False(t, step.Changed, "expected the same file not to be reported as the changed one")
+
+	writeConfigFile(t, path, "subjects: []\n")
+
+	step, err = policies.Prepare()
+	require.NoError(t, err, "expected no error after the preparing")
+	assert.True(t, step.Changed, "expected the file to be reported as the changed one")
+
+	assert.True(t, publishDuplicate(t, sp, "id-0"), "expected the prepared policies not to be applied before the Apply")
+
+	step.Apply()
+	assert.False(t, publishDuplicate(t, sp, "id-0"), "expected the default policy after the subject's removing from the file")
+}
+
+func TestSubjectPoliciesNegativeCases(t *testing.T) {
+	cases := []string{
+		"subjects: [",
+		"subjects:\n  - dedup_count: 1\n",
+		"subjects:\n  - subject: orders\n    dedup_count: -1\n",
+		"subjects:\n  - subject: orders\n  - subject: orders\n",
+	}
+
+	for _, data := range cases {
+		_, err := NewSubjectPolicies(writeConfigFile(t, "", data), subpub.NewSubPub())
+		assert.ErrorIs(t, err, ErrPolicyConfig, "expected the error of the file %q", data)
+	}
+
+	sp, policies, path := newTestPolicies(t, testPoliciesFile)
+	assert.False(t, publishDuplicate(t, sp, "id-0"), "expected the first message not to be the duplicate")
+
+	writeConfigFile(t, path, cases[0])
+	assert.ErrorIs(t, policies.Reload(), ErrPolicyConfig, "expected the error of the broken file")
+	assert.True(t, publishDuplicate(t, sp, "id-0"), "expected the previous policies to be kept after the failed reloading")
+}
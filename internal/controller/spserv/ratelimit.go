@@ -0,0 +1,208 @@
+package spserv
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// rateKeyClient and rateKeyPeer define the kinds of the publishers' keys:
+	// the authenticated client's identity and the host of the client's address.
+	rateKeyClient = "client"
+	rateKeyPeer   = "peer"
+
+	// retryAfterKey defines the trailer's key of the seconds after which the rejected request may be retried.
+	retryAfterKey = "retry-after"
+)
+
+// rateLimitConf defines the single limit of the rate limits' file.
+type rateLimitConf struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// rateLimitsFile defines the structure of the publishing's rate limits' config file.
+type rateLimitsFile struct {
+	Client rateLimitConf `yaml:"client"`
+	Peer   rateLimitConf `yaml:"peer"`
+
+	Subjects []struct {
+		Pattern       string `yaml:"pattern"`
+		rateLimitConf `yaml:",inline"`
+	} `yaml:"subjects"`
+}
+
+// RateLimits defines the limits of the clients' publishing rate by the clients' identities,
+// by the clients' addresses and by the subjects' patterns.
+type RateLimits struct {
+	path    string
+	limiter *subpub.RateLimiter
+
+	// digest defines the hash of the applied file's content.
+	digest atomic.Pointer[[sha256.Size]byte]
+}
+
+// NewRateLimits loads the publishing's rate limits from the YAML file.
+func NewRateLimits(path string) (*RateLimits, error) {
+	const op = "spserv.NewRateLimits"
+
+	limits, digest, err := loadRateLimits(path)
+	if err != nil {
+		return nil, err
+	}
+
+	limiter, err := subpub.NewRateLimiter(subpub.NewRealClock(), limits)
+	if err != nil {
+		return nil, fmt.Errorf("error of the %s: %w: %s", op, ErrRateConfig, err)
+	}
+
+	rates := &RateLimits{
+		path:    path,
+		limiter: limiter,
+	}
+	rates.digest.Store(&digest)
+
+	return rates, nil
+}
+
+// Reload re-reads the file replacing the limits, the buckets of the unchanged limits are kept.
+func (r *RateLimits) Reload() error {
+	step, err := r.Prepare()
+	if err != nil {
+		return err
+	}
+	step.Apply()
+
+	return nil
+}
+
+// Prepare re-reads and validates the file without applying it: the step's Apply replaces the limits.
+func (r *RateLimits) Prepare() (ReloadStep, error) {
+	const op = "spserv.RateLimits.Prepare"
+
+	limits, digest, err := loadRateLimits(r.path)
+	if err != nil {
+		return ReloadStep{}, err
+	}
+
+	if err := limits.Validate(); err != nil {
+		return ReloadStep{}, fmt.Errorf("error of the %s: %w: %s", op, ErrRateConfig, err)
+	}
+
+	return ReloadStep{
+		Changed: digest != *r.digest.Load(),
+		Apply: func() {
+			// the limits were validated by the preparing
+			r.limiter.SetLimits(limits)
+			r.digest.Store(&digest)
+		},
+	}, nil
+}
+
+// loadRateLimits reads the rate limits from the YAML file, the digest is the hash of the file's content.
+func loadRateLimits(path string) (subpub.RateLimits, [sha256.Size]byte, error) {
+	const op = "spserv.loadRateLimits"
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return subpub.RateLimits{}, [sha256.Size]byte{}, fmt.Errorf("error of the %s: %w: %s", op, ErrRateConfig, err)
+	}
+
+	var file rateLimitsFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return subpub.RateLimits{}, [sha256.Size]byte{}, fmt.Errorf("error of the %s: %w: %s", op, ErrRateConfig, err)
+	}
+
+	limits := subpub.RateLimits{
+		Keys: map[string]subpub.RateLimit{
+			rateKeyClient: {Rate: file.Client.Rate, Burst: file.Client.Burst},
+			rateKeyPeer:   {Rate: file.Peer.Rate, Burst: file.Peer.Burst},
+		},
+		Subjects: make([]subpub.SubjectRateLimit, 0, len(file.Subjects)),
+	}
+
+	for _, subject := range file.Subjects {
+		limits.Subjects = append(limits.Subjects, subpub.SubjectRateLimit{
+			Pattern:   subject.Pattern,
+			RateLimit: subpub.RateLimit{Rate: subject.Rate, Burst: subject.Burst},
+		})
+	}
+
+	return limits, sha256.Sum256(data), nil
+}
+
+// allow checks the limits of the client's publishing into the subjects (the subject is repeated for every its message).
+// The anonymous client is limited by its address only.
+func (r *RateLimits) allow(ctx context.Context, subjects ...string) error {
+	if r == nil {
+		return nil
+	}
+	keys := make(subpub.RateKeys, 2)
+
+	if id, ok := IdentityFromContext(ctx); ok && id.Name != "" {
+		keys[rateKeyClient] = id.Name
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		keys[rateKeyPeer] = host
+	}
+
+	return r.limiter.Allow(keys, subjects...)
+}
+
+// withRetryInfo returns the error of the st with the RetryInfo's details if the err exceeds the rate's limit.
+func withRetryInfo(st *status.Status, err error) error {
+	var rateErr *subpub.RateLimitError
+	if !errors.As(err, &rateErr) {
+		return st.Err()
+	}
+
+	detailed, detErr := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(rateErr.RetryAfter)})
+	if detErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// retryDelay returns the delay of the retrying from the error's RetryInfo details.
+func retryDelay(err error) (time.Duration, bool) {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			return info.RetryDelay.AsDuration(), true
+		}
+	}
+	return 0, false
+}
+
+// retryAfterInterceptor defines the interceptor that copies the delay of the retrying of the rejected
+// unary request into the retry-after trailer in the whole seconds.
+func retryAfterInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+
+	if delay, ok := retryDelay(err); ok {
+		secs := strconv.Itoa(int(math.Ceil(delay.Seconds())))
+		grpc.SetTrailer(ctx, metadata.Pairs(retryAfterKey, secs))
+	}
+	return resp, err
+}
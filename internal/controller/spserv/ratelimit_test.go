@@ -0,0 +1,167 @@
+package spserv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const testRateLimitsFile = `
+peer:
+  rate: 2
+subjects:
+  - pattern: "orders.*"
+    rate: 1
+`
+
+// startRateService starts the service with the rate limits on the local socket and returns
+// the limits and the client, the subjects get the subscriptions for the publishing.
+func startRateService(t *testing.T, path string, subjects ...string) (*RateLimits, sprpc.PubSubClient) {
+	rates, err := NewRateLimits(path)
+	require.NoError(t, err, "expected no error after the rate limits' loading")
+
+	sp := subpub.NewSubPub()
+	for _, subject := range subjects {
+		_, err := sp.Subscribe(subject, func(interface{}) {})
+		require.NoError(t, err, "expected no error after the subscribing")
+	}
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service, err := NewSubPubService(log, "127.0.0.1:0", NewSubPubServer(log, sp, WithRateLimits(rates)))
+	require.NoError(t, err, "expected no error after the service's creating")
+
+	go service.Run()
+	t.Cleanup(service.Close)
+
+	return rates, sprpc.NewPubSubClient(dialInsecure(t, service.conn.Addr().String()))
+}
+
+// writeConfigFile writes the config file (the new temporary one if the path is empty) and returns its path.
+func writeConfigFile(t *testing.T, path, data string) string {
+	if path == "" {
+		path = filepath.Join(t.TempDir(), "config.yaml")
+	}
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600), "expected no error after the file's writing")
+
+	return path
+}
+
+func TestRateLimitsPositiveCases(t *testing.T) {
+	t.Run("TestRateLimitsPositiveCases_RetryAfter", func(t *testing.T) {
+		_, client := startRateService(t, writeConfigFile(t, "", testRateLimitsFile), "orders.eu", "orders.us")
+
+		_, err := client.Publish(context.Background(), &sprpc.PublishRequest{Key: "orders.eu", Data: "order-0"})
+		require.NoError(t, err, "expected no error inside the limits")
+
+		var trailer metadata.MD
+		_, err = client.Publish(context.Background(), &sprpc.PublishRequest{Key: "orders.eu", Data: "order-1"}, grpc.Trailer(&trailer))
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err), "expected the error of the subject's limit")
+		assert.Equal(t, []string{"1"}, trailer.Get(retryAfterKey), "expected the retry-after in the whole seconds")
+
+		delay, ok := retryDelay(err)
+		assert.True(t, ok && delay > 0 && delay <= time.Second, "expected the retry's delay in the error's details")
+
+		_, err = client.Publish(context.Background(), &sprpc.PublishRequest{Key: "orders.us", Data: "order-2"})
+		assert.NoError(t, err, "expected the separate limit of the other subject")
+
+		_, err = client.PublishTx(context.Background(), &sprpc.PublishTxRequest{Messages: []*sprpc.PublishRequest{
+			{Key: "orders.us", Data: "order-3"},
+		}})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err), "expected the transaction to be limited")
+	})
+
+	t.Run("TestRateLimitsPositiveCases_Connect", func(t *testing.T) {
+		_, client := startRateService(t, writeConfigFile(t, "", testRateLimitsFile), "events")
+
+		stream, err := client.Connect(context.Background())
+		require.NoError(t, err, "expected no error after the connecting")
+
+		var results []*sprpc.OpResult
+		for _, id := range []string{"publish-0", "publish-1", "publish-2"} {
+			require.NoError(t, stream.Send(&sprpc.ClientFrame{CorrelationId: id, Op: &sprpc.ClientFrame_Publish{
+				Publish: &sprpc.PublishRequest{Key: "events", Data: id},
+			}}), "expected no error after the frame's sending")
+
+			frame, err := stream.Recv()
+			require.NoError(t, err, "expected no error after the frame's receiving")
+			results = append(results, frame.GetResult())
+		}
+
+		assert.Equal(t, int32(codes.OK), results[1].Code, "expected the publishing inside the peer's burst")
+		assert.Equal(t, int32(codes.ResourceExhausted), results[2].Code, "expected the error of the peer's limit")
+		assert.NotNil(t, results[2].RetryAfter, "expected the retry-after in the operation's result")
+	})
+
+	t.Run("TestRateLimitsPositiveCases_Reload", func(t *testing.T) {
+		path := writeConfigFile(t, "", testRateLimitsFile)
+		rates, client := startRateService(t, path, "orders.eu")
+
+		_, err := client.Publish(context.Background(), &sprpc.PublishRequest{Key: "orders.eu", Data: "order-0"})
+		require.NoError(t, err, "expected no error inside the limits")
+
+		step, err := rates.Prepare()
+		require.NoError(t, err, "expected no error after the preparing")
+		assert.False(t, step.Changed, "expected the same file not to be reported as the changed one")
+
+		writeConfigFile(t, path, "peer:\n  rate: 2\n")
+		require.NoError(t, rates.Reload(), "expected no error after the reloading")
+
+		_, err = client.Publish(context.Background(), &sprpc.PublishRequest{Key: "orders.eu", Data: "order-1"})
+		assert.NoError(t, err, "expected the removed subject's limit not to be applied")
+	})
+}
+
+func TestRateLimitsNegativeCases(t *testing.T) {
+	_, err := NewRateLimits(filepath.Join(t.TempDir(), "absent.yaml"))
+	assert.ErrorIs(t, err, ErrRateConfig, "expected the error of the absent file")
+
+	_, err = NewRateLimits(writeConfigFile(t, "", "client:\n  rate: -1\n"))
+	assert.ErrorIs(t, err, ErrRateConfig, "expected the error of the negative rate")
+
+	path := writeConfigFile(t, "", testRateLimitsFile)
+	rates, err := NewRateLimits(path)
+	require.NoError(t, err, "expected no error after the rate limits' loading")
+
+	writeConfigFile(t, path, "subjects: [")
+	assert.ErrorIs(t, rates.Reload(), ErrRateConfig, "expected the error of the broken file")
+}
+
+func TestStatusOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "TestStatusOf_InputData", err: subpub.ErrInputData, code: codes.InvalidArgument},
+		{name: "TestStatusOf_Limit", err: &subpub.RateLimitError{Limit: "subject", RetryAfter: time.Second}, code: codes.ResourceExhausted},
+		{name: "TestStatusOf_SystemCondition", err: subpub.ErrSystemCondition, code: codes.Unavailable},
+		{name: "TestStatusOf_Unknown", err: errors.New("unknown"), code: codes.Unavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := statusOf(tt.err)
+			assert.Equal(t, tt.code, status.Code(err), "expected the code of the error's kind")
+
+			delay, ok := retryDelay(err)
+			assert.Equal(t, tt.code == codes.ResourceExhausted, ok, "expected the retry info for the rate's limit only")
+			if ok {
+				assert.Equal(t, time.Second, delay, "expected the delay of the rate's limit")
+			}
+		})
+	}
+}
@@ -6,15 +6,24 @@ import (
 	"fmt"
 	"log/slog"
 	"sync/atomic"
+	"time"
 
 	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
 	"github.com/MaKcm14/sub-pub/pkg/subpub"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// subCapacity defines the max count of the messages buffered for the single remote subscriber,
+// e.g. while it's paused.
+const subCapacity = 1024
+
 // SubPubServer defines the logic of handling the grpc's requests.
 type SubPubServer struct {
 	sprpc.UnimplementedPubSubServer
@@ -24,101 +33,524 @@ type SubPubServer struct {
 	// flagDone is the flag that stores the current service's condition.
 	flagDone atomic.Bool
 
-	// subMsgCh stores the message chans for its forced closing.
-	subMsgCh syncMap
+	// subs stores the active subscriptions by their ids for the administration and the forced closing.
+	subs syncMap[int64, *remoteSub]
+
+	// lastSubID defines the last assigned subscription's id.
+	lastSubID atomic.Int64
+
+	// conns stores the active Connect streams for their forced closing.
+	conns syncMap[int64, *connection]
+
+	// lastConnID defines the last assigned connection's id.
+	lastConnID atomic.Int64
+
+	// health defines the standard health checking service that reports the server's condition.
+	health *health.Server
+
+	// auth defines the authorization of the clients' access to the subjects (nil means everything is allowed).
+	auth *Auth
+
+	// accounts defines the isolated namespaces of the clients' accounts (nil means the single shared namespace).
+	accounts *subpub.Accounts
+
+	// heartbeat defines the interval of the Subscribe streams' heartbeats (0 means no heartbeats).
+	heartbeat time.Duration
+
+	// rates defines the limits of the clients' publishing rate (nil means no limits).
+	rates *RateLimits
+
+	// reload defines the reloading of the service's config requested by the Admin (nil means it isn't supported).
+	reload ReloadFunc
+}
+
+// ReloadReport defines the result of the config's reloading.
+type ReloadReport struct {
+	// Applied defines the settings that were applied without the restart.
+	Applied []string
+
+	// Rejected defines the changed settings that can't be applied without the restart.
+	Rejected []string
+}
+
+// ReloadStep defines the validated change of the reloaded file that isn't applied yet.
+type ReloadStep struct {
+	// Changed defines whether the file's content differs from the applied one.
+	Changed bool
 
-	// curID defines the current free chan id-number.
-	curID atomic.Int64
+	// Apply applies the change: it can't fail after the validation.
+	Apply func()
 }
 
-func NewSubPubServer(log *slog.Logger, serv subpub.SubPub) *SubPubServer {
-	return &SubPubServer{
-		log:      log,
-		serv:     serv,
-		subMsgCh: newSyncMap(),
+// ReloadFunc defines the func of the service's config reloading.
+type ReloadFunc func() (ReloadReport, error)
+
+// ServerOpt defines the func of the server's configuration.
+type ServerOpt func(s *SubPubServer)
+
+// WithAccounts makes every client work inside the namespace of its identity's account.
+func WithAccounts(accounts *subpub.Accounts) ServerOpt {
+	return func(s *SubPubServer) {
+		s.accounts = accounts
 	}
 }
 
-// Subscribe defines the logic of the handling the subscribe requests.
-func (s *SubPubServer) Subscribe(request *sprpc.SubscribeRequest, stream grpc.ServerStreamingServer[sprpc.Event]) error {
-	const op = "spserv.Subscribe"
+// WithHeartbeat makes the Subscribe streams send the heartbeat events after every interval without the events,
+// so the clients can tell the quiet subject apart from the lost connection.
+func WithHeartbeat(interval time.Duration) ServerOpt {
+	return func(s *SubPubServer) {
+		s.heartbeat = interval
+	}
+}
 
-	msgCh := make(chan string)
-	curID := int(s.curID.Load())
+// WithRateLimits makes the server limit the rate of the clients' publishing.
+func WithRateLimits(rates *RateLimits) ServerOpt {
+	return func(s *SubPubServer) {
+		s.rates = rates
+	}
+}
 
-	s.curID.Add(1)
-	s.subMsgCh.Add(int(s.curID.Load()), msgCh)
+// WithReload makes the Admin's ReloadConfig call the reload.
+func WithReload(reload ReloadFunc) ServerOpt {
+	return func(s *SubPubServer) {
+		s.reload = reload
+	}
+}
 
-	sub, err := s.serv.Subscribe(request.Key, func(msg interface{}) {
-		msgCh <- msg.(string)
-	})
+// WithAuthorization makes the server check the clients' rights on the subjects.
+func WithAuthorization(auth *Auth) ServerOpt {
+	return func(s *SubPubServer) {
+		s.auth = auth
+	}
+}
 
+func NewSubPubServer(log *slog.Logger, serv subpub.SubPub, opts ...ServerOpt) *SubPubServer {
+	server := &SubPubServer{
+		log:    log,
+		serv:   serv,
+		subs:   newSyncMap[int64, *remoteSub](),
+		conns:  newSyncMap[int64, *connection](),
+		health: health.NewServer(),
+	}
+
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	return server
+}
+
+// subPub returns the SubPub of the client's account.
+func (s *SubPubServer) subPub(ctx context.Context) (subpub.SubPub, error) {
+	if s.accounts == nil {
+		return s.serv, nil
+	}
+	id, _ := IdentityFromContext(ctx)
+
+	sp, err := s.accounts.Account(id.account())
 	if err != nil {
-		var code codes.Code
-		var subErr error
+		return nil, status.Error(codes.PermissionDenied, fmt.Sprintf("%s: %s", ErrPermission, err))
+	}
+	return sp, nil
+}
 
-		if errors.Is(err, subpub.ErrInputData) {
-			code = codes.InvalidArgument
-			subErr = fmt.Errorf("%w: %s", ErrDataRequest, err)
-		} else if errors.Is(err, subpub.ErrSystemCondition) {
-			code = codes.Unavailable
-			subErr = fmt.Errorf("%w: %s", ErrServiceCondition, err)
+// sharedSubject returns the shared system's name of the client's subject available for the subscribing,
+// it's used as the subject's key by the administration.
+func (s *SubPubServer) sharedSubject(ctx context.Context, subject string) string {
+	if s.accounts == nil {
+		return subject
+	}
+	id, _ := IdentityFromContext(ctx)
+
+	shared, err := s.accounts.Resolve(id.account(), subject)
+	if err != nil {
+		return subpub.AccountSubject(id.account(), subject)
+	}
+	return shared
+}
+
+// allowRate checks the rate's limits of the client's publishing into the subjects, with the accounts
+// the subjects are limited by the shared names of the form '<account>:<subject>'.
+func (s *SubPubServer) allowRate(ctx context.Context, subjects ...string) error {
+	if s.rates == nil {
+		return nil
+	}
+
+	if s.accounts != nil {
+		id, _ := IdentityFromContext(ctx)
+		shared := make([]string, 0, len(subjects))
+
+		for _, subject := range subjects {
+			shared = append(shared, subpub.AccountSubject(id.account(), subject))
 		}
-		s.log.Error(fmt.Sprintf("error of the %s: %s", op, subErr))
+		subjects = shared
+	}
+
+	return s.rates.allow(ctx, subjects...)
+}
+
+// ActiveSubscriptions returns the count of the active remote subscriptions.
+func (s *SubPubServer) ActiveSubscriptions() int {
+	return s.subs.Len()
+}
+
+// Health returns the standard health checking server of the SubPubServer.
+func (s *SubPubServer) Health() healthpb.HealthServer {
+	return s.health
+}
 
-		return status.Error(code, subErr.Error())
+// Subscribe defines the logic of the handling the subscribe requests.
+// The subscription lives as long as the stream's context: it's unsubscribed right after the client's disconnecting.
+// The stream without the messages during the heartbeat's interval gets the heartbeat event.
+func (s *SubPubServer) Subscribe(request *sprpc.SubscribeRequest, stream grpc.ServerStreamingServer[sprpc.Event]) error {
+	const op = "spserv.Subscribe"
+
+	ctx := stream.Context()
+	if err := s.auth.authorize(ctx, rightSubscribe, request.Key); err != nil {
+		return err
 	}
 
-	for msg := range msgCh {
-		if err := stream.Send(&sprpc.Event{Data: msg}); err != nil {
-			sub.Unsubscribe()
-			close(s.subMsgCh.Delete(curID))
+	sp, err := s.subPub(ctx)
+	if err != nil {
+		return err
+	}
 
-			sendErr := fmt.Errorf("%w: %s", ErrSendingMsg, err)
-			s.log.Error(fmt.Sprintf("error of the %s: %s", op, sendErr))
+	msgCh := make(chan string)
+	remote := newRemoteSub(ctx, s.lastSubID.Add(1), request.Key, s.sharedSubject(ctx, request.Key))
+	log := requestLog(s.log, ctx).With(slog.String("subject", request.Key), slog.Int64("subscription_id", remote.id))
 
-			return status.Error(codes.Aborted, sendErr.Error())
+	sub, err := sp.Subscribe(request.Key, func(msg interface{}) {
+		data, ok := msg.(string)
+		if !ok {
+			log.Warn("the message that isn't the string was skipped", slog.String("type", fmt.Sprintf("%T", msg)))
+			return
 		}
+
+		select {
+		case msgCh <- data:
+		case <-remote.done:
+		}
+	}, subpub.WithCapacity(subCapacity, subpub.DropOldest))
+
+	if err != nil {
+		subErr := statusOf(err)
+		log.Error("the subscribing failed", slog.String("op", op), slog.Any("error", subErr))
+
+		return subErr
 	}
 
+	subID := remote.id
+	remote.sub = sub
+
+	s.subs.Add(subID, remote)
+	defer s.subs.Delete(subID)
+	defer remote.stop()
+
 	if s.flagDone.Load() {
-		sub.Unsubscribe()
 		return status.Error(codes.Aborted, ErrServiceCondition.Error())
 	}
-	s.curID.Add(-1)
 
-	return nil
+	log.Info("the subscription was started")
+
+	first := &sprpc.Event{Type: sprpc.EventType_EVENT_TYPE_SUBSCRIBED, SubscriptionId: subID}
+	if s.heartbeat > 0 {
+		first.HeartbeatInterval = durationpb.New(s.heartbeat)
+	}
+
+	if err := stream.Send(first); err != nil {
+		sendErr := fmt.Errorf("%w: %s", ErrSendingMsg, err)
+		log.Error("the subscription's sending failed", slog.String("op", op), slog.Any("error", sendErr))
+
+		return status.Error(codes.Aborted, sendErr.Error())
+	}
+
+	// the heartbeats are sent by the same goroutine as the messages, so they never reorder them
+	var heartbeat *time.Ticker
+	var heartbeats <-chan time.Time
+
+	if s.heartbeat > 0 {
+		heartbeat = time.NewTicker(s.heartbeat)
+		defer heartbeat.Stop()
+		heartbeats = heartbeat.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("the subscription was cancelled by the client")
+			return nil
+
+		case <-remote.done:
+			if s.flagDone.Load() {
+				return status.Error(codes.Aborted, ErrServiceCondition.Error())
+			} else if remote.kicked.Load() {
+				return status.Error(codes.Aborted, ErrSubKicked.Error())
+			}
+			log.Info("the subscription was unsubscribed")
+
+			return nil
+
+		case msg := <-msgCh:
+			if err := stream.Send(&sprpc.Event{Type: sprpc.EventType_EVENT_TYPE_DATA, Data: msg, SubscriptionId: subID}); err != nil {
+				sendErr := fmt.Errorf("%w: %s", ErrSendingMsg, err)
+				log.Error("the subscription's sending failed", slog.String("op", op), slog.Any("error", sendErr))
+
+				return status.Error(codes.Aborted, sendErr.Error())
+			}
+			remote.delivered.Add(1)
+
+			if heartbeat != nil {
+				heartbeat.Reset(s.heartbeat)
+			}
+
+		case <-heartbeats:
+			if err := stream.Send(&sprpc.Event{Type: sprpc.EventType_EVENT_TYPE_HEARTBEAT, SubscriptionId: subID}); err != nil {
+				sendErr := fmt.Errorf("%w: %s", ErrSendingMsg, err)
+				log.Error("the subscription's heartbeat failed", slog.String("op", op), slog.Any("error", sendErr))
+
+				return status.Error(codes.Aborted, sendErr.Error())
+			}
+		}
+	}
 }
 
 // Publish defines the logic of the handling the publish requests.
-func (s *SubPubServer) Publish(_ context.Context, request *sprpc.PublishRequest) (*emptypb.Empty, error) {
+// The request with the deliver_at is scheduled instead of the immediate publishing.
+func (s *SubPubServer) Publish(ctx context.Context, request *sprpc.PublishRequest) (*sprpc.PublishResponse, error) {
 	const op = "spserv.Publish"
 
-	if err := s.serv.Publish(request.Key, request.Data); err != nil {
-		var code codes.Code
-		var pubErr error
+	if err := s.auth.authorize(ctx, rightPublish, request.Key); err != nil {
+		return nil, err
+	}
+
+	sp, err := s.subPub(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	dup := false
+	opts := append(publishOpts(request), subpub.WithDuplicate(&dup))
+	scheduleID := ""
+	log := requestLog(s.log, ctx).With(slog.String("subject", request.Key))
+
+	if err = s.allowRate(ctx, request.Key); err == nil {
+		if request.DeliverAt != nil {
+			scheduleID, err = sp.PublishAt(request.Key, request.Data, request.DeliverAt.AsTime(), opts...)
+		} else {
+			err = sp.Publish(request.Key, request.Data, opts...)
+		}
+	}
+
+	if err == nil && dup {
+		log.Info("the duplicate of the message was skipped", slog.String("msg_id", request.MsgId))
+		return &sprpc.PublishResponse{Duplicate: true}, nil
+	} else if err != nil {
+		pubErr := statusOf(err)
+		log.Error("the publishing failed", slog.String("op", op), slog.Any("error", pubErr))
+
+		return nil, pubErr
+	}
+
+	if scheduleID != "" {
+		log.Info("the message was scheduled", slog.String("schedule_id", scheduleID))
+	}
+
+	return &sprpc.PublishResponse{ScheduleId: scheduleID}, nil
+}
+
+// PublishTx defines the logic of the handling the transactional publish requests.
+func (s *SubPubServer) PublishTx(ctx context.Context, request *sprpc.PublishTxRequest) (*sprpc.PublishResponse, error) {
+	const op = "spserv.PublishTx"
+
+	msgs := make([]subpub.SubjectMessage, 0, len(request.Messages))
+	subjects := make([]string, 0, len(request.Messages))
+	log := requestLog(s.log, ctx)
+	for _, msg := range request.Messages {
+		if msg.DeliverAt != nil {
+			err := fmt.Errorf("%w: the deliver_at of the transaction's message must be empty", ErrDataRequest)
+			log.Error("the transaction's publishing failed", slog.String("op", op), slog.String("subject", msg.Key), slog.Any("error", err))
+
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
 
+		if err := s.auth.authorize(ctx, rightPublish, msg.Key); err != nil {
+			return nil, err
+		}
+
+		msgs = append(msgs, subpub.SubjectMessage{
+			Subject: msg.Key,
+			Msg:     msg.Data,
+			Opts:    publishOpts(msg),
+		})
+		subjects = append(subjects, msg.Key)
+	}
+
+	sp, err := s.subPub(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scheduleID := ""
+
+	if err = s.allowRate(ctx, subjects...); err == nil {
+		if request.DeliverAt != nil {
+			scheduleID, err = sp.PublishTxAt(ctx, msgs, request.DeliverAt.AsTime())
+		} else {
+			err = sp.PublishTx(ctx, msgs)
+		}
+	}
+
+	if err != nil {
+		pubErr := statusOf(err)
+		log.Error("the transaction's publishing failed", slog.String("op", op), slog.Any("error", pubErr))
+
+		return nil, pubErr
+	}
+
+	if scheduleID != "" {
+		log.Info("the transaction was scheduled", slog.String("schedule_id", scheduleID), slog.Int("messages", len(msgs)))
+	}
+
+	return &sprpc.PublishResponse{ScheduleId: scheduleID}, nil
+}
+
+// publishOpts returns the publishing's options of the request.
+func publishOpts(request *sprpc.PublishRequest) []subpub.PublishOpt {
+	opts := make([]subpub.PublishOpt, 0, 3)
+	if request.Ttl != nil {
+		opts = append(opts, subpub.WithTTL(request.Ttl.AsDuration()))
+	}
+	if request.Priority != 0 {
+		opts = append(opts, subpub.WithPriority(int(request.Priority)))
+	}
+	if request.MsgId != "" {
+		opts = append(opts, subpub.WithMsgID(request.MsgId))
+	}
+	return opts
+}
+
+// Unsubscribe defines the logic of the handling the unsubscribe requests: the subscription's stream is finished.
+func (s *SubPubServer) Unsubscribe(ctx context.Context, request *sprpc.SubscriptionRequest) (*emptypb.Empty, error) {
+	const op = "spserv.Unsubscribe"
+
+	sub, ok := s.subs.Get(request.Id)
+	if !ok {
+		err := fmt.Errorf("%w: the subscription %d doesn't exist", ErrSubNotFound, request.Id)
+		requestLog(s.log, ctx).Error("the unsubscribing failed",
+			slog.String("op", op), slog.Int64("subscription_id", request.Id), slog.Any("error", err))
+
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	if err := s.auth.authorizeOwner(ctx, sub); err != nil {
+		return nil, err
+	}
+	s.subs.Delete(request.Id)
+	sub.stop()
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListScheduled defines the logic of the handling the requests of the scheduled messages' list.
+// Only the messages of the subjects that the client may publish into are listed.
+func (s *SubPubServer) ListScheduled(ctx context.Context, _ *emptypb.Empty) (*sprpc.ScheduledList, error) {
+	sp, err := s.subPub(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled := sp.Scheduled()
+	list := &sprpc.ScheduledList{
+		Messages: make([]*sprpc.ScheduledMessage, 0, len(scheduled)),
+	}
+
+	for _, msg := range scheduled {
+		if !s.auth.permits(ctx, rightPublish, msg.Subject) {
+			continue
+		}
+		data, _ := msg.Msg.(string)
+
+		list.Messages = append(list.Messages, &sprpc.ScheduledMessage{
+			Id:        msg.ID,
+			Key:       msg.Subject,
+			Data:      data,
+			DeliverAt: timestamppb.New(msg.DeliverAt),
+			Ttl:       durationpb.New(msg.TTL),
+			Priority:  int32(msg.Priority),
+			TxId:      msg.TxID,
+		})
+	}
+
+	return list, nil
+}
+
+// CancelScheduled defines the logic of the handling the scheduled messages' cancel requests.
+func (s *SubPubServer) CancelScheduled(ctx context.Context, request *sprpc.ScheduledRequest) (*emptypb.Empty, error) {
+	const op = "spserv.CancelScheduled"
+
+	sp, err := s.subPub(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.auth != nil {
+		for _, msg := range sp.Scheduled() {
+			if msg.ID != request.Id && msg.TxID != request.Id {
+				continue
+			}
+
+			if err := s.auth.authorize(ctx, rightPublish, msg.Subject); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := sp.CancelScheduled(request.Id); err != nil {
+		cancelErr := statusOf(err)
 		if errors.Is(err, subpub.ErrInputData) {
-			code = codes.InvalidArgument
-			pubErr = fmt.Errorf("%w: %s", ErrDataRequest, err)
-		} else if errors.Is(err, subpub.ErrSystemCondition) {
-			code = codes.Unavailable
-			pubErr = fmt.Errorf("%w: %s", ErrServiceCondition, err)
+			cancelErr = status.Error(codes.NotFound, fmt.Errorf("%w: %s", ErrScheduledNotFound, err).Error())
 		}
-		s.log.Error(fmt.Sprintf("error of the %s: %s", op, pubErr))
+		requestLog(s.log, ctx).Error("the scheduled message's canceling failed",
+			slog.String("op", op), slog.String("schedule_id", request.Id), slog.Any("error", cancelErr))
 
-		return nil, status.Error(code, pubErr.Error())
+		return nil, cancelErr
 	}
 
+	requestLog(s.log, ctx).Info("the scheduled message was canceled", slog.String("schedule_id", request.Id))
+
 	return &emptypb.Empty{}, nil
 }
 
+// drain stops accepting the new requests and waits for the delivery of the published messages
+// untill the ctx is done. Then all the subscriptions are stopped.
+func (s *SubPubServer) drain(ctx context.Context) error {
+	s.health.Shutdown()
+	s.flagDone.Store(true)
+
+	err := s.serv.Close(ctx)
+
+	s.subs.Range(func(sub *remoteSub) {
+		sub.stop()
+	})
+
+	s.conns.Range(func(conn *connection) {
+		conn.close()
+	})
+
+	return err
+}
+
 // Close releases the resources of the SubPubServer.
+// The health status is switched to NOT_SERVING first, so the balancers stop routing to the server.
 func (s *SubPubServer) Close() {
+	s.health.Shutdown()
 	s.flagDone.Store(true)
 
-	s.subMsgCh.Range(func(ch chan string) {
-		close(ch)
+	s.subs.Range(func(sub *remoteSub) {
+		sub.stop()
+	})
+
+	s.conns.Range(func(conn *connection) {
+		conn.close()
 	})
 
 	s.serv.Close(context.Background())
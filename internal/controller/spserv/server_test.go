@@ -0,0 +1,282 @@
+package spserv
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// countingSubPub defines the SubPub that counts its active subscriptions.
+type countingSubPub struct {
+	subpub.SubPub
+	active atomic.Int64
+}
+
+func (c *countingSubPub) Subscribe(subject string, cb subpub.MessageHandler, opts ...subpub.SubscribeOpt) (subpub.Subscription, error) {
+	sub, err := c.SubPub.Subscribe(subject, cb, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.active.Add(1)
+
+	return &countingSub{Subscription: sub, count: &c.active}, nil
+}
+
+// countingSub defines the Subscription that decrements the count of the active subscriptions once.
+type countingSub struct {
+	subpub.Subscription
+	count *atomic.Int64
+	once  sync.Once
+}
+
+func (c *countingSub) Unsubscribe() {
+	c.once.Do(func() {
+		c.count.Add(-1)
+	})
+	c.Subscription.Unsubscribe()
+}
+
+// startTestServer starts the SubPubServer on the in-memory listener and returns its client.
+func startTestServer(t *testing.T, serv subpub.SubPub, opts ...ServerOpt) (*SubPubServer, sprpc.PubSubClient) {
+	server, conn := startTestService(t, serv, opts...)
+	return server, sprpc.NewPubSubClient(conn)
+}
+
+// startTestService starts the SubPubServer with its Admin on the in-memory listener and returns the client's connection.
+func startTestService(t *testing.T, serv subpub.SubPub, opts ...ServerOpt) (*SubPubServer, *grpc.ClientConn) {
+	lis := bufconn.Listen(1024 * 1024)
+
+	server := NewSubPubServer(slog.New(slog.NewTextHandler(io.Discard, nil)), serv, opts...)
+	grpcServ := grpc.NewServer()
+	sprpc.RegisterPubSubServer(grpcServ, server)
+	sprpc.RegisterAdminServer(grpcServ, server.Admin())
+	healthpb.RegisterHealthServer(grpcServ, server.Health())
+
+	go grpcServ.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err, "expected no error after the client's creating")
+
+	t.Cleanup(func() {
+		conn.Close()
+		server.Close()
+		grpcServ.Stop()
+	})
+
+	return server, conn
+}
+
+func TestSubscribeCleanup(t *testing.T) {
+	const streams = 20
+
+	serv := &countingSubPub{SubPub: subpub.NewSubPub()}
+	server, client := startTestServer(t, serv)
+
+	// the connection's goroutines are started by the first call.
+	client.Publish(context.Background(), &sprpc.PublishRequest{Key: "test-channel-warmup", Data: "test-message"})
+	time.Sleep(time.Millisecond * 100)
+	baseline := runtime.NumGoroutine()
+
+	cancels := make([]context.CancelFunc, 0, streams)
+	for i := 0; i != streams; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancels = append(cancels, cancel)
+
+		stream, err := client.Subscribe(ctx, &sprpc.SubscribeRequest{Key: "test-channel-quiet"})
+		require.NoError(t, err, "expected no error after the subscribing")
+
+		first, err := stream.Recv()
+		require.NoError(t, err, "expected the subscription's id to be received")
+		require.Equal(t, sprpc.EventType_EVENT_TYPE_SUBSCRIBED, first.Type, "expected the subscription's confirmation as the first event")
+	}
+
+	assert.Equal(t, streams, server.subs.Len(), "expected all the subscriptions to be registered")
+	assert.Equal(t, int64(streams), serv.active.Load(), "expected all the subscriptions to be active")
+
+	for _, cancel := range cancels {
+		cancel()
+	}
+
+	assert.Eventually(t, func() bool {
+		return server.subs.Len() == 0 && serv.active.Load() == 0
+	}, time.Second*5, time.Millisecond*10, "expected the abandoned subscriptions to be removed without the publishing")
+
+	// the polling is done in the test's goroutine, because the assert.Eventually starts its own ones.
+	deadline := time.Now().Add(time.Second * 5)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond * 10)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline, "expected the goroutines' count to return to the baseline")
+}
+
+func TestSubscribeHeartbeat(t *testing.T) {
+	const msgs = 100
+
+	_, client := startTestServer(t, subpub.NewSubPub(), WithHeartbeat(time.Millisecond*20))
+
+	stream, err := client.Subscribe(context.Background(), &sprpc.SubscribeRequest{Key: "test-channel"})
+	require.NoError(t, err, "expected no error after the subscribing")
+
+	first, err := stream.Recv()
+	require.NoError(t, err, "expected the subscription's id to be received")
+	assert.Equal(t, sprpc.EventType_EVENT_TYPE_SUBSCRIBED, first.Type, "expected the subscription's confirmation as the first event")
+	assert.Equal(t, time.Millisecond*20, first.HeartbeatInterval.AsDuration(), "expected the heartbeat's interval in the first event")
+
+	event, err := stream.Recv()
+	require.NoError(t, err, "expected no error after the receiving")
+	assert.Equal(t, sprpc.EventType_EVENT_TYPE_HEARTBEAT, event.Type, "expected the heartbeat of the quiet subject")
+	assert.Equal(t, first.SubscriptionId, event.SubscriptionId, "expected the heartbeat of the subscription")
+
+	go func() {
+		for i := 0; i != msgs; i++ {
+			client.Publish(context.Background(), &sprpc.PublishRequest{Key: "test-channel", Data: strconv.Itoa(i)})
+			if i%10 == 0 {
+				time.Sleep(time.Millisecond * 30)
+			}
+		}
+	}()
+
+	for i := 0; i != msgs; {
+		event, err := stream.Recv()
+		require.NoError(t, err, "expected no error after the receiving")
+
+		if event.Type == sprpc.EventType_EVENT_TYPE_HEARTBEAT {
+			continue
+		}
+		require.Equal(t, sprpc.EventType_EVENT_TYPE_DATA, event.Type, "expected the type of the message's event")
+		require.Equal(t, strconv.Itoa(i), event.Data, "expected the heartbeats not to reorder the messages")
+		i++
+	}
+}
+
+func TestDrainServer(t *testing.T) {
+	testChannel := "test-channel"
+
+	_, conn := startTestService(t, subpub.NewSubPub())
+	client, admin := sprpc.NewPubSubClient(conn), sprpc.NewAdminClient(conn)
+
+	stream, err := client.Subscribe(context.Background(), &sprpc.SubscribeRequest{Key: testChannel})
+	require.NoError(t, err, "expected no error after the subscribing")
+
+	_, err = stream.Recv()
+	require.NoError(t, err, "expected the subscription's id to be received")
+
+	_, err = client.Publish(context.Background(), &sprpc.PublishRequest{Key: testChannel, Data: "test-message"})
+	require.NoError(t, err, "expected no error after the publishing")
+
+	_, err = admin.DrainServer(context.Background(), &sprpc.DrainRequest{Timeout: durationpb.New(time.Second * 5)})
+	assert.NoError(t, err, "expected no error after the draining")
+
+	ev, err := stream.Recv()
+	if assert.NoError(t, err, "expected the published message to be delivered before the draining's end") {
+		assert.Equal(t, "test-message", ev.Data, "expected the published data")
+	}
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Aborted, status.Code(err), "expected the stream to be aborted after the draining")
+
+	_, err = client.Publish(context.Background(), &sprpc.PublishRequest{Key: testChannel, Data: "test-message"})
+	assert.Equal(t, codes.Unavailable, status.Code(err), "expected the publishing to be rejected after the draining")
+}
+
+func TestPauseSubscription(t *testing.T) {
+	testChannel := "test-channel"
+
+	_, conn := startTestService(t, subpub.NewSubPub())
+	client, admin := sprpc.NewPubSubClient(conn), sprpc.NewAdminClient(conn)
+
+	stream, err := client.Subscribe(context.Background(), &sprpc.SubscribeRequest{Key: testChannel})
+	require.NoError(t, err, "expected no error after the subscribing")
+
+	first, err := stream.Recv()
+	require.NoError(t, err, "expected the subscription's id to be received")
+
+	_, err = admin.PauseSubscription(context.Background(), &sprpc.SubscriptionRequest{Id: first.SubscriptionId})
+	require.NoError(t, err, "expected no error after the pausing")
+
+	_, err = client.Publish(context.Background(), &sprpc.PublishRequest{Key: testChannel, Data: "test-message"})
+	require.NoError(t, err, "expected no error after the publishing")
+
+	list, err := admin.ListSubscriptions(context.Background(), &emptypb.Empty{})
+	if assert.NoError(t, err, "expected no error after the listing") && assert.Len(t, list.Subscriptions, 1) {
+		assert.True(t, list.Subscriptions[0].Paused, "expected the subscription to be listed as paused")
+	}
+
+	_, err = admin.ResumeSubscription(context.Background(), &sprpc.SubscriptionRequest{Id: first.SubscriptionId})
+	require.NoError(t, err, "expected no error after the resuming")
+
+	ev, err := stream.Recv()
+	if assert.NoError(t, err, "expected the buffered message to be delivered after the resuming") {
+		assert.Equal(t, "test-message", ev.Data, "expected the published data")
+	}
+}
+
+func TestHealth(t *testing.T) {
+	server, conn := startTestService(t, subpub.NewSubPub())
+	health := healthpb.NewHealthClient(conn)
+
+	resp, err := health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err, "expected no error after the health checking")
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status, "expected the started server to be serving")
+
+	server.Close()
+
+	resp, err = health.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err, "expected no error after the health checking")
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status, "expected the closed server to be not serving")
+}
+
+func TestReloadConfig(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	sp := subpub.NewSubPub()
+	defer sp.Close(context.Background())
+
+	_, err := NewSubPubServer(log, sp).Admin().ReloadConfig(context.Background(), &emptypb.Empty{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err), "expected the error without the reload's func")
+
+	reloadErr := errors.New("the config is broken")
+	reports := []ReloadReport{{Applied: []string{"log_level"}, Rejected: []string{"socket"}}}
+
+	server := NewSubPubServer(log, sp, WithReload(func() (ReloadReport, error) {
+		if len(reports) == 0 {
+			return ReloadReport{}, reloadErr
+		}
+		report := reports[0]
+		reports = reports[1:]
+
+		return report, nil
+	}))
+
+	resp, err := server.Admin().ReloadConfig(context.Background(), &emptypb.Empty{})
+	require.NoError(t, err, "expected no error after the reloading")
+	assert.Equal(t, []string{"log_level"}, resp.Applied, "expected the applied settings")
+	assert.Equal(t, []string{"socket"}, resp.Rejected, "expected the rejected settings")
+
+	_, err = server.Admin().ReloadConfig(context.Background(), &emptypb.Empty{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "expected the error of the failed reloading")
+}
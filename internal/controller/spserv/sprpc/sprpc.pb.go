@@ -9,7 +9,9 @@ package sprpc
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,6 +24,64 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Тип события потока Subscribe
+type EventType int32
+
+const (
+	// Тип не задан (не отправляется сервером)
+	EventType_EVENT_TYPE_UNSPECIFIED EventType = 0
+	// Сигнал жизни сервера, отправляемый при отсутствии сообщений в течение интервала heartbeat_interval:
+	// клиент, не получивший ни одного события за несколько интервалов, должен переподключиться
+	EventType_EVENT_TYPE_HEARTBEAT EventType = 1
+	// Первое событие потока: подтверждение подписки с её ID и интервалом сигналов жизни
+	EventType_EVENT_TYPE_SUBSCRIBED EventType = 2
+	// Сообщение канала
+	EventType_EVENT_TYPE_DATA EventType = 3
+)
+
+// Enum value maps for EventType.
+var (
+	EventType_name = map[int32]string{
+		0: "EVENT_TYPE_UNSPECIFIED",
+		1: "EVENT_TYPE_HEARTBEAT",
+		2: "EVENT_TYPE_SUBSCRIBED",
+		3: "EVENT_TYPE_DATA",
+	}
+	EventType_value = map[string]int32{
+		"EVENT_TYPE_UNSPECIFIED": 0,
+		"EVENT_TYPE_HEARTBEAT":   1,
+		"EVENT_TYPE_SUBSCRIBED":  2,
+		"EVENT_TYPE_DATA":        3,
+	}
+)
+
+func (x EventType) Enum() *EventType {
+	p := new(EventType)
+	*p = x
+	return p
+}
+
+func (x EventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_sprpc_proto_enumTypes[0].Descriptor()
+}
+
+func (EventType) Type() protoreflect.EnumType {
+	return &file_sprpc_proto_enumTypes[0]
+}
+
+func (x EventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventType.Descriptor instead.
+func (EventType) EnumDescriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{0}
+}
+
 type SubscribeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
@@ -67,9 +127,17 @@ func (x *SubscribeRequest) GetKey() string {
 }
 
 type PublishRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Data          string                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Data  string                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// Время жизни сообщения: по его истечении недоставленное сообщение отбрасывается
+	Ttl *durationpb.Duration `protobuf:"bytes,3,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	// Время отложенной публикации: если не задано, сообщение публикуется сразу
+	DeliverAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=deliver_at,json=deliverAt,proto3" json:"deliver_at,omitempty"`
+	// Приоритет сообщения: сообщения с большим приоритетом обгоняют ожидающие в очереди подписчика
+	Priority int32 `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	// ID сообщения, задаваемый издателем: повторы с тем же ID в окне дедупликации не доставляются
+	MsgId         string `protobuf:"bytes,6,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -118,27 +186,58 @@ func (x *PublishRequest) GetData() string {
 	return ""
 }
 
-type Event struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Data          string                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+func (x *PublishRequest) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
+
+func (x *PublishRequest) GetDeliverAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeliverAt
+	}
+	return nil
+}
+
+func (x *PublishRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *PublishRequest) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+type PublishTxRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Сообщения транзакции (поле deliver_at отдельных сообщений не используется)
+	Messages []*PublishRequest `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	// Время отложенной публикации всей транзакции: если не задано, транзакция публикуется сразу
+	DeliverAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=deliver_at,json=deliverAt,proto3" json:"deliver_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Event) Reset() {
-	*x = Event{}
+func (x *PublishTxRequest) Reset() {
+	*x = PublishTxRequest{}
 	mi := &file_sprpc_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Event) String() string {
+func (x *PublishTxRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Event) ProtoMessage() {}
+func (*PublishTxRequest) ProtoMessage() {}
 
-func (x *Event) ProtoReflect() protoreflect.Message {
+func (x *PublishTxRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_sprpc_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -150,82 +249,1640 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Event.ProtoReflect.Descriptor instead.
-func (*Event) Descriptor() ([]byte, []int) {
+// Deprecated: Use PublishTxRequest.ProtoReflect.Descriptor instead.
+func (*PublishTxRequest) Descriptor() ([]byte, []int) {
 	return file_sprpc_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *Event) GetData() string {
+func (x *PublishTxRequest) GetMessages() []*PublishRequest {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *PublishTxRequest) GetDeliverAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeliverAt
+	}
+	return nil
+}
+
+type PublishResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID отложенного сообщения (пустой при немедленной публикации)
+	ScheduleId string `protobuf:"bytes,1,opt,name=schedule_id,json=scheduleId,proto3" json:"schedule_id,omitempty"`
+	// Признак того, что сообщение является повтором и не было доставлено повторно
+	Duplicate     bool `protobuf:"varint,2,opt,name=duplicate,proto3" json:"duplicate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PublishResponse) Reset() {
+	*x = PublishResponse{}
+	mi := &file_sprpc_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PublishResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PublishResponse) ProtoMessage() {}
+
+func (x *PublishResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PublishResponse.ProtoReflect.Descriptor instead.
+func (*PublishResponse) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *PublishResponse) GetScheduleId() string {
+	if x != nil {
+		return x.ScheduleId
+	}
+	return ""
+}
+
+func (x *PublishResponse) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+type ScheduledRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduledRequest) Reset() {
+	*x = ScheduledRequest{}
+	mi := &file_sprpc_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduledRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledRequest) ProtoMessage() {}
+
+func (x *ScheduledRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledRequest.ProtoReflect.Descriptor instead.
+func (*ScheduledRequest) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ScheduledRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ScheduledMessage struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Key       string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Data      string                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	DeliverAt *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=deliver_at,json=deliverAt,proto3" json:"deliver_at,omitempty"`
+	Ttl       *durationpb.Duration   `protobuf:"bytes,5,opt,name=ttl,proto3" json:"ttl,omitempty"`
+	Priority  int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	// ID транзакции, в составе которой публикуется сообщение (пустой вне транзакции)
+	TxId          string `protobuf:"bytes,7,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduledMessage) Reset() {
+	*x = ScheduledMessage{}
+	mi := &file_sprpc_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduledMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledMessage) ProtoMessage() {}
+
+func (x *ScheduledMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledMessage.ProtoReflect.Descriptor instead.
+func (*ScheduledMessage) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ScheduledMessage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ScheduledMessage) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ScheduledMessage) GetData() string {
 	if x != nil {
 		return x.Data
 	}
 	return ""
 }
 
-var File_sprpc_proto protoreflect.FileDescriptor
+func (x *ScheduledMessage) GetDeliverAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeliverAt
+	}
+	return nil
+}
 
-const file_sprpc_proto_rawDesc = "" +
-	"\n" +
-	"\vsprpc.proto\x12\x05sprpc\x1a\x1bgoogle/protobuf/empty.proto\"$\n" +
-	"\x10SubscribeRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\"6\n" +
-	"\x0ePublishRequest\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
-	"\x04data\x18\x02 \x01(\tR\x04data\"\x1b\n" +
-	"\x05Event\x12\x12\n" +
-	"\x04data\x18\x01 \x01(\tR\x04data2|\n" +
-	"\x06PubSub\x126\n" +
-	"\tSubscribe\x12\x17.sprpc.SubscribeRequest\x1a\f.sprpc.Event\"\x000\x01\x12:\n" +
-	"\aPublish\x12\x15.sprpc.PublishRequest\x1a\x16.google.protobuf.Empty\"\x00B=Z;github.com/MaKcm14/vk-test/internal/controller/spserv/sprpcb\x06proto3"
+func (x *ScheduledMessage) GetTtl() *durationpb.Duration {
+	if x != nil {
+		return x.Ttl
+	}
+	return nil
+}
 
-var (
-	file_sprpc_proto_rawDescOnce sync.Once
-	file_sprpc_proto_rawDescData []byte
-)
+func (x *ScheduledMessage) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
 
-func file_sprpc_proto_rawDescGZIP() []byte {
-	file_sprpc_proto_rawDescOnce.Do(func() {
-		file_sprpc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sprpc_proto_rawDesc), len(file_sprpc_proto_rawDesc)))
-	})
-	return file_sprpc_proto_rawDescData
+func (x *ScheduledMessage) GetTxId() string {
+	if x != nil {
+		return x.TxId
+	}
+	return ""
 }
 
-var file_sprpc_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
-var file_sprpc_proto_goTypes = []any{
-	(*SubscribeRequest)(nil), // 0: sprpc.SubscribeRequest
-	(*PublishRequest)(nil),   // 1: sprpc.PublishRequest
-	(*Event)(nil),            // 2: sprpc.Event
-	(*emptypb.Empty)(nil),    // 3: google.protobuf.Empty
+type ScheduledList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*ScheduledMessage    `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
-var file_sprpc_proto_depIdxs = []int32{
-	0, // 0: sprpc.PubSub.Subscribe:input_type -> sprpc.SubscribeRequest
-	1, // 1: sprpc.PubSub.Publish:input_type -> sprpc.PublishRequest
-	2, // 2: sprpc.PubSub.Subscribe:output_type -> sprpc.Event
-	3, // 3: sprpc.PubSub.Publish:output_type -> google.protobuf.Empty
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (x *ScheduledList) Reset() {
+	*x = ScheduledList{}
+	mi := &file_sprpc_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func init() { file_sprpc_proto_init() }
-func file_sprpc_proto_init() {
-	if File_sprpc_proto != nil {
-		return
+func (x *ScheduledList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledList) ProtoMessage() {}
+
+func (x *ScheduledList) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledList.ProtoReflect.Descriptor instead.
+func (*ScheduledList) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ScheduledList) GetMessages() []*ScheduledMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type SubscriptionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscriptionRequest) Reset() {
+	*x = SubscriptionRequest{}
+	mi := &file_sprpc_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriptionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriptionRequest) ProtoMessage() {}
+
+func (x *SubscriptionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriptionRequest.ProtoReflect.Descriptor instead.
+func (*SubscriptionRequest) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *SubscriptionRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Data  string                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// ID подписки (передаётся во всех событиях потока)
+	SubscriptionId int64     `protobuf:"varint,2,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	Type           EventType `protobuf:"varint,3,opt,name=type,proto3,enum=sprpc.EventType" json:"type,omitempty"`
+	// Интервал сигналов жизни (передаётся в первом событии потока, отсутствует при их отключении)
+	HeartbeatInterval *durationpb.Duration `protobuf:"bytes,4,opt,name=heartbeat_interval,json=heartbeatInterval,proto3" json:"heartbeat_interval,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_sprpc_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Event) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+func (x *Event) GetSubscriptionId() int64 {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return 0
+}
+
+func (x *Event) GetType() EventType {
+	if x != nil {
+		return x.Type
+	}
+	return EventType_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *Event) GetHeartbeatInterval() *durationpb.Duration {
+	if x != nil {
+		return x.HeartbeatInterval
+	}
+	return nil
+}
+
+type SubscriptionInfo struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Key        string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	ClientAddr string                 `protobuf:"bytes,3,opt,name=client_addr,json=clientAddr,proto3" json:"client_addr,omitempty"`
+	StartedAt  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	// Число доставленных подписчику событий
+	Delivered uint64 `protobuf:"varint,5,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	Paused    bool   `protobuf:"varint,6,opt,name=paused,proto3" json:"paused,omitempty"`
+	// Идентичность клиента, подтверждённая при аутентификации (пустая для анонимного клиента)
+	Identity string `protobuf:"bytes,7,opt,name=identity,proto3" json:"identity,omitempty"`
+	// Аккаунт клиента, в пространстве имён которого создана подписка
+	Account       string `protobuf:"bytes,8,opt,name=account,proto3" json:"account,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscriptionInfo) Reset() {
+	*x = SubscriptionInfo{}
+	mi := &file_sprpc_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriptionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriptionInfo) ProtoMessage() {}
+
+func (x *SubscriptionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriptionInfo.ProtoReflect.Descriptor instead.
+func (*SubscriptionInfo) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SubscriptionInfo) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SubscriptionInfo) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SubscriptionInfo) GetClientAddr() string {
+	if x != nil {
+		return x.ClientAddr
+	}
+	return ""
+}
+
+func (x *SubscriptionInfo) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *SubscriptionInfo) GetDelivered() uint64 {
+	if x != nil {
+		return x.Delivered
+	}
+	return 0
+}
+
+func (x *SubscriptionInfo) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *SubscriptionInfo) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *SubscriptionInfo) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+type SubscriptionList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Subscriptions []*SubscriptionInfo    `protobuf:"bytes,1,rep,name=subscriptions,proto3" json:"subscriptions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscriptionList) Reset() {
+	*x = SubscriptionList{}
+	mi := &file_sprpc_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscriptionList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscriptionList) ProtoMessage() {}
+
+func (x *SubscriptionList) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscriptionList.ProtoReflect.Descriptor instead.
+func (*SubscriptionList) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SubscriptionList) GetSubscriptions() []*SubscriptionInfo {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return nil
+}
+
+// Кадр клиента в потоке Connect
+type ClientFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID операции, возвращаемый сервером в результате её выполнения
+	CorrelationId string `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	// Types that are valid to be assigned to Op:
+	//
+	//	*ClientFrame_Subscribe
+	//	*ClientFrame_Unsubscribe
+	//	*ClientFrame_Publish
+	//	*ClientFrame_Ack
+	//	*ClientFrame_Flow
+	Op            isClientFrame_Op `protobuf_oneof:"op"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClientFrame) Reset() {
+	*x = ClientFrame{}
+	mi := &file_sprpc_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientFrame) ProtoMessage() {}
+
+func (x *ClientFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientFrame.ProtoReflect.Descriptor instead.
+func (*ClientFrame) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ClientFrame) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *ClientFrame) GetOp() isClientFrame_Op {
+	if x != nil {
+		return x.Op
+	}
+	return nil
+}
+
+func (x *ClientFrame) GetSubscribe() *SubscribeRequest {
+	if x != nil {
+		if x, ok := x.Op.(*ClientFrame_Subscribe); ok {
+			return x.Subscribe
+		}
+	}
+	return nil
+}
+
+func (x *ClientFrame) GetUnsubscribe() *UnsubscribeOp {
+	if x != nil {
+		if x, ok := x.Op.(*ClientFrame_Unsubscribe); ok {
+			return x.Unsubscribe
+		}
+	}
+	return nil
+}
+
+func (x *ClientFrame) GetPublish() *PublishRequest {
+	if x != nil {
+		if x, ok := x.Op.(*ClientFrame_Publish); ok {
+			return x.Publish
+		}
+	}
+	return nil
+}
+
+func (x *ClientFrame) GetAck() *AckOp {
+	if x != nil {
+		if x, ok := x.Op.(*ClientFrame_Ack); ok {
+			return x.Ack
+		}
+	}
+	return nil
+}
+
+func (x *ClientFrame) GetFlow() *FlowOp {
+	if x != nil {
+		if x, ok := x.Op.(*ClientFrame_Flow); ok {
+			return x.Flow
+		}
+	}
+	return nil
+}
+
+type isClientFrame_Op interface {
+	isClientFrame_Op()
+}
+
+type ClientFrame_Subscribe struct {
+	Subscribe *SubscribeRequest `protobuf:"bytes,2,opt,name=subscribe,proto3,oneof"`
+}
+
+type ClientFrame_Unsubscribe struct {
+	Unsubscribe *UnsubscribeOp `protobuf:"bytes,3,opt,name=unsubscribe,proto3,oneof"`
+}
+
+type ClientFrame_Publish struct {
+	Publish *PublishRequest `protobuf:"bytes,4,opt,name=publish,proto3,oneof"`
+}
+
+type ClientFrame_Ack struct {
+	Ack *AckOp `protobuf:"bytes,5,opt,name=ack,proto3,oneof"`
+}
+
+type ClientFrame_Flow struct {
+	Flow *FlowOp `protobuf:"bytes,6,opt,name=flow,proto3,oneof"`
+}
+
+func (*ClientFrame_Subscribe) isClientFrame_Op() {}
+
+func (*ClientFrame_Unsubscribe) isClientFrame_Op() {}
+
+func (*ClientFrame_Publish) isClientFrame_Op() {}
+
+func (*ClientFrame_Ack) isClientFrame_Op() {}
+
+func (*ClientFrame_Flow) isClientFrame_Op() {}
+
+type UnsubscribeOp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID подписки, полученный в результате операции subscribe
+	SubscriptionId int64 `protobuf:"varint,1,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UnsubscribeOp) Reset() {
+	*x = UnsubscribeOp{}
+	mi := &file_sprpc_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnsubscribeOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnsubscribeOp) ProtoMessage() {}
+
+func (x *UnsubscribeOp) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnsubscribeOp.ProtoReflect.Descriptor instead.
+func (*UnsubscribeOp) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UnsubscribeOp) GetSubscriptionId() int64 {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return 0
+}
+
+type AckOp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID доставки подтверждаемого события
+	DeliveryId    uint64 `protobuf:"varint,1,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AckOp) Reset() {
+	*x = AckOp{}
+	mi := &file_sprpc_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AckOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AckOp) ProtoMessage() {}
+
+func (x *AckOp) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AckOp.ProtoReflect.Descriptor instead.
+func (*AckOp) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AckOp) GetDeliveryId() uint64 {
+	if x != nil {
+		return x.DeliveryId
+	}
+	return 0
+}
+
+type FlowOp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Максимальное число неподтверждённых событий в потоке
+	Window        uint32 `protobuf:"varint,1,opt,name=window,proto3" json:"window,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FlowOp) Reset() {
+	*x = FlowOp{}
+	mi := &file_sprpc_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FlowOp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FlowOp) ProtoMessage() {}
+
+func (x *FlowOp) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FlowOp.ProtoReflect.Descriptor instead.
+func (*FlowOp) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *FlowOp) GetWindow() uint32 {
+	if x != nil {
+		return x.Window
+	}
+	return 0
+}
+
+// Кадр сервера в потоке Connect
+type ServerFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Kind:
+	//
+	//	*ServerFrame_Result
+	//	*ServerFrame_Event
+	Kind          isServerFrame_Kind `protobuf_oneof:"kind"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerFrame) Reset() {
+	*x = ServerFrame{}
+	mi := &file_sprpc_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerFrame) ProtoMessage() {}
+
+func (x *ServerFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerFrame.ProtoReflect.Descriptor instead.
+func (*ServerFrame) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ServerFrame) GetKind() isServerFrame_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return nil
+}
+
+func (x *ServerFrame) GetResult() *OpResult {
+	if x != nil {
+		if x, ok := x.Kind.(*ServerFrame_Result); ok {
+			return x.Result
+		}
+	}
+	return nil
+}
+
+func (x *ServerFrame) GetEvent() *Delivery {
+	if x != nil {
+		if x, ok := x.Kind.(*ServerFrame_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+type isServerFrame_Kind interface {
+	isServerFrame_Kind()
+}
+
+type ServerFrame_Result struct {
+	Result *OpResult `protobuf:"bytes,1,opt,name=result,proto3,oneof"`
+}
+
+type ServerFrame_Event struct {
+	Event *Delivery `protobuf:"bytes,2,opt,name=event,proto3,oneof"`
+}
+
+func (*ServerFrame_Result) isServerFrame_Kind() {}
+
+func (*ServerFrame_Event) isServerFrame_Kind() {}
+
+type OpResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID операции из кадра клиента
+	CorrelationId string `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	// Код ошибки gRPC (0 при успешном выполнении) и её описание
+	Code  int32  `protobuf:"varint,2,opt,name=code,proto3" json:"code,omitempty"`
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	// ID подписки (для операции subscribe)
+	SubscriptionId int64 `protobuf:"varint,4,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	// Результат публикации (для операции publish)
+	Publish *PublishResponse `protobuf:"bytes,5,opt,name=publish,proto3" json:"publish,omitempty"`
+	// Время, через которое операцию, отклонённую из-за превышения ограничения скорости, можно повторить
+	RetryAfter    *durationpb.Duration `protobuf:"bytes,6,opt,name=retry_after,json=retryAfter,proto3" json:"retry_after,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OpResult) Reset() {
+	*x = OpResult{}
+	mi := &file_sprpc_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OpResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OpResult) ProtoMessage() {}
+
+func (x *OpResult) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OpResult.ProtoReflect.Descriptor instead.
+func (*OpResult) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *OpResult) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *OpResult) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *OpResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *OpResult) GetSubscriptionId() int64 {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return 0
+}
+
+func (x *OpResult) GetPublish() *PublishResponse {
+	if x != nil {
+		return x.Publish
+	}
+	return nil
+}
+
+func (x *OpResult) GetRetryAfter() *durationpb.Duration {
+	if x != nil {
+		return x.RetryAfter
+	}
+	return nil
+}
+
+type Delivery struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// ID доставки, которым событие подтверждается через операцию ack
+	DeliveryId     uint64 `protobuf:"varint,1,opt,name=delivery_id,json=deliveryId,proto3" json:"delivery_id,omitempty"`
+	SubscriptionId int64  `protobuf:"varint,2,opt,name=subscription_id,json=subscriptionId,proto3" json:"subscription_id,omitempty"`
+	Key            string `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Data           string `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Delivery) Reset() {
+	*x = Delivery{}
+	mi := &file_sprpc_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Delivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Delivery) ProtoMessage() {}
+
+func (x *Delivery) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Delivery.ProtoReflect.Descriptor instead.
+func (*Delivery) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *Delivery) GetDeliveryId() uint64 {
+	if x != nil {
+		return x.DeliveryId
+	}
+	return 0
+}
+
+func (x *Delivery) GetSubscriptionId() int64 {
+	if x != nil {
+		return x.SubscriptionId
+	}
+	return 0
+}
+
+func (x *Delivery) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Delivery) GetData() string {
+	if x != nil {
+		return x.Data
+	}
+	return ""
+}
+
+type SubjectList struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []string               `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubjectList) Reset() {
+	*x = SubjectList{}
+	mi := &file_sprpc_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubjectList) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubjectList) ProtoMessage() {}
+
+func (x *SubjectList) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubjectList.ProtoReflect.Descriptor instead.
+func (*SubjectList) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SubjectList) GetKeys() []string {
+	if x != nil {
+		return x.Keys
+	}
+	return nil
+}
+
+type SubjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubjectRequest) Reset() {
+	*x = SubjectRequest{}
+	mi := &file_sprpc_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubjectRequest) ProtoMessage() {}
+
+func (x *SubjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubjectRequest.ProtoReflect.Descriptor instead.
+func (*SubjectRequest) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SubjectRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type SubjectStats struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Key        string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Published  uint64                 `protobuf:"varint,2,opt,name=published,proto3" json:"published,omitempty"`
+	Delivered  uint64                 `protobuf:"varint,3,opt,name=delivered,proto3" json:"delivered,omitempty"`
+	Expired    uint64                 `protobuf:"varint,4,opt,name=expired,proto3" json:"expired,omitempty"`
+	Dropped    uint64                 `protobuf:"varint,5,opt,name=dropped,proto3" json:"dropped,omitempty"`
+	Duplicates uint64                 `protobuf:"varint,6,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	// Число активных удалённых подписок на канал
+	Subscriptions uint32 `protobuf:"varint,7,opt,name=subscriptions,proto3" json:"subscriptions,omitempty"`
+	// Число сообщений, ожидающих доставки в очередях подписок канала
+	Pending       uint64 `protobuf:"varint,8,opt,name=pending,proto3" json:"pending,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubjectStats) Reset() {
+	*x = SubjectStats{}
+	mi := &file_sprpc_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubjectStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubjectStats) ProtoMessage() {}
+
+func (x *SubjectStats) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubjectStats.ProtoReflect.Descriptor instead.
+func (*SubjectStats) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SubjectStats) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SubjectStats) GetPublished() uint64 {
+	if x != nil {
+		return x.Published
+	}
+	return 0
+}
+
+func (x *SubjectStats) GetDelivered() uint64 {
+	if x != nil {
+		return x.Delivered
+	}
+	return 0
+}
+
+func (x *SubjectStats) GetExpired() uint64 {
+	if x != nil {
+		return x.Expired
+	}
+	return 0
+}
+
+func (x *SubjectStats) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *SubjectStats) GetDuplicates() uint64 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+func (x *SubjectStats) GetSubscriptions() uint32 {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return 0
+}
+
+func (x *SubjectStats) GetPending() uint64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+type PurgeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Число удалённых сообщений
+	Purged        uint64 `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeResponse) Reset() {
+	*x = PurgeResponse{}
+	mi := &file_sprpc_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeResponse) ProtoMessage() {}
+
+func (x *PurgeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeResponse.ProtoReflect.Descriptor instead.
+func (*PurgeResponse) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *PurgeResponse) GetPurged() uint64 {
+	if x != nil {
+		return x.Purged
+	}
+	return 0
+}
+
+type DrainRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Максимальное время ожидания доставки сообщений (если не задано, ожидание не ограничено)
+	Timeout       *durationpb.Duration `protobuf:"bytes,1,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DrainRequest) Reset() {
+	*x = DrainRequest{}
+	mi := &file_sprpc_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DrainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DrainRequest) ProtoMessage() {}
+
+func (x *DrainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DrainRequest.ProtoReflect.Descriptor instead.
+func (*DrainRequest) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DrainRequest) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+type ReloadResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Параметры конфигурации, применённые без перезапуска
+	Applied []string `protobuf:"bytes,1,rep,name=applied,proto3" json:"applied,omitempty"`
+	// Изменённые параметры, которые требуют перезапуска сервиса
+	Rejected      []string `protobuf:"bytes,2,rep,name=rejected,proto3" json:"rejected,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadResponse) Reset() {
+	*x = ReloadResponse{}
+	mi := &file_sprpc_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadResponse) ProtoMessage() {}
+
+func (x *ReloadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_sprpc_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadResponse.ProtoReflect.Descriptor instead.
+func (*ReloadResponse) Descriptor() ([]byte, []int) {
+	return file_sprpc_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ReloadResponse) GetApplied() []string {
+	if x != nil {
+		return x.Applied
+	}
+	return nil
+}
+
+func (x *ReloadResponse) GetRejected() []string {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+var File_sprpc_proto protoreflect.FileDescriptor
+
+const file_sprpc_proto_rawDesc = "" +
+	"\n" +
+	"\vsprpc.proto\x12\x05sprpc\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"$\n" +
+	"\x10SubscribeRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\xd1\x01\n" +
+	"\x0ePublishRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\tR\x04data\x12+\n" +
+	"\x03ttl\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\x129\n" +
+	"\n" +
+	"deliver_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tdeliverAt\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\x05R\bpriority\x12\x15\n" +
+	"\x06msg_id\x18\x06 \x01(\tR\x05msgId\"\x80\x01\n" +
+	"\x10PublishTxRequest\x121\n" +
+	"\bmessages\x18\x01 \x03(\v2\x15.sprpc.PublishRequestR\bmessages\x129\n" +
+	"\n" +
+	"deliver_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tdeliverAt\"P\n" +
+	"\x0fPublishResponse\x12\x1f\n" +
+	"\vschedule_id\x18\x01 \x01(\tR\n" +
+	"scheduleId\x12\x1c\n" +
+	"\tduplicate\x18\x02 \x01(\bR\tduplicate\"\"\n" +
+	"\x10ScheduledRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\xe1\x01\n" +
+	"\x10ScheduledMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\tR\x04data\x129\n" +
+	"\n" +
+	"deliver_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tdeliverAt\x12+\n" +
+	"\x03ttl\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\x03ttl\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\x05R\bpriority\x12\x13\n" +
+	"\x05tx_id\x18\a \x01(\tR\x04txId\"D\n" +
+	"\rScheduledList\x123\n" +
+	"\bmessages\x18\x01 \x03(\v2\x17.sprpc.ScheduledMessageR\bmessages\"%\n" +
+	"\x13SubscriptionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xb4\x01\n" +
+	"\x05Event\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\tR\x04data\x12'\n" +
+	"\x0fsubscription_id\x18\x02 \x01(\x03R\x0esubscriptionId\x12$\n" +
+	"\x04type\x18\x03 \x01(\x0e2\x10.sprpc.EventTypeR\x04type\x12H\n" +
+	"\x12heartbeat_interval\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\x11heartbeatInterval\"\xfc\x01\n" +
+	"\x10SubscriptionInfo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x1f\n" +
+	"\vclient_addr\x18\x03 \x01(\tR\n" +
+	"clientAddr\x129\n" +
+	"\n" +
+	"started_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12\x1c\n" +
+	"\tdelivered\x18\x05 \x01(\x04R\tdelivered\x12\x16\n" +
+	"\x06paused\x18\x06 \x01(\bR\x06paused\x12\x1a\n" +
+	"\bidentity\x18\a \x01(\tR\bidentity\x12\x18\n" +
+	"\aaccount\x18\b \x01(\tR\aaccount\"Q\n" +
+	"\x10SubscriptionList\x12=\n" +
+	"\rsubscriptions\x18\x01 \x03(\v2\x17.sprpc.SubscriptionInfoR\rsubscriptions\"\xa7\x02\n" +
+	"\vClientFrame\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x127\n" +
+	"\tsubscribe\x18\x02 \x01(\v2\x17.sprpc.SubscribeRequestH\x00R\tsubscribe\x128\n" +
+	"\vunsubscribe\x18\x03 \x01(\v2\x14.sprpc.UnsubscribeOpH\x00R\vunsubscribe\x121\n" +
+	"\apublish\x18\x04 \x01(\v2\x15.sprpc.PublishRequestH\x00R\apublish\x12 \n" +
+	"\x03ack\x18\x05 \x01(\v2\f.sprpc.AckOpH\x00R\x03ack\x12#\n" +
+	"\x04flow\x18\x06 \x01(\v2\r.sprpc.FlowOpH\x00R\x04flowB\x04\n" +
+	"\x02op\"8\n" +
+	"\rUnsubscribeOp\x12'\n" +
+	"\x0fsubscription_id\x18\x01 \x01(\x03R\x0esubscriptionId\"(\n" +
+	"\x05AckOp\x12\x1f\n" +
+	"\vdelivery_id\x18\x01 \x01(\x04R\n" +
+	"deliveryId\" \n" +
+	"\x06FlowOp\x12\x16\n" +
+	"\x06window\x18\x01 \x01(\rR\x06window\"i\n" +
+	"\vServerFrame\x12)\n" +
+	"\x06result\x18\x01 \x01(\v2\x0f.sprpc.OpResultH\x00R\x06result\x12'\n" +
+	"\x05event\x18\x02 \x01(\v2\x0f.sprpc.DeliveryH\x00R\x05eventB\x06\n" +
+	"\x04kind\"\xf2\x01\n" +
+	"\bOpResult\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\x05R\x04code\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12'\n" +
+	"\x0fsubscription_id\x18\x04 \x01(\x03R\x0esubscriptionId\x120\n" +
+	"\apublish\x18\x05 \x01(\v2\x16.sprpc.PublishResponseR\apublish\x12:\n" +
+	"\vretry_after\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"retryAfter\"z\n" +
+	"\bDelivery\x12\x1f\n" +
+	"\vdelivery_id\x18\x01 \x01(\x04R\n" +
+	"deliveryId\x12'\n" +
+	"\x0fsubscription_id\x18\x02 \x01(\x03R\x0esubscriptionId\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\tR\x04data\"!\n" +
+	"\vSubjectList\x12\x12\n" +
+	"\x04keys\x18\x01 \x03(\tR\x04keys\"\"\n" +
+	"\x0eSubjectRequest\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\"\xf0\x01\n" +
+	"\fSubjectStats\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x1c\n" +
+	"\tpublished\x18\x02 \x01(\x04R\tpublished\x12\x1c\n" +
+	"\tdelivered\x18\x03 \x01(\x04R\tdelivered\x12\x18\n" +
+	"\aexpired\x18\x04 \x01(\x04R\aexpired\x12\x18\n" +
+	"\adropped\x18\x05 \x01(\x04R\adropped\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x06 \x01(\x04R\n" +
+	"duplicates\x12$\n" +
+	"\rsubscriptions\x18\a \x01(\rR\rsubscriptions\x12\x18\n" +
+	"\apending\x18\b \x01(\x04R\apending\"'\n" +
+	"\rPurgeResponse\x12\x16\n" +
+	"\x06purged\x18\x01 \x01(\x04R\x06purged\"C\n" +
+	"\fDrainRequest\x123\n" +
+	"\atimeout\x18\x01 \x01(\v2\x19.google.protobuf.DurationR\atimeout\"F\n" +
+	"\x0eReloadResponse\x12\x18\n" +
+	"\aapplied\x18\x01 \x03(\tR\aapplied\x12\x1a\n" +
+	"\brejected\x18\x02 \x03(\tR\brejected*q\n" +
+	"\tEventType\x12\x1a\n" +
+	"\x16EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14EVENT_TYPE_HEARTBEAT\x10\x01\x12\x19\n" +
+	"\x15EVENT_TYPE_SUBSCRIBED\x10\x02\x12\x13\n" +
+	"\x0fEVENT_TYPE_DATA\x10\x032\xc1\x03\n" +
+	"\x06PubSub\x126\n" +
+	"\tSubscribe\x12\x17.sprpc.SubscribeRequest\x1a\f.sprpc.Event\"\x000\x01\x127\n" +
+	"\aConnect\x12\x12.sprpc.ClientFrame\x1a\x12.sprpc.ServerFrame\"\x00(\x010\x01\x12:\n" +
+	"\aPublish\x12\x15.sprpc.PublishRequest\x1a\x16.sprpc.PublishResponse\"\x00\x12C\n" +
+	"\vUnsubscribe\x12\x1a.sprpc.SubscriptionRequest\x1a\x16.google.protobuf.Empty\"\x00\x12>\n" +
+	"\tPublishTx\x12\x17.sprpc.PublishTxRequest\x1a\x16.sprpc.PublishResponse\"\x00\x12?\n" +
+	"\rListScheduled\x12\x16.google.protobuf.Empty\x1a\x14.sprpc.ScheduledList\"\x00\x12D\n" +
+	"\x0fCancelScheduled\x12\x17.sprpc.ScheduledRequest\x1a\x16.google.protobuf.Empty\"\x002\xed\x04\n" +
+	"\x05Admin\x12<\n" +
+	"\fListSubjects\x12\x16.google.protobuf.Empty\x1a\x12.sprpc.SubjectList\"\x00\x12?\n" +
+	"\x0fGetSubjectStats\x12\x15.sprpc.SubjectRequest\x1a\x13.sprpc.SubjectStats\"\x00\x12F\n" +
+	"\x11ListSubscriptions\x12\x16.google.protobuf.Empty\x1a\x17.sprpc.SubscriptionList\"\x00\x12I\n" +
+	"\x11PauseSubscription\x12\x1a.sprpc.SubscriptionRequest\x1a\x16.google.protobuf.Empty\"\x00\x12J\n" +
+	"\x12ResumeSubscription\x12\x1a.sprpc.SubscriptionRequest\x1a\x16.google.protobuf.Empty\"\x00\x12H\n" +
+	"\x10KickSubscription\x12\x1a.sprpc.SubscriptionRequest\x1a\x16.google.protobuf.Empty\"\x00\x12=\n" +
+	"\fPurgeSubject\x12\x15.sprpc.SubjectRequest\x1a\x14.sprpc.PurgeResponse\"\x00\x12<\n" +
+	"\vDrainServer\x12\x13.sprpc.DrainRequest\x1a\x16.google.protobuf.Empty\"\x00\x12?\n" +
+	"\fReloadConfig\x12\x16.google.protobuf.Empty\x1a\x15.sprpc.ReloadResponse\"\x00B=Z;github.com/MaKcm14/vk-test/internal/controller/spserv/sprpcb\x06proto3"
+
+var (
+	file_sprpc_proto_rawDescOnce sync.Once
+	file_sprpc_proto_rawDescData []byte
+)
+
+func file_sprpc_proto_rawDescGZIP() []byte {
+	file_sprpc_proto_rawDescOnce.Do(func() {
+		file_sprpc_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_sprpc_proto_rawDesc), len(file_sprpc_proto_rawDesc)))
+	})
+	return file_sprpc_proto_rawDescData
+}
+
+var file_sprpc_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_sprpc_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
+var file_sprpc_proto_goTypes = []any{
+	(EventType)(0),                // 0: sprpc.EventType
+	(*SubscribeRequest)(nil),      // 1: sprpc.SubscribeRequest
+	(*PublishRequest)(nil),        // 2: sprpc.PublishRequest
+	(*PublishTxRequest)(nil),      // 3: sprpc.PublishTxRequest
+	(*PublishResponse)(nil),       // 4: sprpc.PublishResponse
+	(*ScheduledRequest)(nil),      // 5: sprpc.ScheduledRequest
+	(*ScheduledMessage)(nil),      // 6: sprpc.ScheduledMessage
+	(*ScheduledList)(nil),         // 7: sprpc.ScheduledList
+	(*SubscriptionRequest)(nil),   // 8: sprpc.SubscriptionRequest
+	(*Event)(nil),                 // 9: sprpc.Event
+	(*SubscriptionInfo)(nil),      // 10: sprpc.SubscriptionInfo
+	(*SubscriptionList)(nil),      // 11: sprpc.SubscriptionList
+	(*ClientFrame)(nil),           // 12: sprpc.ClientFrame
+	(*UnsubscribeOp)(nil),         // 13: sprpc.UnsubscribeOp
+	(*AckOp)(nil),                 // 14: sprpc.AckOp
+	(*FlowOp)(nil),                // 15: sprpc.FlowOp
+	(*ServerFrame)(nil),           // 16: sprpc.ServerFrame
+	(*OpResult)(nil),              // 17: sprpc.OpResult
+	(*Delivery)(nil),              // 18: sprpc.Delivery
+	(*SubjectList)(nil),           // 19: sprpc.SubjectList
+	(*SubjectRequest)(nil),        // 20: sprpc.SubjectRequest
+	(*SubjectStats)(nil),          // 21: sprpc.SubjectStats
+	(*PurgeResponse)(nil),         // 22: sprpc.PurgeResponse
+	(*DrainRequest)(nil),          // 23: sprpc.DrainRequest
+	(*ReloadResponse)(nil),        // 24: sprpc.ReloadResponse
+	(*durationpb.Duration)(nil),   // 25: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil), // 26: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 27: google.protobuf.Empty
+}
+var file_sprpc_proto_depIdxs = []int32{
+	25, // 0: sprpc.PublishRequest.ttl:type_name -> google.protobuf.Duration
+	26, // 1: sprpc.PublishRequest.deliver_at:type_name -> google.protobuf.Timestamp
+	2,  // 2: sprpc.PublishTxRequest.messages:type_name -> sprpc.PublishRequest
+	26, // 3: sprpc.PublishTxRequest.deliver_at:type_name -> google.protobuf.Timestamp
+	26, // 4: sprpc.ScheduledMessage.deliver_at:type_name -> google.protobuf.Timestamp
+	25, // 5: sprpc.ScheduledMessage.ttl:type_name -> google.protobuf.Duration
+	6,  // 6: sprpc.ScheduledList.messages:type_name -> sprpc.ScheduledMessage
+	0,  // 7: sprpc.Event.type:type_name -> sprpc.EventType
+	25, // 8: sprpc.Event.heartbeat_interval:type_name -> google.protobuf.Duration
+	26, // 9: sprpc.SubscriptionInfo.started_at:type_name -> google.protobuf.Timestamp
+	10, // 10: sprpc.SubscriptionList.subscriptions:type_name -> sprpc.SubscriptionInfo
+	1,  // 11: sprpc.ClientFrame.subscribe:type_name -> sprpc.SubscribeRequest
+	13, // 12: sprpc.ClientFrame.unsubscribe:type_name -> sprpc.UnsubscribeOp
+	2,  // 13: sprpc.ClientFrame.publish:type_name -> sprpc.PublishRequest
+	14, // 14: sprpc.ClientFrame.ack:type_name -> sprpc.AckOp
+	15, // 15: sprpc.ClientFrame.flow:type_name -> sprpc.FlowOp
+	17, // 16: sprpc.ServerFrame.result:type_name -> sprpc.OpResult
+	18, // 17: sprpc.ServerFrame.event:type_name -> sprpc.Delivery
+	4,  // 18: sprpc.OpResult.publish:type_name -> sprpc.PublishResponse
+	25, // 19: sprpc.OpResult.retry_after:type_name -> google.protobuf.Duration
+	25, // 20: sprpc.DrainRequest.timeout:type_name -> google.protobuf.Duration
+	1,  // 21: sprpc.PubSub.Subscribe:input_type -> sprpc.SubscribeRequest
+	12, // 22: sprpc.PubSub.Connect:input_type -> sprpc.ClientFrame
+	2,  // 23: sprpc.PubSub.Publish:input_type -> sprpc.PublishRequest
+	8,  // 24: sprpc.PubSub.Unsubscribe:input_type -> sprpc.SubscriptionRequest
+	3,  // 25: sprpc.PubSub.PublishTx:input_type -> sprpc.PublishTxRequest
+	27, // 26: sprpc.PubSub.ListScheduled:input_type -> google.protobuf.Empty
+	5,  // 27: sprpc.PubSub.CancelScheduled:input_type -> sprpc.ScheduledRequest
+	27, // 28: sprpc.Admin.ListSubjects:input_type -> google.protobuf.Empty
+	20, // 29: sprpc.Admin.GetSubjectStats:input_type -> sprpc.SubjectRequest
+	27, // 30: sprpc.Admin.ListSubscriptions:input_type -> google.protobuf.Empty
+	8,  // 31: sprpc.Admin.PauseSubscription:input_type -> sprpc.SubscriptionRequest
+	8,  // 32: sprpc.Admin.ResumeSubscription:input_type -> sprpc.SubscriptionRequest
+	8,  // 33: sprpc.Admin.KickSubscription:input_type -> sprpc.SubscriptionRequest
+	20, // 34: sprpc.Admin.PurgeSubject:input_type -> sprpc.SubjectRequest
+	23, // 35: sprpc.Admin.DrainServer:input_type -> sprpc.DrainRequest
+	27, // 36: sprpc.Admin.ReloadConfig:input_type -> google.protobuf.Empty
+	9,  // 37: sprpc.PubSub.Subscribe:output_type -> sprpc.Event
+	16, // 38: sprpc.PubSub.Connect:output_type -> sprpc.ServerFrame
+	4,  // 39: sprpc.PubSub.Publish:output_type -> sprpc.PublishResponse
+	27, // 40: sprpc.PubSub.Unsubscribe:output_type -> google.protobuf.Empty
+	4,  // 41: sprpc.PubSub.PublishTx:output_type -> sprpc.PublishResponse
+	7,  // 42: sprpc.PubSub.ListScheduled:output_type -> sprpc.ScheduledList
+	27, // 43: sprpc.PubSub.CancelScheduled:output_type -> google.protobuf.Empty
+	19, // 44: sprpc.Admin.ListSubjects:output_type -> sprpc.SubjectList
+	21, // 45: sprpc.Admin.GetSubjectStats:output_type -> sprpc.SubjectStats
+	11, // 46: sprpc.Admin.ListSubscriptions:output_type -> sprpc.SubscriptionList
+	27, // 47: sprpc.Admin.PauseSubscription:output_type -> google.protobuf.Empty
+	27, // 48: sprpc.Admin.ResumeSubscription:output_type -> google.protobuf.Empty
+	27, // 49: sprpc.Admin.KickSubscription:output_type -> google.protobuf.Empty
+	22, // 50: sprpc.Admin.PurgeSubject:output_type -> sprpc.PurgeResponse
+	27, // 51: sprpc.Admin.DrainServer:output_type -> google.protobuf.Empty
+	24, // 52: sprpc.Admin.ReloadConfig:output_type -> sprpc.ReloadResponse
+	37, // [37:53] is the sub-list for method output_type
+	21, // [21:37] is the sub-list for method input_type
+	21, // [21:21] is the sub-list for extension type_name
+	21, // [21:21] is the sub-list for extension extendee
+	0,  // [0:21] is the sub-list for field type_name
+}
+
+func init() { file_sprpc_proto_init() }
+func file_sprpc_proto_init() {
+	if File_sprpc_proto != nil {
+		return
+	}
+	file_sprpc_proto_msgTypes[11].OneofWrappers = []any{
+		(*ClientFrame_Subscribe)(nil),
+		(*ClientFrame_Unsubscribe)(nil),
+		(*ClientFrame_Publish)(nil),
+		(*ClientFrame_Ack)(nil),
+		(*ClientFrame_Flow)(nil),
+	}
+	file_sprpc_proto_msgTypes[15].OneofWrappers = []any{
+		(*ServerFrame_Result)(nil),
+		(*ServerFrame_Event)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_sprpc_proto_rawDesc), len(file_sprpc_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      1,
+			NumMessages:   24,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_sprpc_proto_goTypes,
 		DependencyIndexes: file_sprpc_proto_depIdxs,
+		EnumInfos:         file_sprpc_proto_enumTypes,
 		MessageInfos:      file_sprpc_proto_msgTypes,
 	}.Build()
 	File_sprpc_proto = out.File
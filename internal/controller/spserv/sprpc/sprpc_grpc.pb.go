@@ -20,18 +20,34 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PubSub_Subscribe_FullMethodName = "/sprpc.PubSub/Subscribe"
-	PubSub_Publish_FullMethodName   = "/sprpc.PubSub/Publish"
+	PubSub_Subscribe_FullMethodName       = "/sprpc.PubSub/Subscribe"
+	PubSub_Connect_FullMethodName         = "/sprpc.PubSub/Connect"
+	PubSub_Publish_FullMethodName         = "/sprpc.PubSub/Publish"
+	PubSub_Unsubscribe_FullMethodName     = "/sprpc.PubSub/Unsubscribe"
+	PubSub_PublishTx_FullMethodName       = "/sprpc.PubSub/PublishTx"
+	PubSub_ListScheduled_FullMethodName   = "/sprpc.PubSub/ListScheduled"
+	PubSub_CancelScheduled_FullMethodName = "/sprpc.PubSub/CancelScheduled"
 )
 
 // PubSubClient is the client API for PubSub service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type PubSubClient interface {
-	// Подписка (сервер отправляет поток событий)
+	//  Подписка (сервер отправляет поток событий)
 	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+	// Мультиплексированное соединение: подписки, отписки, публикации и подтверждения событий
+	// для множества каналов в рамках одного двунаправленного потока
+	Connect(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ClientFrame, ServerFrame], error)
 	// Публикация (классический запрос-ответ)
-	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error)
+	// Отписка по ID подписки, полученному первым событием потока Subscribe (поток при этом завершается)
+	Unsubscribe(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Атомарная публикация в несколько каналов: публикуются либо все сообщения, либо ни одно
+	PublishTx(ctx context.Context, in *PublishTxRequest, opts ...grpc.CallOption) (*PublishResponse, error)
+	// Список сообщений, ожидающих отложенной публикации
+	ListScheduled(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ScheduledList, error)
+	// Отмена отложенной публикации по ID сообщения
+	CancelScheduled(ctx context.Context, in *ScheduledRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type pubSubClient struct {
@@ -61,9 +77,22 @@ func (c *pubSubClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type PubSub_SubscribeClient = grpc.ServerStreamingClient[Event]
 
-func (c *pubSubClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (c *pubSubClient) Connect(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ClientFrame, ServerFrame], error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(emptypb.Empty)
+	stream, err := c.cc.NewStream(ctx, &PubSub_ServiceDesc.Streams[1], PubSub_Connect_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ClientFrame, ServerFrame]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PubSub_ConnectClient = grpc.BidiStreamingClient[ClientFrame, ServerFrame]
+
+func (c *pubSubClient) Publish(ctx context.Context, in *PublishRequest, opts ...grpc.CallOption) (*PublishResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishResponse)
 	err := c.cc.Invoke(ctx, PubSub_Publish_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -71,14 +100,65 @@ func (c *pubSubClient) Publish(ctx context.Context, in *PublishRequest, opts ...
 	return out, nil
 }
 
+func (c *pubSubClient) Unsubscribe(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PubSub_Unsubscribe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pubSubClient) PublishTx(ctx context.Context, in *PublishTxRequest, opts ...grpc.CallOption) (*PublishResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishResponse)
+	err := c.cc.Invoke(ctx, PubSub_PublishTx_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pubSubClient) ListScheduled(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ScheduledList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduledList)
+	err := c.cc.Invoke(ctx, PubSub_ListScheduled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pubSubClient) CancelScheduled(ctx context.Context, in *ScheduledRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PubSub_CancelScheduled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PubSubServer is the server API for PubSub service.
 // All implementations must embed UnimplementedPubSubServer
 // for forward compatibility.
 type PubSubServer interface {
-	// Подписка (сервер отправляет поток событий)
+	//  Подписка (сервер отправляет поток событий)
 	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error
+	// Мультиплексированное соединение: подписки, отписки, публикации и подтверждения событий
+	// для множества каналов в рамках одного двунаправленного потока
+	Connect(grpc.BidiStreamingServer[ClientFrame, ServerFrame]) error
 	// Публикация (классический запрос-ответ)
-	Publish(context.Context, *PublishRequest) (*emptypb.Empty, error)
+	Publish(context.Context, *PublishRequest) (*PublishResponse, error)
+	// Отписка по ID подписки, полученному первым событием потока Subscribe (поток при этом завершается)
+	Unsubscribe(context.Context, *SubscriptionRequest) (*emptypb.Empty, error)
+	// Атомарная публикация в несколько каналов: публикуются либо все сообщения, либо ни одно
+	PublishTx(context.Context, *PublishTxRequest) (*PublishResponse, error)
+	// Список сообщений, ожидающих отложенной публикации
+	ListScheduled(context.Context, *emptypb.Empty) (*ScheduledList, error)
+	// Отмена отложенной публикации по ID сообщения
+	CancelScheduled(context.Context, *ScheduledRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedPubSubServer()
 }
 
@@ -92,9 +172,24 @@ type UnimplementedPubSubServer struct{}
 func (UnimplementedPubSubServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error {
 	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
 }
-func (UnimplementedPubSubServer) Publish(context.Context, *PublishRequest) (*emptypb.Empty, error) {
+func (UnimplementedPubSubServer) Connect(grpc.BidiStreamingServer[ClientFrame, ServerFrame]) error {
+	return status.Errorf(codes.Unimplemented, "method Connect not implemented")
+}
+func (UnimplementedPubSubServer) Publish(context.Context, *PublishRequest) (*PublishResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Publish not implemented")
 }
+func (UnimplementedPubSubServer) Unsubscribe(context.Context, *SubscriptionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unsubscribe not implemented")
+}
+func (UnimplementedPubSubServer) PublishTx(context.Context, *PublishTxRequest) (*PublishResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishTx not implemented")
+}
+func (UnimplementedPubSubServer) ListScheduled(context.Context, *emptypb.Empty) (*ScheduledList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListScheduled not implemented")
+}
+func (UnimplementedPubSubServer) CancelScheduled(context.Context, *ScheduledRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelScheduled not implemented")
+}
 func (UnimplementedPubSubServer) mustEmbedUnimplementedPubSubServer() {}
 func (UnimplementedPubSubServer) testEmbeddedByValue()                {}
 
@@ -127,6 +222,13 @@ func _PubSub_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type PubSub_SubscribeServer = grpc.ServerStreamingServer[Event]
 
+func _PubSub_Connect_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PubSubServer).Connect(&grpc.GenericServerStream[ClientFrame, ServerFrame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PubSub_ConnectServer = grpc.BidiStreamingServer[ClientFrame, ServerFrame]
+
 func _PubSub_Publish_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PublishRequest)
 	if err := dec(in); err != nil {
@@ -145,6 +247,78 @@ func _PubSub_Publish_Handler(srv interface{}, ctx context.Context, dec func(inte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PubSub_Unsubscribe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PubSubServer).Unsubscribe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PubSub_Unsubscribe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PubSubServer).Unsubscribe(ctx, req.(*SubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PubSub_PublishTx_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishTxRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PubSubServer).PublishTx(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PubSub_PublishTx_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PubSubServer).PublishTx(ctx, req.(*PublishTxRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PubSub_ListScheduled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PubSubServer).ListScheduled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PubSub_ListScheduled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PubSubServer).ListScheduled(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PubSub_CancelScheduled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PubSubServer).CancelScheduled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PubSub_CancelScheduled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PubSubServer).CancelScheduled(ctx, req.(*ScheduledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PubSub_ServiceDesc is the grpc.ServiceDesc for PubSub service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -156,6 +330,22 @@ var PubSub_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Publish",
 			Handler:    _PubSub_Publish_Handler,
 		},
+		{
+			MethodName: "Unsubscribe",
+			Handler:    _PubSub_Unsubscribe_Handler,
+		},
+		{
+			MethodName: "PublishTx",
+			Handler:    _PubSub_PublishTx_Handler,
+		},
+		{
+			MethodName: "ListScheduled",
+			Handler:    _PubSub_ListScheduled_Handler,
+		},
+		{
+			MethodName: "CancelScheduled",
+			Handler:    _PubSub_CancelScheduled_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -163,6 +353,444 @@ var PubSub_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _PubSub_Subscribe_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Connect",
+			Handler:       _PubSub_Connect_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "sprpc.proto",
+}
+
+const (
+	Admin_ListSubjects_FullMethodName       = "/sprpc.Admin/ListSubjects"
+	Admin_GetSubjectStats_FullMethodName    = "/sprpc.Admin/GetSubjectStats"
+	Admin_ListSubscriptions_FullMethodName  = "/sprpc.Admin/ListSubscriptions"
+	Admin_PauseSubscription_FullMethodName  = "/sprpc.Admin/PauseSubscription"
+	Admin_ResumeSubscription_FullMethodName = "/sprpc.Admin/ResumeSubscription"
+	Admin_KickSubscription_FullMethodName   = "/sprpc.Admin/KickSubscription"
+	Admin_PurgeSubject_FullMethodName       = "/sprpc.Admin/PurgeSubject"
+	Admin_DrainServer_FullMethodName        = "/sprpc.Admin/DrainServer"
+	Admin_ReloadConfig_FullMethodName       = "/sprpc.Admin/ReloadConfig"
+)
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Сервис администрирования брокера: может обслуживаться на отдельном сокете,
+// недоступном обычным клиентам
+type AdminClient interface {
+	// Список каналов с активными подписками
+	ListSubjects(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SubjectList, error)
+	// Статистика сообщений канала
+	GetSubjectStats(ctx context.Context, in *SubjectRequest, opts ...grpc.CallOption) (*SubjectStats, error)
+	// Список активных подписок с их состоянием
+	ListSubscriptions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SubscriptionList, error)
+	// Приостановка доставки событий подписчику по его ID: сообщения накапливаются в очереди подписки
+	// (не более 1024, при переполнении вытесняются самые старые). Доступна только в сервисе Admin
+	PauseSubscription(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Возобновление доставки событий подписчику по его ID
+	ResumeSubscription(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Принудительное отключение подписки: поток подписчика завершается с ошибкой
+	KickSubscription(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Удаление сообщений, ожидающих доставки в очередях подписок канала
+	PurgeSubject(ctx context.Context, in *SubjectRequest, opts ...grpc.CallOption) (*PurgeResponse, error)
+	// Остановка приёма новых подписок и публикаций с ожиданием доставки уже опубликованных сообщений
+	DrainServer(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Перечитывание конфигурации без разрыва потоков подписчиков
+	ReloadConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReloadResponse, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListSubjects(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SubjectList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubjectList)
+	err := c.cc.Invoke(ctx, Admin_ListSubjects_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetSubjectStats(ctx context.Context, in *SubjectRequest, opts ...grpc.CallOption) (*SubjectStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubjectStats)
+	err := c.cc.Invoke(ctx, Admin_GetSubjectStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListSubscriptions(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*SubscriptionList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubscriptionList)
+	err := c.cc.Invoke(ctx, Admin_ListSubscriptions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) PauseSubscription(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Admin_PauseSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ResumeSubscription(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Admin_ResumeSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) KickSubscription(ctx context.Context, in *SubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Admin_KickSubscription_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) PurgeSubject(ctx context.Context, in *SubjectRequest, opts ...grpc.CallOption) (*PurgeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeResponse)
+	err := c.cc.Invoke(ctx, Admin_PurgeSubject_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DrainServer(ctx context.Context, in *DrainRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Admin_DrainServer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ReloadConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReloadResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReloadResponse)
+	err := c.cc.Invoke(ctx, Admin_ReloadConfig_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility.
+//
+// Сервис администрирования брокера: может обслуживаться на отдельном сокете,
+// недоступном обычным клиентам
+type AdminServer interface {
+	// Список каналов с активными подписками
+	ListSubjects(context.Context, *emptypb.Empty) (*SubjectList, error)
+	// Статистика сообщений канала
+	GetSubjectStats(context.Context, *SubjectRequest) (*SubjectStats, error)
+	// Список активных подписок с их состоянием
+	ListSubscriptions(context.Context, *emptypb.Empty) (*SubscriptionList, error)
+	// Приостановка доставки событий подписчику по его ID: сообщения накапливаются в очереди подписки
+	// (не более 1024, при переполнении вытесняются самые старые). Доступна только в сервисе Admin
+	PauseSubscription(context.Context, *SubscriptionRequest) (*emptypb.Empty, error)
+	// Возобновление доставки событий подписчику по его ID
+	ResumeSubscription(context.Context, *SubscriptionRequest) (*emptypb.Empty, error)
+	// Принудительное отключение подписки: поток подписчика завершается с ошибкой
+	KickSubscription(context.Context, *SubscriptionRequest) (*emptypb.Empty, error)
+	// Удаление сообщений, ожидающих доставки в очередях подписок канала
+	PurgeSubject(context.Context, *SubjectRequest) (*PurgeResponse, error)
+	// Остановка приёма новых подписок и публикаций с ожиданием доставки уже опубликованных сообщений
+	DrainServer(context.Context, *DrainRequest) (*emptypb.Empty, error)
+	// Перечитывание конфигурации без разрыва потоков подписчиков
+	ReloadConfig(context.Context, *emptypb.Empty) (*ReloadResponse, error)
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServer struct{}
+
+func (UnimplementedAdminServer) ListSubjects(context.Context, *emptypb.Empty) (*SubjectList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubjects not implemented")
+}
+func (UnimplementedAdminServer) GetSubjectStats(context.Context, *SubjectRequest) (*SubjectStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSubjectStats not implemented")
+}
+func (UnimplementedAdminServer) ListSubscriptions(context.Context, *emptypb.Empty) (*SubscriptionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscriptions not implemented")
+}
+func (UnimplementedAdminServer) PauseSubscription(context.Context, *SubscriptionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PauseSubscription not implemented")
+}
+func (UnimplementedAdminServer) ResumeSubscription(context.Context, *SubscriptionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResumeSubscription not implemented")
+}
+func (UnimplementedAdminServer) KickSubscription(context.Context, *SubscriptionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KickSubscription not implemented")
+}
+func (UnimplementedAdminServer) PurgeSubject(context.Context, *SubjectRequest) (*PurgeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeSubject not implemented")
+}
+func (UnimplementedAdminServer) DrainServer(context.Context, *DrainRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DrainServer not implemented")
+}
+func (UnimplementedAdminServer) ReloadConfig(context.Context, *emptypb.Empty) (*ReloadResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+func (UnimplementedAdminServer) testEmbeddedByValue()               {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	// If the following call pancis, it indicates UnimplementedAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_ListSubjects_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListSubjects(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListSubjects_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListSubjects(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetSubjectStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetSubjectStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_GetSubjectStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetSubjectStats(ctx, req.(*SubjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListSubscriptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListSubscriptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListSubscriptions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListSubscriptions(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_PauseSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).PauseSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_PauseSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).PauseSubscription(ctx, req.(*SubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ResumeSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ResumeSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ResumeSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ResumeSubscription(ctx, req.(*SubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_KickSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).KickSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_KickSubscription_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).KickSubscription(ctx, req.(*SubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_PurgeSubject_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubjectRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).PurgeSubject(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_PurgeSubject_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).PurgeSubject(ctx, req.(*SubjectRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DrainServer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DrainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DrainServer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_DrainServer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DrainServer(ctx, req.(*DrainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ReloadConfig(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "sprpc.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListSubjects",
+			Handler:    _Admin_ListSubjects_Handler,
+		},
+		{
+			MethodName: "GetSubjectStats",
+			Handler:    _Admin_GetSubjectStats_Handler,
+		},
+		{
+			MethodName: "ListSubscriptions",
+			Handler:    _Admin_ListSubscriptions_Handler,
+		},
+		{
+			MethodName: "PauseSubscription",
+			Handler:    _Admin_PauseSubscription_Handler,
+		},
+		{
+			MethodName: "ResumeSubscription",
+			Handler:    _Admin_ResumeSubscription_Handler,
+		},
+		{
+			MethodName: "KickSubscription",
+			Handler:    _Admin_KickSubscription_Handler,
+		},
+		{
+			MethodName: "PurgeSubject",
+			Handler:    _Admin_PurgeSubject_Handler,
+		},
+		{
+			MethodName: "DrainServer",
+			Handler:    _Admin_DrainServer_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _Admin_ReloadConfig_Handler,
+		},
 	},
+	Streams:  []grpc.StreamDesc{},
 	Metadata: "sprpc.proto",
 }
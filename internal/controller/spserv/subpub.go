@@ -7,6 +7,9 @@ import (
 
 	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 // SubPub is the main service that defines the grpc-server configuring.
@@ -14,39 +17,174 @@ type SubPubService struct {
 	grpcServ *grpc.Server
 	conn     net.Listener
 
+	// adminServ defines the separate grpc-server of the Admin service (nil means the Admin is served by the grpcServ).
+	adminServ *grpc.Server
+	adminConn net.Listener
+
+	// servOpts defines the options shared by the service's grpc-servers.
+	servOpts []grpc.ServerOption
+
+	// auth defines the clients' authentication (nil means every client is accepted).
+	auth *Auth
+
+	// certs defines the reloader of the TLS certificates (nil means no TLS).
+	certs *certReloader
+
 	kern SPServer
 	log  *slog.Logger
 }
 
-func NewSubPubService(log *slog.Logger, socket string, server SPServer) (SubPubService, error) {
+// ServiceOpt defines the func of the service's configuration.
+type ServiceOpt func(s *SubPubService) error
+
+// WithAdminSocket makes the Admin service be served on the separate socket,
+// so it can be firewalled off from the ordinary clients.
+func WithAdminSocket(socket string) ServiceOpt {
+	return func(s *SubPubService) error {
+		const op = "spserv.WithAdminSocket"
+
+		s.log.Info("opening the admin's connection", slog.String("socket", socket))
+		lis, err := net.Listen("tcp", socket)
+
+		if err != nil {
+			return fmt.Errorf("error of the %s: %w: %s", op, ErrNetOpenConn, err)
+		}
+
+		s.adminConn = lis
+
+		return nil
+	}
+}
+
+// WithTLS makes the service's sockets use the TLS transport.
+// The certificates are reloaded after their rotation without the service's restart.
+func WithTLS(conf TLSConfig) ServiceOpt {
+	return func(s *SubPubService) error {
+		reloader, err := newCertReloader(s.log, conf)
+		if err != nil {
+			return err
+		}
+
+		s.log.Info("the TLS transport is enabled")
+		s.certs = reloader
+		s.servOpts = append(s.servOpts, grpc.Creds(credentials.NewTLS(reloader.serverConfig())))
+
+		return nil
+	}
+}
+
+// WithLimits sets the limits of the messages' sizes, the streams and the connections and makes
+// the servers detect the dead clients by the keepalive's pings, so their subscriptions are closed.
+func WithLimits(conf LimitsConfig) ServiceOpt {
+	return func(s *SubPubService) error {
+		s.servOpts = append(s.servOpts, conf.serverOptions()...)
+
+		if conf.MaxConnections > 0 {
+			s.conn = newLimitListener(s.log, s.conn, conf.MaxConnections)
+		}
+		return nil
+	}
+}
+
+// WithServerOptions adds the options to every grpc-server of the service, e.g. the interceptors.
+// The added interceptors are called before the authentication.
+func WithServerOptions(opts ...grpc.ServerOption) ServiceOpt {
+	return func(s *SubPubService) error {
+		s.servOpts = append(s.servOpts, opts...)
+		return nil
+	}
+}
+
+// WithAuthentication makes the service accept only the authenticated clients
+// and authorize their administrative requests.
+func WithAuthentication(auth *Auth) ServiceOpt {
+	return func(s *SubPubService) error {
+		s.log.Info("the clients' authentication is enabled")
+		s.auth = auth
+
+		return nil
+	}
+}
+
+func NewSubPubService(log *slog.Logger, socket string, server SPServer, opts ...ServiceOpt) (SubPubService, error) {
 	const op = "spserv.NewSubPub"
 
-	log.Info(fmt.Sprintf("opening the connection on the %s", socket))
+	log.Info("opening the connection", slog.String("socket", socket))
 	lis, err := net.Listen("tcp", socket)
 
 	if err != nil {
 		errNet := fmt.Errorf("error of the %s: %w: %s", op, ErrNetOpenConn, err)
-		log.Error(errNet.Error())
+		log.Error("the service's connection can't be opened", slog.Any("error", errNet))
 		return SubPubService{}, errNet
 	}
 
-	grpcServ := grpc.NewServer()
-	sprpc.RegisterPubSubServer(grpcServ, server)
+	service := SubPubService{
+		log:  log,
+		conn: lis,
+		kern: server,
+	}
+
+	for _, opt := range opts {
+		if err := opt(&service); err != nil {
+			lis.Close()
+			if service.adminConn != nil {
+				service.adminConn.Close()
+			}
+			log.Error("the service can't be configured", slog.Any("error", err))
+			return SubPubService{}, err
+		}
+	}
+
+	service.servOpts = append(service.servOpts,
+		grpc.ChainUnaryInterceptor(service.auth.unaryInterceptor, retryAfterInterceptor),
+		grpc.ChainStreamInterceptor(service.auth.streamInterceptor),
+	)
+
+	service.grpcServ = grpc.NewServer(service.servOpts...)
+	sprpc.RegisterPubSubServer(service.grpcServ, server)
+	healthpb.RegisterHealthServer(service.grpcServ, server.Health())
+	reflection.Register(service.grpcServ)
 
-	return SubPubService{
-		log:      log,
-		grpcServ: grpcServ,
-		conn:     lis,
-		kern:     server,
-	}, nil
+	if service.adminConn != nil {
+		service.adminServ = grpc.NewServer(service.servOpts...)
+		sprpc.RegisterAdminServer(service.adminServ, server.Admin())
+		healthpb.RegisterHealthServer(service.adminServ, server.Health())
+		reflection.Register(service.adminServ)
+	} else {
+		sprpc.RegisterAdminServer(service.grpcServ, server.Admin())
+	}
+
+	return service, nil
 }
 
 // Run starts the serving new client's requests.
 func (s *SubPubService) Run() {
+	if s.adminServ != nil {
+		s.log.Info("starting the admin's grpc-server")
+		go s.adminServ.Serve(s.adminConn)
+	}
+
 	s.log.Info("starting the grpc-server")
 	s.grpcServ.Serve(s.conn)
 }
 
+// ReloadTLS reloads the TLS certificates without waiting for the next handshake, the existing connections are kept.
+func (s *SubPubService) ReloadTLS() error {
+	if s.certs == nil {
+		return nil
+	}
+	return s.certs.reload()
+}
+
+// PrepareTLS loads the TLS certificates without applying them: the step's Apply replaces the certificates
+// of the next handshakes. The step does nothing if the TLS isn't used.
+func (s *SubPubService) PrepareTLS() (ReloadStep, error) {
+	if s.certs == nil {
+		return ReloadStep{Apply: func() {}}, nil
+	}
+	return s.certs.prepare()
+}
+
 // Close releases the resources of the server.
 func (s *SubPubService) Close() {
 	s.log.Info("releasing the resources of the service")
@@ -55,5 +193,8 @@ func (s *SubPubService) Close() {
 	s.log.Info("service condition was changed on 'closed'")
 
 	s.grpcServ.GracefulStop()
+	if s.adminServ != nil {
+		s.adminServ.GracefulStop()
+	}
 	s.log.Info("service was gracefuly stopped")
 }
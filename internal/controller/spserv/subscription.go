@@ -0,0 +1,107 @@
+package spserv
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// remoteSub defines the remote client's subscription registered on the server.
+type remoteSub struct {
+	sub subpub.Subscription
+
+	id      int64
+	subject string
+
+	// shared defines the subject's name in the shared system's namespace used by the administration.
+	shared string
+
+	// addr defines the client's address.
+	addr string
+
+	// identity defines the client's authenticated identity (empty for the anonymous client).
+	identity string
+
+	// account defines the account whose namespace contains the subject (empty for the default one).
+	account string
+
+	startedAt time.Time
+
+	// delivered defines the count of the events sent to the client.
+	delivered atomic.Uint64
+	paused    atomic.Bool
+
+	// kicked defines whether the subscription was stopped by the administrator.
+	kicked atomic.Bool
+
+	// done is closed after the subscription's stopping.
+	done chan struct{}
+	once sync.Once
+}
+
+// newRemoteSub creates the subscription's record: the sub must be set before the registering.
+func newRemoteSub(ctx context.Context, id int64, subject, shared string) *remoteSub {
+	r := &remoteSub{
+		id:        id,
+		subject:   subject,
+		shared:    shared,
+		startedAt: time.Now(),
+		done:      make(chan struct{}),
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		r.addr = p.Addr.String()
+	}
+
+	if id, ok := IdentityFromContext(ctx); ok {
+		r.identity = id.Name
+		r.account = id.Account
+	}
+	return r
+}
+
+// pause suspends the delivery of the subscription's events.
+func (r *remoteSub) pause() {
+	r.paused.Store(true)
+	r.sub.Pause()
+}
+
+// resume continues the delivery of the subscription's events.
+func (r *remoteSub) resume() {
+	r.paused.Store(false)
+	r.sub.Resume()
+}
+
+// stop unsubscribes the subscription and notifies its stream.
+func (r *remoteSub) stop() {
+	r.once.Do(func() {
+		r.sub.Unsubscribe()
+		close(r.done)
+	})
+}
+
+// kick stops the subscription making its stream finish with the error.
+func (r *remoteSub) kick() {
+	r.kicked.Store(true)
+	r.stop()
+}
+
+// info returns the description of the subscription for the administration.
+func (r *remoteSub) info() *sprpc.SubscriptionInfo {
+	return &sprpc.SubscriptionInfo{
+		Id:         r.id,
+		Key:        r.subject,
+		ClientAddr: r.addr,
+		StartedAt:  timestamppb.New(r.startedAt),
+		Delivered:  r.delivered.Load(),
+		Paused:     r.paused.Load(),
+		Identity:   r.identity,
+		Account:    r.account,
+	}
+}
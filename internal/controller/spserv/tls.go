@@ -0,0 +1,229 @@
+package spserv
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSConfig defines the settings of the service's TLS transport.
+type TLSConfig struct {
+	// CertFile and KeyFile define the paths of the server's certificate and its private key.
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile defines the path of the CA bundle for the clients' certificates verifying (empty means no verifying).
+	ClientCAFile string
+
+	// RequireClientCert defines whether every client must present the valid certificate (the mutual TLS).
+	RequireClientCert bool
+}
+
+// certReloader defines the logic of loading the TLS files and reloading them after their rotation.
+type certReloader struct {
+	conf TLSConfig
+	log  *slog.Logger
+
+	mut sync.Mutex
+
+	// modTime defines the latest modification time of the loaded files.
+	modTime time.Time
+
+	// digest defines the hash of the loaded files' content.
+	digest  [sha256.Size]byte
+	tlsConf *tls.Config
+}
+
+func newCertReloader(log *slog.Logger, conf TLSConfig) (*certReloader, error) {
+	const op = "spserv.newCertReloader"
+
+	if conf.CertFile == "" || conf.KeyFile == "" {
+		return nil, fmt.Errorf("error of the %s: %w: the certificate and the key must be set", op, ErrTLSConfig)
+	}
+
+	if conf.RequireClientCert && conf.ClientCAFile == "" {
+		return nil, fmt.Errorf("error of the %s: %w: the client's certificates can't be required without the client CA", op, ErrTLSConfig)
+	}
+
+	r := &certReloader{
+		conf: conf,
+		log:  log,
+	}
+
+	if _, err := r.config(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// serverConfig returns the base TLS config that takes the current files' state on every handshake.
+func (r *certReloader) serverConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.config()
+		},
+	}
+}
+
+// config returns the current TLS config reloading the files if any of them was changed.
+// The previous config is kept while the rotated files can't be loaded (e.g. the key isn't written yet).
+func (r *certReloader) config() (*tls.Config, error) {
+	const op = "spserv.certReloader.config"
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	modTime, err := r.lastModified()
+	if err == nil && r.tlsConf != nil && modTime.Equal(r.modTime) {
+		return r.tlsConf, nil
+	}
+
+	var (
+		conf   *tls.Config
+		digest [sha256.Size]byte
+	)
+	if err == nil {
+		conf, digest, err = r.load()
+	}
+
+	if err != nil {
+		err = fmt.Errorf("error of the %s: %w: %s", op, ErrTLSConfig, err)
+		if r.tlsConf == nil {
+			return nil, err
+		}
+
+		r.log.Warn("the previous TLS config is used", slog.Any("error", err))
+		return r.tlsConf, nil
+	}
+
+	if r.tlsConf != nil && digest != r.digest {
+		r.log.Info("the TLS certificates were reloaded")
+	}
+	r.tlsConf, r.modTime, r.digest = conf, modTime, digest
+
+	return conf, nil
+}
+
+// prepare loads the TLS files regardless of their modification time without applying them.
+func (r *certReloader) prepare() (ReloadStep, error) {
+	const op = "spserv.certReloader.prepare"
+
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	modTime, err := r.lastModified()
+
+	var (
+		conf   *tls.Config
+		digest [sha256.Size]byte
+	)
+	if err == nil {
+		conf, digest, err = r.load()
+	}
+
+	if err != nil {
+		return ReloadStep{}, fmt.Errorf("error of the %s: %w: %s", op, ErrTLSConfig, err)
+	}
+	changed := digest != r.digest
+
+	return ReloadStep{
+		Changed: changed,
+		Apply: func() {
+			r.mut.Lock()
+			defer r.mut.Unlock()
+
+			r.tlsConf, r.modTime, r.digest = conf, modTime, digest
+			if changed {
+				r.log.Info("the TLS certificates were reloaded")
+			}
+		},
+	}, nil
+}
+
+// reload loads the TLS files regardless of their modification time, the previous config is kept on the error.
+func (r *certReloader) reload() error {
+	step, err := r.prepare()
+	if err != nil {
+		return err
+	}
+	step.Apply()
+
+	return nil
+}
+
+// lastModified returns the latest modification time of the TLS files.
+func (r *certReloader) lastModified() (time.Time, error) {
+	var last time.Time
+
+	for _, path := range []string{r.conf.CertFile, r.conf.KeyFile, r.conf.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(last) {
+			last = info.ModTime()
+		}
+	}
+
+	return last, nil
+}
+
+// load reads the TLS files and builds the config of them, the digest is the hash of the files' content.
+func (r *certReloader) load() (*tls.Config, [sha256.Size]byte, error) {
+	var digest [sha256.Size]byte
+	hash := sha256.New()
+
+	files := make([][]byte, 0, 3)
+	for _, path := range []string{r.conf.CertFile, r.conf.KeyFile, r.conf.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, digest, err
+		}
+		hash.Write(data)
+		files = append(files, data)
+	}
+	hash.Sum(digest[:0])
+
+	cert, err := tls.X509KeyPair(files[0], files[1])
+	if err != nil {
+		return nil, digest, err
+	}
+
+	conf := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{cert},
+	}
+
+	if r.conf.ClientCAFile == "" {
+		return conf, digest, nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(files[2]) {
+		return nil, digest, fmt.Errorf("no certificates were found in the %s", r.conf.ClientCAFile)
+	}
+	conf.ClientCAs = pool
+
+	if r.conf.RequireClientCert {
+		conf.ClientAuth = tls.RequireAndVerifyClientCert
+	} else {
+		conf.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return conf, digest, nil
+}
@@ -0,0 +1,215 @@
+package spserv
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// testCert defines the certificate generated for the tests.
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert generates the certificate signed by the parent (the self-signed CA if the parent is nil).
+func newTestCert(t *testing.T, name string, serial int64, parent *testCert) testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err, "expected no error after the key's generating")
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := tmpl, key
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err, "expected no error after the certificate's creating")
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err, "expected no error after the certificate's parsing")
+
+	return testCert{cert: cert, key: key}
+}
+
+// write stores the certificate and its key in the PEM files.
+func (c testCert) write(t *testing.T, certFile, keyFile string) {
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600), "expected no error after the certificate's writing")
+
+	if keyFile == "" {
+		return
+	}
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err, "expected no error after the key's marshaling")
+
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600), "expected no error after the key's writing")
+}
+
+// tlsCert returns the certificate for the tls.Config.
+func (c testCert) tlsCert() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.cert.Raw}, PrivateKey: c.key}
+}
+
+// startTLSService starts the service with the mutual TLS and returns its address and the CA.
+func startTLSService(t *testing.T, server *SubPubServer) (string, testCert) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", 1, nil)
+	newTestCert(t, "test-server", 2, &ca).write(t, filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	ca.write(t, filepath.Join(dir, "ca.crt"), "")
+
+	service, err := NewSubPubService(server.log, "127.0.0.1:0", server, WithTLS(TLSConfig{
+		CertFile:          filepath.Join(dir, "server.crt"),
+		KeyFile:           filepath.Join(dir, "server.key"),
+		ClientCAFile:      filepath.Join(dir, "ca.crt"),
+		RequireClientCert: true,
+	}))
+	require.NoError(t, err, "expected no error after the service's creating")
+
+	go service.Run()
+	t.Cleanup(service.Close)
+
+	return service.conn.Addr().String(), ca
+}
+
+// dialTLS returns the client's connection that presents the certificates.
+func dialTLS(t *testing.T, addr string, ca testCert, certs ...tls.Certificate) *grpc.ClientConn {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+		RootCAs:      pool,
+		Certificates: certs,
+	})))
+	require.NoError(t, err, "expected no error after the client's creating")
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+func TestTLSPositiveCases(t *testing.T) {
+	server := NewSubPubServer(slog.New(slog.NewTextHandler(io.Discard, nil)), subpub.NewSubPub())
+	addr, ca := startTLSService(t, server)
+
+	t.Run("TestTLSPositiveCases_ClientIdentity", func(t *testing.T) {
+		conn := dialTLS(t, addr, ca, newTestCert(t, "test-client", 3, &ca).tlsCert())
+
+		stream, err := sprpc.NewPubSubClient(conn).Subscribe(context.Background(), &sprpc.SubscribeRequest{Key: "test-channel"})
+		require.NoError(t, err, "expected no error after the subscribing")
+
+		_, err = stream.Recv()
+		require.NoError(t, err, "expected the subscription's id to be received")
+
+		list, err := sprpc.NewAdminClient(conn).ListSubscriptions(context.Background(), &emptypb.Empty{})
+		require.NoError(t, err, "expected no error after the subscriptions' listing")
+
+		require.Len(t, list.Subscriptions, 1, "expected the single subscription")
+		assert.Equal(t, "test-client", list.Subscriptions[0].Identity, "expected the certificate's common name as the identity")
+	})
+}
+
+func TestTLSNegativeCases(t *testing.T) {
+	server := NewSubPubServer(slog.New(slog.NewTextHandler(io.Discard, nil)), subpub.NewSubPub())
+	addr, ca := startTLSService(t, server)
+
+	t.Run("TestTLSNegativeCases_NoClientCert", func(t *testing.T) {
+		conn := dialTLS(t, addr, ca)
+
+		_, err := sprpc.NewPubSubClient(conn).Publish(context.Background(), &sprpc.PublishRequest{Key: "test-channel", Data: "test-message"})
+		assert.Equal(t, codes.Unavailable, status.Code(err), "expected the client without the certificate to be rejected")
+	})
+
+	t.Run("TestTLSNegativeCases_ForeignClientCert", func(t *testing.T) {
+		foreign := newTestCert(t, "foreign-ca", 1, nil)
+		conn := dialTLS(t, addr, ca, newTestCert(t, "test-client", 3, &foreign).tlsCert())
+
+		_, err := sprpc.NewPubSubClient(conn).Publish(context.Background(), &sprpc.PublishRequest{Key: "test-channel", Data: "test-message"})
+		assert.Equal(t, codes.Unavailable, status.Code(err), "expected the client with the foreign certificate to be rejected")
+	})
+
+	t.Run("TestTLSNegativeCases_RequireWithoutCA", func(t *testing.T) {
+		_, err := newCertReloader(server.log, TLSConfig{CertFile: "server.crt", KeyFile: "server.key", RequireClientCert: true})
+		assert.ErrorIs(t, err, ErrTLSConfig, "expected the error of the TLS configuration")
+	})
+}
+
+func TestCertReload(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key")
+
+	ca := newTestCert(t, "test-ca", 1, nil)
+	newTestCert(t, "test-server", 2, &ca).write(t, certFile, keyFile)
+
+	reloader, err := newCertReloader(slog.New(slog.NewTextHandler(io.Discard, nil)), TLSConfig{CertFile: certFile, KeyFile: keyFile})
+	require.NoError(t, err, "expected no error after the reloader's creating")
+
+	serial := func() int64 {
+		conf, err := reloader.config()
+		require.NoError(t, err, "expected no error after the config's getting")
+
+		cert, err := x509.ParseCertificate(conf.Certificates[0].Certificate[0])
+		require.NoError(t, err, "expected no error after the certificate's parsing")
+
+		return cert.SerialNumber.Int64()
+	}
+	// the modification time is moved forward explicitly, because the files may be rewritten within the fs's time granularity.
+	touch := func(offset time.Duration) {
+		at := time.Now().Add(offset)
+		require.NoError(t, os.Chtimes(certFile, at, at), "expected no error after the time's changing")
+		require.NoError(t, os.Chtimes(keyFile, at, at), "expected no error after the time's changing")
+	}
+
+	assert.Equal(t, int64(2), serial(), "expected the initial certificate")
+
+	newTestCert(t, "test-server", 3, &ca).write(t, certFile, keyFile)
+	touch(time.Minute)
+	assert.Equal(t, int64(3), serial(), "expected the rotated certificate to be reloaded")
+
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0600), "expected no error after the key's writing")
+	touch(time.Minute * 2)
+	assert.Equal(t, int64(3), serial(), "expected the previous certificate to be kept while the rotated one is broken")
+
+	assert.ErrorIs(t, reloader.reload(), ErrTLSConfig, "expected the error of the forced reloading of the broken files")
+	assert.Equal(t, int64(3), serial(), "expected the previous certificate to be kept after the failed reloading")
+
+	newTestCert(t, "test-server", 4, &ca).write(t, certFile, keyFile)
+	require.NoError(t, reloader.reload(), "expected no error after the forced reloading")
+	assert.Equal(t, int64(4), serial(), "expected the certificate of the forced reloading")
+}
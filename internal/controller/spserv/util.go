@@ -4,47 +4,70 @@ import (
 	"sync"
 
 	"github.com/MaKcm14/sub-pub/internal/controller/spserv/sprpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 // SPServer defines the common interface for every Sub/Pub server implementation.
 type SPServer interface {
 	sprpc.PubSubServer
 	Close()
+
+	// Admin returns the server of the administrative requests.
+	Admin() sprpc.AdminServer
+
+	// Health returns the standard health checking server.
+	Health() healthpb.HealthServer
 }
 
-type syncMap struct {
-	m   map[int]chan string
+type syncMap[K comparable, V any] struct {
+	m   map[K]V
 	rwm sync.RWMutex
 }
 
-func newSyncMap() syncMap {
-	return syncMap{
-		m: make(map[int]chan string),
+func newSyncMap[K comparable, V any]() syncMap[K, V] {
+	return syncMap[K, V]{
+		m: make(map[K]V),
 	}
 }
 
-func (s *syncMap) Add(key int, ch chan string) {
+func (s *syncMap[K, V]) Add(key K, val V) {
 	s.rwm.Lock()
 	defer s.rwm.Unlock()
 
-	s.m[key] = ch
+	s.m[key] = val
 }
 
-func (s *syncMap) Delete(key int) chan string {
+func (s *syncMap[K, V]) Get(key K) (V, bool) {
+	s.rwm.RLock()
+	defer s.rwm.RUnlock()
+
+	val, ok := s.m[key]
+
+	return val, ok
+}
+
+func (s *syncMap[K, V]) Delete(key K) V {
 	s.rwm.Lock()
 	defer s.rwm.Unlock()
 
-	ch := s.m[key]
+	val := s.m[key]
 	delete(s.m, key)
 
-	return ch
+	return val
 }
 
-func (s *syncMap) Range(f func(ch chan string)) {
+func (s *syncMap[K, V]) Range(f func(val V)) {
 	s.rwm.Lock()
 	defer s.rwm.Unlock()
 
-	for _, ch := range s.m {
-		f(ch)
+	for _, val := range s.m {
+		f(val)
 	}
 }
+
+func (s *syncMap[K, V]) Len() int {
+	s.rwm.RLock()
+	defer s.rwm.RUnlock()
+
+	return len(s.m)
+}
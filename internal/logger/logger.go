@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+var (
+	ErrLogConfig = errors.New("error of the logger's configuration")
+	ErrLogFile   = errors.New("error of the log file")
+)
+
+const (
+	// OutputStdout and OutputStderr define the standard streams' outputs, any other output is the file's path.
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config defines the settings of the service's logger.
+type Config struct {
+	// Output defines the stdout, the stderr or the log file's path.
+	Output string
+
+	// Format defines the text or the json format of the lines.
+	Format string
+
+	// Level defines the min level of the written lines, e.g. the slog.LevelVar that can be changed at runtime.
+	Level slog.Leveler
+
+	// MaxSize defines the size of the log file in bytes after which it's rotated (0 means no limit).
+	MaxSize int64
+
+	// MaxAge defines the age of the log file after which it's rotated (0 means no limit).
+	MaxAge time.Duration
+
+	// MaxBackups defines the count of the kept rotated files (0 means all of them are kept).
+	MaxBackups int
+
+	// Retention defines the age of the rotated files after which they're removed (0 means no limit).
+	Retention time.Duration
+}
+
+// New creates the logger and returns the closer of its output.
+func New(conf Config) (*slog.Logger, io.Closer, error) {
+	const op = "logger.New"
+
+	var (
+		out    io.Writer
+		closer io.Closer = nopCloser{}
+	)
+
+	switch conf.Output {
+	case "", OutputStdout:
+		out = os.Stdout
+	case OutputStderr:
+		out = os.Stderr
+	default:
+		file, err := newRotatingFile(conf.Output, conf.MaxSize, conf.MaxAge, conf.MaxBackups, conf.Retention)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error of the %s: %w", op, err)
+		}
+		out, closer = file, file
+	}
+
+	opts := &slog.HandlerOptions{Level: conf.Level}
+
+	switch conf.Format {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(out, opts)), closer, nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(out, opts)), closer, nil
+	}
+
+	closer.Close()
+	return nil, nil, fmt.Errorf("error of the %s: %w: the format '%s' isn't supported", op, ErrLogConfig, conf.Format)
+}
+
+// nopCloser defines the closer of the standard streams that mustn't be closed.
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testClock defines the manually moved time of the rotating file.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+// backups returns the names of the rotated files of the log.
+func backups(t *testing.T, path string) []string {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err, "expected nil error of the log's dir reading")
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Name() != filepath.Base(path) {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func TestNewPositiveCases(t *testing.T) {
+	t.Run("TestNewPositiveCases_JSONFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "logs", "main.log")
+
+		log, closer, err := New(Config{Output: path, Format: FormatJSON, Level: slog.LevelInfo})
+		require.NoError(t, err, "expected nil error of the logger's creating")
+
+		log.Debug("the hidden line")
+		log.Info("the subscription was started", slog.String("subject", "orders"), slog.Int64("subscription_id", 7))
+		require.NoError(t, closer.Close(), "expected nil error of the log's closing")
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err, "expected nil error of the log's reading")
+
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 1, "expected the lines below the level to be skipped")
+
+		var line map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &line), "expected the json line")
+
+		assert.Equal(t, "the subscription was started", line["msg"], "expected the line's message")
+		assert.Equal(t, "orders", line["subject"], "expected the subject's attribute")
+		assert.Equal(t, float64(7), line["subscription_id"], "expected the subscription's attribute")
+	})
+
+	t.Run("TestNewPositiveCases_StandardStreams", func(t *testing.T) {
+		for _, output := range []string{"", OutputStdout, OutputStderr} {
+			log, closer, err := New(Config{Output: output})
+			require.NoError(t, err, "expected nil error of the logger's creating")
+
+			assert.NotNil(t, log, "expected the logger")
+			assert.NoError(t, closer.Close(), "expected the standard stream's closer to do nothing")
+		}
+	})
+}
+
+func TestNewNegativeCases(t *testing.T) {
+	t.Run("TestNewNegativeCases_UnknownFormat", func(t *testing.T) {
+		_, _, err := New(Config{Format: "xml"})
+		assert.ErrorIs(t, err, ErrLogConfig, "expected the config's error")
+	})
+
+	t.Run("TestNewNegativeCases_NegativeLimits", func(t *testing.T) {
+		_, _, err := New(Config{Output: filepath.Join(t.TempDir(), "main.log"), MaxSize: -1})
+		assert.ErrorIs(t, err, ErrLogConfig, "expected the config's error")
+	})
+}
+
+func TestRotatingFileSize(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)}
+	path := filepath.Join(t.TempDir(), "main.log")
+
+	file, err := newRotatingFile(path, 10, 0, 2, 0)
+	require.NoError(t, err, "expected nil error of the file's creating")
+	defer file.Close()
+	file.now = clock.Now
+
+	for i := 0; i < 4; i++ {
+		_, err := file.Write([]byte("01234567\n"))
+		require.NoError(t, err, "expected nil error of the writing")
+		clock.now = clock.now.Add(time.Second)
+	}
+
+	assert.Len(t, backups(t, path), 2, "expected the backups over the limit to be removed")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "expected nil error of the log's reading")
+	assert.Equal(t, "01234567\n", string(data), "expected only the last line in the current file")
+}
+
+func TestRotatingFileAge(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)}
+	path := filepath.Join(t.TempDir(), "main.log")
+
+	file, err := newRotatingFile(path, 0, time.Hour, 0, 90*time.Minute)
+	require.NoError(t, err, "expected nil error of the file's creating")
+	defer file.Close()
+	file.now = clock.Now
+	file.openedAt = clock.now
+
+	file.Write([]byte("line-0\n"))
+	file.Write([]byte("line-1\n"))
+	assert.Empty(t, backups(t, path), "expected no rotation before the max age")
+
+	clock.now = clock.now.Add(time.Hour)
+	file.Write([]byte("line-2\n"))
+	assert.Len(t, backups(t, path), 1, "expected the rotation after the max age")
+
+	clock.now = clock.now.Add(time.Hour)
+	file.Write([]byte("line-3\n"))
+	assert.Len(t, backups(t, path), 2, "expected the rotation after the max age")
+
+	clock.now = clock.now.Add(time.Hour)
+	file.Write([]byte("line-4\n"))
+	assert.Len(t, backups(t, path), 2, "expected the backups older than the retention to be removed")
+}
+
+func TestRotatingFileRecovery(t *testing.T) {
+	clock := &testClock{now: time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.log")
+
+	file, err := newRotatingFile(path, 10, 0, 0, 0)
+	require.NoError(t, err, "expected nil error of the file's creating")
+	defer file.Close()
+	file.now = clock.Now
+
+	_, err = file.Write([]byte("01234567\n"))
+	require.NoError(t, err, "expected nil error of the writing")
+
+	// the non-empty dir of the backup's name makes the renaming fail
+	backup := filepath.Join(dir, "main-"+clock.now.Format(backupLayout)+".log")
+	require.NoError(t, os.MkdirAll(filepath.Join(backup, "busy"), 0755), "expected nil error of the dir's creating")
+
+	_, err = file.Write([]byte("89\n"))
+	assert.NoError(t, err, "expected the line to be written into the current file after the failed renaming")
+
+	require.NoError(t, os.RemoveAll(backup), "expected nil error of the dir's removing")
+
+	_, err = file.Write([]byte("abc\n"))
+	require.NoError(t, err, "expected nil error of the writing")
+	assert.Len(t, backups(t, path), 1, "expected the rotation to be retried by the next write")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err, "expected nil error of the log's reading")
+	assert.Equal(t, "abc\n", string(data), "expected the logging to go on after the recovery")
+
+	file.mut.Lock()
+	file.file.Close()
+	file.file = nil
+	file.mut.Unlock()
+
+	_, err = file.Write([]byte("def\n"))
+	assert.NoError(t, err, "expected the file to be reopened after the failed opening")
+
+	require.NoError(t, file.Close(), "expected nil error of the closing")
+	_, err = file.Write([]byte("ghi\n"))
+	assert.ErrorIs(t, err, ErrLogFile, "expected the error of the closed file's writing")
+}
@@ -0,0 +1,198 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupLayout defines the time's layout of the rotated files' suffixes.
+const backupLayout = "20060102T150405.000"
+
+// rotatingFile defines the log file that is renamed into the backup after reaching its max size
+// or its max age. The old backups are removed according to the count and the retention's limits.
+type rotatingFile struct {
+	path string
+
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	retention  time.Duration
+
+	// now defines the source of the time of the files' ages.
+	now func() time.Time
+
+	mut sync.Mutex
+
+	// file is nil after the closing or after the failed reopening by the rotation (it's retried by the next write).
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	closed bool
+}
+
+func newRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int, retention time.Duration) (*rotatingFile, error) {
+	if maxSize < 0 || maxAge < 0 || maxBackups < 0 || retention < 0 {
+		return nil, fmt.Errorf("%w: the rotation's limits must be non-negative", ErrLogConfig)
+	}
+
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+		retention:  retention,
+		now:        time.Now,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrLogFile, err)
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the log file for the appending, the age of the existing file is counted from its last modification.
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrLogFile, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("%w: %s", ErrLogFile, err)
+	}
+
+	r.file, r.size, r.openedAt = file, info.Size(), r.now()
+	if info.Size() != 0 {
+		r.openedAt = info.ModTime()
+	}
+
+	return nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	if r.closed {
+		return 0, fmt.Errorf("%w: the file is closed", ErrLogFile)
+	}
+
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.size > 0 && ((r.maxSize > 0 && r.size+int64(len(p)) > r.maxSize) ||
+		(r.maxAge > 0 && r.now().Sub(r.openedAt) >= r.maxAge)) {
+		// the line is written into the current file if only its renaming failed
+		if err := r.rotate(); err != nil && r.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+// rotate renames the current file into the backup, opens the new one and removes the old backups.
+// If the renaming fails, the current file is reopened and the rotation is retried by the next write.
+// Must be called with the mut locked.
+func (r *rotatingFile) rotate() error {
+	err := r.file.Close()
+	r.file = nil
+
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrLogFile, err)
+	}
+
+	ext := filepath.Ext(r.path)
+	backup := fmt.Sprintf("%s-%s%s", strings.TrimSuffix(r.path, ext), r.now().Format(backupLayout), ext)
+
+	if err := os.Rename(r.path, backup); err != nil {
+		openedAt := r.openedAt
+		if openErr := r.open(); openErr != nil {
+			return openErr
+		}
+		r.openedAt = openedAt
+
+		return fmt.Errorf("%w: %s", ErrLogFile, err)
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+	r.openedAt = r.now()
+	r.cleanup()
+
+	return nil
+}
+
+// cleanup removes the backups over the count's limit and the ones older than the retention.
+func (r *rotatingFile) cleanup() {
+	ext := filepath.Ext(r.path)
+	prefix := strings.TrimSuffix(filepath.Base(r.path), ext) + "-"
+
+	entries, err := os.ReadDir(filepath.Dir(r.path))
+	if err != nil {
+		return
+	}
+
+	type backup struct {
+		path string
+		at   time.Time
+	}
+	backups := make([]backup, 0, len(entries))
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+
+		at, err := time.ParseInLocation(backupLayout, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext), time.Local)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(filepath.Dir(r.path), name), at: at})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].at.After(backups[j].at)
+	})
+
+	for i, b := range backups {
+		if (r.maxBackups > 0 && i >= r.maxBackups) || (r.retention > 0 && r.now().Sub(b.at) > r.retention) {
+			os.Remove(b.path)
+		}
+	}
+}
+
+// Close closes the log file.
+func (r *rotatingFile) Close() error {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.closed = true
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+
+	return err
+}
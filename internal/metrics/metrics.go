@@ -0,0 +1,231 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// OtherSubject defines the label of the subjects that exceed the limit of the subjects' labels.
+const OtherSubject = "_other"
+
+// EventKinds defines the kinds of the sub-pub system's events that the Metrics observe.
+var EventKinds = []subpub.EventKind{
+	subpub.EventPublished,
+	subpub.EventDelivered,
+	subpub.EventDropped,
+	subpub.EventExpired,
+	subpub.EventDuplicate,
+}
+
+// Metrics defines the Prometheus metrics of the sub-pub service.
+type Metrics struct {
+	reg *prometheus.Registry
+
+	// subjects bounds the cardinality of the subject's label.
+	subjects *subjectLabels
+
+	published  *prometheus.CounterVec
+	delivered  *prometheus.CounterVec
+	dropped    *prometheus.CounterVec
+	expired    *prometheus.CounterVec
+	duplicates *prometheus.CounterVec
+
+	// handlerLatency defines the durations of the handlers' calls.
+	handlerLatency *prometheus.HistogramVec
+
+	// streams defines the count of the active grpc's streams by the methods.
+	streams *prometheus.GaugeVec
+
+	// requests defines the count of the finished grpc's calls by the methods and the status codes.
+	requests *prometheus.CounterVec
+}
+
+// New creates the metrics where at most the maxSubjects subjects get their own label,
+// the rest of them are counted under the OtherSubject.
+func New(maxSubjects int) *Metrics {
+	m := &Metrics{
+		reg:      prometheus.NewRegistry(),
+		subjects: newSubjectLabels(maxSubjects),
+
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "subpub_messages_published_total",
+			Help: "The count of the messages published into the subjects.",
+		}, []string{"subject"}),
+		delivered: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "subpub_messages_delivered_total",
+			Help: "The count of the messages passed to the subscriptions' handlers.",
+		}, []string{"subject"}),
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "subpub_messages_dropped_total",
+			Help: "The count of the messages dropped because of the queues' overflow, purging or the failed scheduled publishing.",
+		}, []string{"subject"}),
+		expired: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "subpub_messages_expired_total",
+			Help: "The count of the messages discarded because of the TTL.",
+		}, []string{"subject"}),
+		duplicates: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "subpub_messages_duplicate_total",
+			Help: "The count of the messages rejected by the dedup windows.",
+		}, []string{"subject"}),
+
+		handlerLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "subpub_handler_duration_seconds",
+			Help:    "The duration of the subscriptions' handlers' calls.",
+			Buckets: prometheus.ExponentialBuckets(0.0001, 4, 10),
+		}, []string{"subject"}),
+
+		streams: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "subpub_grpc_active_streams",
+			Help: "The count of the active grpc's streams.",
+		}, []string{"method"}),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "subpub_grpc_requests_total",
+			Help: "The count of the finished grpc's calls by their status codes.",
+		}, []string{"method", "code"}),
+	}
+
+	m.reg.MustRegister(
+		m.published, m.delivered, m.dropped, m.expired, m.duplicates,
+		m.handlerLatency, m.streams, m.requests,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Observe counts the sub-pub system's event (see the EventKinds).
+func (m *Metrics) Observe(ev subpub.Event) {
+	subject := m.subjects.label(ev.Subject)
+
+	switch ev.Kind {
+	case subpub.EventPublished:
+		m.published.WithLabelValues(subject).Inc()
+	case subpub.EventDelivered:
+		m.delivered.WithLabelValues(subject).Inc()
+		m.handlerLatency.WithLabelValues(subject).Observe(ev.Latency.Seconds())
+	case subpub.EventDropped:
+		m.dropped.WithLabelValues(subject).Inc()
+	case subpub.EventExpired:
+		m.expired.WithLabelValues(subject).Inc()
+	case subpub.EventDuplicate:
+		m.duplicates.WithLabelValues(subject).Inc()
+	}
+}
+
+// WatchSubPub adds the depths of the sp subjects' queues measured on every scrape.
+func (m *Metrics) WatchSubPub(sp subpub.SubPub) {
+	m.reg.MustRegister(&queueCollector{
+		sp:       sp,
+		subjects: m.subjects,
+		desc: prometheus.NewDesc("subpub_queue_depth",
+			"The count of the messages waiting in the queues of the subjects' subscriptions.",
+			[]string{"subject"}, nil),
+	})
+}
+
+// WatchSubscriptions adds the count of the active subscriptions measured on every scrape.
+func (m *Metrics) WatchSubscriptions(count func() int) {
+	m.reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "subpub_active_subscriptions",
+		Help: "The count of the active remote subscriptions.",
+	}, func() float64 {
+		return float64(count())
+	}))
+}
+
+// UnaryInterceptor defines the interceptor that counts the status codes of the unary calls.
+func (m *Metrics) UnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	m.requests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+
+	return resp, err
+}
+
+// StreamInterceptor defines the interceptor that counts the active streams and their status codes.
+func (m *Metrics) StreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	gauge := m.streams.WithLabelValues(info.FullMethod)
+
+	gauge.Inc()
+	err := handler(srv, stream)
+	gauge.Dec()
+
+	m.requests.WithLabelValues(info.FullMethod, status.Code(err).String()).Inc()
+
+	return err
+}
+
+// Handler returns the handler of the metrics' endpoint.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{Registry: m.reg})
+}
+
+// subjectLabels defines the logic of the subject label's bounding: the first max subjects
+// get their own labels, the rest of them share the OtherSubject.
+type subjectLabels struct {
+	max int
+
+	mut   sync.RWMutex
+	known map[string]struct{}
+}
+
+func newSubjectLabels(max int) *subjectLabels {
+	return &subjectLabels{
+		max:   max,
+		known: make(map[string]struct{}),
+	}
+}
+
+// label returns the label of the subject.
+func (s *subjectLabels) label(subject string) string {
+	s.mut.RLock()
+	_, ok := s.known[subject]
+	s.mut.RUnlock()
+
+	if ok {
+		return subject
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.known[subject]; ok {
+		return subject
+	}
+	if len(s.known) >= s.max {
+		return OtherSubject
+	}
+	s.known[subject] = struct{}{}
+
+	return subject
+}
+
+// queueCollector defines the collector of the subjects' queues' depths.
+type queueCollector struct {
+	sp       subpub.SubPub
+	subjects *subjectLabels
+	desc     *prometheus.Desc
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	depths := make(map[string]uint64)
+	for _, subject := range c.sp.Subjects() {
+		depths[c.subjects.label(subject)] += c.sp.Stats(subject).Pending
+	}
+
+	for subject, depth := range depths {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(depth), subject)
+	}
+}
@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSubjectLabels(t *testing.T) {
+	labels := newSubjectLabels(2)
+
+	assert.Equal(t, "orders", labels.label("orders"), "expected the subject's own label")
+	assert.Equal(t, "billing", labels.label("billing"), "expected the subject's own label")
+	assert.Equal(t, OtherSubject, labels.label("payments"), "expected the subject over the limit to share the label")
+	assert.Equal(t, "orders", labels.label("orders"), "expected the known subject to keep its label")
+}
+
+func TestObserve(t *testing.T) {
+	m := New(1)
+	sp := subpub.NewSubPub(subpub.WithObserver(m, EventKinds...))
+
+	sp.Subscribe("orders", func(interface{}) {})
+	sp.Subscribe("billing", func(interface{}) {})
+
+	sp.Publish("orders", "order-0")
+	sp.Publish("orders", "order-1")
+	sp.Publish("billing", "bill-0")
+
+	require.NoError(t, sp.Close(context.Background()), "expected nil error after closing")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.published.WithLabelValues("orders")), "expected the published messages to be counted")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.published.WithLabelValues(OtherSubject)), "expected the subject over the limit to be counted as the other")
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.delivered.WithLabelValues("orders")), "expected the delivered messages to be counted")
+	assert.Equal(t, 2, testutil.CollectAndCount(m.handlerLatency), "expected the latency of every subject's label")
+}
+
+func TestWatchSubPub(t *testing.T) {
+	m := New(10)
+	sp := subpub.NewSubPub()
+	defer sp.Close(context.Background())
+
+	sub, _ := sp.Subscribe("orders", func(interface{}) {})
+	sub.Pause()
+
+	sp.Publish("orders", "order-0")
+	sp.Publish("orders", "order-1")
+
+	m.WatchSubPub(sp)
+	m.WatchSubscriptions(func() int { return 3 })
+
+	expected := `
+# HELP subpub_queue_depth The count of the messages waiting in the queues of the subjects' subscriptions.
+# TYPE subpub_queue_depth gauge
+subpub_queue_depth{subject="orders"} 2
+# HELP subpub_active_subscriptions The count of the active remote subscriptions.
+# TYPE subpub_active_subscriptions gauge
+subpub_active_subscriptions 3
+`
+	assert.NoError(t, testutil.GatherAndCompare(m.reg, strings.NewReader(expected), "subpub_queue_depth", "subpub_active_subscriptions"),
+		"expected the queues' depths and the subscriptions' count")
+}
+
+func TestInterceptors(t *testing.T) {
+	m := New(10)
+
+	m.UnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/sprpc.PubSub/Publish"},
+		func(context.Context, any) (any, error) {
+			return nil, status.Error(codes.InvalidArgument, "test-error")
+		})
+
+	streamInfo := &grpc.StreamServerInfo{FullMethod: "/sprpc.PubSub/Subscribe"}
+	started, finish := make(chan struct{}), make(chan struct{})
+
+	go m.StreamInterceptor(nil, nil, streamInfo, func(any, grpc.ServerStream) error {
+		close(started)
+		<-finish
+		return errors.New("test-error")
+	})
+	<-started
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.streams.WithLabelValues(streamInfo.FullMethod)), "expected the active stream to be counted")
+	close(finish)
+
+	assert.Eventually(t, func() bool {
+		return testutil.ToFloat64(m.requests.WithLabelValues(streamInfo.FullMethod, codes.Unknown.String())) == 1
+	}, time.Second, time.Millisecond*10, "expected the stream's status code to be counted")
+
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.streams.WithLabelValues(streamInfo.FullMethod)), "expected the finished stream not to be counted")
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues("/sprpc.PubSub/Publish", codes.InvalidArgument.String())),
+		"expected the unary call's status code to be counted")
+}
+
+func TestHandler(t *testing.T) {
+	m := New(10)
+	m.Observe(subpub.Event{Kind: subpub.EventPublished, Subject: "orders"})
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	body, _ := io.ReadAll(rec.Body)
+	assert.Contains(t, string(body), `subpub_messages_published_total{subject="orders"} 1`, "expected the metrics in the exposition format")
+}
@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ErrNetOpenConn is returned when the metrics' socket can't be opened.
+var ErrNetOpenConn = errors.New("error of opening the metrics' connection")
+
+// Server defines the local HTTP server of the metrics' endpoint.
+type Server struct {
+	httpServ *http.Server
+	conn     net.Listener
+	log      *slog.Logger
+}
+
+func NewServer(log *slog.Logger, socket string, m *Metrics) (*Server, error) {
+	const op = "metrics.NewServer"
+
+	log.Info("opening the metrics' connection", slog.String("socket", socket))
+	lis, err := net.Listen("tcp", socket)
+
+	if err != nil {
+		return nil, fmt.Errorf("error of the %s: %w: %s", op, ErrNetOpenConn, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+
+	return &Server{
+		httpServ: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: time.Second * 5,
+		},
+		conn: lis,
+		log:  log,
+	}, nil
+}
+
+// Run starts the serving of the metrics' requests.
+func (s *Server) Run() {
+	s.log.Info("starting the metrics' server")
+
+	if err := s.httpServ.Serve(s.conn); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.log.Error("the metrics' server failed", slog.String("op", "metrics.Server.Run"), slog.Any("error", err))
+	}
+}
+
+// Close stops the metrics' server.
+func (s *Server) Close(ctx context.Context) error {
+	return s.httpServ.Shutdown(ctx)
+}
@@ -0,0 +1,493 @@
+package subpub
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAccount defines the account of the clients that don't belong to any configured account.
+const DefaultAccount = "default"
+
+// accountSep separates the account's name and the subject in the shared system's subjects.
+const accountSep = ":"
+
+// AccountLimits defines the limits of the account's usage, the zero value means the absence of the limit.
+type AccountLimits struct {
+	// MaxSubjects defines the max count of the account's subjects with the active subscriptions.
+	MaxSubjects int
+
+	// MaxSubscriptions defines the max count of the account's active subscriptions.
+	MaxSubscriptions int
+
+	// MsgRate defines the count of the messages per second that the account may publish.
+	MsgRate float64
+
+	// MsgBurst defines the max count of the messages published at once (the MsgRate is used if it's less).
+	MsgBurst int
+}
+
+// Import defines the subject exported by the other account.
+type Import struct {
+	Account string
+	Subject string
+
+	// As defines the local name of the imported subject (the Subject is used if it's empty).
+	As string
+}
+
+// Account defines the isolated namespace of the subjects with its own limits.
+type Account struct {
+	Name   string
+	Limits AccountLimits
+
+	// Exports defines the subjects that the other accounts may import.
+	Exports []string
+
+	// Imports defines the subjects of the other accounts available for the subscribing.
+	Imports []Import
+}
+
+// Accounts defines the router that isolates the subjects of the accounts sharing the single SubPub.
+type Accounts struct {
+	views map[string]*accountSubPub
+}
+
+// NewAccounts creates the accounts over the sp, the clock is used by the rate's limits.
+// The DefaultAccount without the limits is added if it isn't configured.
+func NewAccounts(sp SubPub, clock Clock, accounts ...Account) (*Accounts, error) {
+	const op = "subpub.NewAccounts"
+
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	a := &Accounts{
+		views: make(map[string]*accountSubPub, len(accounts)+1),
+	}
+	exports := make(map[string]map[string]struct{}, len(accounts))
+
+	for _, acc := range accounts {
+		if acc.Name == "" || strings.Contains(acc.Name, accountSep) {
+			return nil, fmt.Errorf("error of the %s: %w: the account's name must be non-empty and mustn't contain the '%s'", op, ErrInputData, accountSep)
+		}
+		if _, ok := a.views[acc.Name]; ok {
+			return nil, fmt.Errorf("error of the %s: %w: the account '%s' is duplicated", op, ErrInputData, acc.Name)
+		}
+
+		a.views[acc.Name] = newAccountSubPub(sp, clock, acc)
+
+		exports[acc.Name] = make(map[string]struct{}, len(acc.Exports))
+		for _, subject := range acc.Exports {
+			exports[acc.Name][subject] = struct{}{}
+		}
+	}
+
+	if _, ok := a.views[DefaultAccount]; !ok {
+		a.views[DefaultAccount] = newAccountSubPub(sp, clock, Account{Name: DefaultAccount})
+	}
+
+	for _, acc := range accounts {
+		view := a.views[acc.Name]
+
+		for _, imp := range acc.Imports {
+			if _, ok := exports[imp.Account][imp.Subject]; !ok || imp.Account == acc.Name {
+				return nil, fmt.Errorf("error of the %s: %w: the '%s' isn't exported by the account '%s' to the '%s'",
+					op, ErrInputData, imp.Subject, imp.Account, acc.Name)
+			}
+
+			local := imp.As
+			if local == "" {
+				local = imp.Subject
+			}
+			if _, ok := view.imports[local]; ok {
+				return nil, fmt.Errorf("error of the %s: %w: the import '%s' of the account '%s' is duplicated", op, ErrInputData, local, acc.Name)
+			}
+
+			view.imports[local] = imp.Account + accountSep + imp.Subject
+		}
+	}
+
+	return a, nil
+}
+
+// Account returns the SubPub of the account's namespace.
+// Closing the account's SubPub doesn't close the shared system.
+func (a *Accounts) Account(name string) (SubPub, error) {
+	const op = "subpub.Accounts.Account"
+
+	view, ok := a.views[name]
+	if !ok {
+		return nil, fmt.Errorf("error of the %s: %w: the account '%s' doesn't exist", op, ErrInputData, name)
+	}
+	return view, nil
+}
+
+// SetLimits changes the limits of the account at runtime: the active subscriptions over
+// the new limits are kept, but the new ones aren't accepted untill the usage is lower.
+func (a *Accounts) SetLimits(name string, limits AccountLimits) error {
+	const op = "subpub.Accounts.SetLimits"
+
+	view, ok := a.views[name]
+	if !ok {
+		return fmt.Errorf("error of the %s: %w: the account '%s' doesn't exist", op, ErrInputData, name)
+	}
+	view.setLimits(limits)
+
+	return nil
+}
+
+// Resolve returns the shared system's name of the subject available for the account's subscribing:
+// the imported subjects are resolved to the exporters' ones.
+func (a *Accounts) Resolve(name, subject string) (string, error) {
+	const op = "subpub.Accounts.Resolve"
+
+	view, ok := a.views[name]
+	if !ok {
+		return "", fmt.Errorf("error of the %s: %w: the account '%s' doesn't exist", op, ErrInputData, name)
+	}
+	return view.resolve(subject), nil
+}
+
+// AccountSubject returns the shared system's name of the account's subject.
+func AccountSubject(account, subject string) string {
+	return account + accountSep + subject
+}
+
+// accountSubPub defines the SubPub of the single account: its subjects are prefixed with the account's name.
+type accountSubPub struct {
+	sp     SubPub
+	clock  Clock
+	name   string
+	prefix string
+
+	// imports stores the shared system's subjects of the imports by their local names.
+	imports map[string]string
+
+	mut sync.Mutex
+
+	limits AccountLimits
+
+	// rate defines the limiter of the publishing (nil means no limit).
+	rate *tokenBucket
+
+	// subs defines the count of the active subscriptions.
+	subs int
+
+	// subjects stores the counts of the active subscriptions by the local subjects.
+	subjects map[string]int
+}
+
+func newAccountSubPub(sp SubPub, clock Clock, acc Account) *accountSubPub {
+	view := &accountSubPub{
+		sp:       sp,
+		clock:    clock,
+		name:     acc.Name,
+		prefix:   acc.Name + accountSep,
+		imports:  make(map[string]string, len(acc.Imports)),
+		subjects: make(map[string]int),
+	}
+	view.setLimits(acc.Limits)
+
+	return view
+}
+
+// setLimits sets the limits of the account, the rate's limiter is recreated if the rate is changed.
+func (a *accountSubPub) setLimits(limits AccountLimits) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if limits.MsgRate <= 0 {
+		a.rate = nil
+	} else if a.rate == nil || limits.MsgRate != a.limits.MsgRate || limits.MsgBurst != a.limits.MsgBurst {
+		a.rate = newTokenBucket(a.clock, limits.MsgRate, limits.MsgBurst)
+	}
+	a.limits = limits
+}
+
+// resolve returns the shared system's subject of the subject available for the subscribing.
+func (a *accountSubPub) resolve(subject string) string {
+	if imported, ok := a.imports[subject]; ok {
+		return imported
+	}
+	return a.prefix + subject
+}
+
+// own returns the shared system's subject of the account's own subject.
+func (a *accountSubPub) own(op, subject string) (string, error) {
+	if subject == "" {
+		return "", fmt.Errorf("error of the %s: %w: the subject is empty", op, ErrInputData)
+	}
+
+	if _, ok := a.imports[subject]; ok {
+		return "", fmt.Errorf("error of the %s: %w: the imported subject '%s' is read-only", op, ErrInputData, subject)
+	}
+	return a.prefix + subject, nil
+}
+
+// publishable checks the rate's limit for the n messages.
+func (a *accountSubPub) publishable(op string, n int) error {
+	a.mut.Lock()
+	rate := a.rate
+	a.mut.Unlock()
+
+	if rate == nil {
+		return nil
+	}
+
+	if wait, ok := rate.take(n); !ok {
+		return fmt.Errorf("error of the %s: %w", op, &RateLimitError{Limit: fmt.Sprintf("account '%s'", a.name), RetryAfter: wait})
+	}
+	return nil
+}
+
+// acquire checks the subscriptions' limits and returns the func that releases the subscription's place.
+func (a *accountSubPub) acquire(op, subject string) (func(), error) {
+	if subject == "" {
+		return nil, fmt.Errorf("error of the %s: %w: the subject is empty", op, ErrInputData)
+	}
+
+	a.mut.Lock()
+	defer a.mut.Unlock()
+
+	if a.limits.MaxSubscriptions > 0 && a.subs >= a.limits.MaxSubscriptions {
+		return nil, fmt.Errorf("error of the %s: %w: the subscriptions' count of the account '%s' was exceeded", op, ErrLimit, a.name)
+	}
+
+	if _, ok := a.subjects[subject]; !ok && a.limits.MaxSubjects > 0 && len(a.subjects) >= a.limits.MaxSubjects {
+		return nil, fmt.Errorf("error of the %s: %w: the subjects' count of the account '%s' was exceeded", op, ErrLimit, a.name)
+	}
+
+	a.subs++
+	a.subjects[subject]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.mut.Lock()
+			defer a.mut.Unlock()
+
+			a.subs--
+			if a.subjects[subject]--; a.subjects[subject] == 0 {
+				delete(a.subjects, subject)
+			}
+		})
+	}, nil
+}
+
+// accountSub defines the subscription that releases its place in the account's limits.
+type accountSub struct {
+	Subscription
+	release func()
+}
+
+func (s *accountSub) Unsubscribe() {
+	s.Subscription.Unsubscribe()
+	s.release()
+}
+
+func (a *accountSubPub) Subscribe(subject string, cb MessageHandler, opts ...SubscribeOpt) (Subscription, error) {
+	release, err := a.acquire("subpub.accountSubPub.Subscribe", subject)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := a.sp.Subscribe(a.resolve(subject), cb, opts...)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	return &accountSub{Subscription: sub, release: release}, nil
+}
+
+func (a *accountSubPub) SubscribeChan(subject string, buf int) (<-chan Message, Subscription, error) {
+	release, err := a.acquire("subpub.accountSubPub.SubscribeChan", subject)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ch, sub, err := a.sp.SubscribeChan(a.resolve(subject), buf)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+	return ch, &accountSub{Subscription: sub, release: release}, nil
+}
+
+func (a *accountSubPub) Messages(ctx context.Context, subject string) (iter.Seq[Message], error) {
+	release, err := a.acquire("subpub.accountSubPub.Messages", subject)
+	if err != nil {
+		return nil, err
+	}
+
+	seq, err := a.sp.Messages(ctx, a.resolve(subject))
+	if err != nil {
+		release()
+		return nil, err
+	}
+	context.AfterFunc(ctx, release)
+
+	return func(yield func(Message) bool) {
+		defer release()
+		seq(yield)
+	}, nil
+}
+
+func (a *accountSubPub) Publish(subject string, msg interface{}, opts ...PublishOpt) error {
+	const op = "subpub.accountSubPub.Publish"
+
+	own, err := a.own(op, subject)
+	if err != nil {
+		return err
+	}
+
+	if err := a.publishable(op, 1); err != nil {
+		return err
+	}
+	return a.sp.Publish(own, msg, opts...)
+}
+
+// ownTx returns the copy of the transaction's messages with the shared system's subjects.
+func (a *accountSubPub) ownTx(op string, msgs []SubjectMessage) ([]SubjectMessage, error) {
+	owned := make([]SubjectMessage, 0, len(msgs))
+
+	for _, msg := range msgs {
+		own, err := a.own(op, msg.Subject)
+		if err != nil {
+			return nil, err
+		}
+
+		msg.Subject = own
+		owned = append(owned, msg)
+	}
+
+	if err := a.publishable(op, len(msgs)); err != nil {
+		return nil, err
+	}
+	return owned, nil
+}
+
+func (a *accountSubPub) PublishTx(ctx context.Context, msgs []SubjectMessage) error {
+	owned, err := a.ownTx("subpub.accountSubPub.PublishTx", msgs)
+	if err != nil {
+		return err
+	}
+	return a.sp.PublishTx(ctx, owned)
+}
+
+func (a *accountSubPub) PublishTxAt(ctx context.Context, msgs []SubjectMessage, at time.Time) (string, error) {
+	owned, err := a.ownTx("subpub.accountSubPub.PublishTxAt", msgs)
+	if err != nil {
+		return "", err
+	}
+	return a.sp.PublishTxAt(ctx, owned, at)
+}
+
+func (a *accountSubPub) PublishAt(subject string, msg interface{}, at time.Time, opts ...PublishOpt) (string, error) {
+	const op = "subpub.accountSubPub.PublishAt"
+
+	own, err := a.own(op, subject)
+	if err != nil {
+		return "", err
+	}
+
+	if err := a.publishable(op, 1); err != nil {
+		return "", err
+	}
+	return a.sp.PublishAt(own, msg, at, opts...)
+}
+
+func (a *accountSubPub) PublishAfter(subject string, msg interface{}, delay time.Duration, opts ...PublishOpt) (string, error) {
+	const op = "subpub.accountSubPub.PublishAfter"
+
+	own, err := a.own(op, subject)
+	if err != nil {
+		return "", err
+	}
+
+	if err := a.publishable(op, 1); err != nil {
+		return "", err
+	}
+	return a.sp.PublishAfter(own, msg, delay, opts...)
+}
+
+// Scheduled returns the account's scheduled messages with the local subjects.
+func (a *accountSubPub) Scheduled() []ScheduledMessage {
+	all := a.sp.Scheduled()
+	scheduled := make([]ScheduledMessage, 0, len(all))
+
+	for _, msg := range all {
+		if subject, ok := strings.CutPrefix(msg.Subject, a.prefix); ok {
+			msg.Subject = subject
+			scheduled = append(scheduled, msg)
+		}
+	}
+	return scheduled
+}
+
+// CancelScheduled cancels only the account's own scheduled messages.
+func (a *accountSubPub) CancelScheduled(id string) error {
+	const op = "subpub.accountSubPub.CancelScheduled"
+
+	for _, msg := range a.Scheduled() {
+		if msg.ID == id || msg.TxID == id {
+			return a.sp.CancelScheduled(id)
+		}
+	}
+	return fmt.Errorf("error of the %s: %w: the scheduled message '%s' doesn't exist", op, ErrInputData, id)
+}
+
+// Subjects returns the account's own subjects with the active subscriptions.
+func (a *accountSubPub) Subjects() []string {
+	all := a.sp.Subjects()
+	subjects := make([]string, 0, len(all))
+
+	for _, subject := range all {
+		if local, ok := strings.CutPrefix(subject, a.prefix); ok {
+			subjects = append(subjects, local)
+		}
+	}
+	return subjects
+}
+
+func (a *accountSubPub) Purge(subject string) int {
+	own, err := a.own("subpub.accountSubPub.Purge", subject)
+	if err != nil {
+		return 0
+	}
+	return a.sp.Purge(own)
+}
+
+func (a *accountSubPub) Stats(subject string) SubjectStats {
+	return a.sp.Stats(a.resolve(subject))
+}
+
+// SetPolicy changes the policy of the account's own subject, the default policy is shared by all the accounts.
+func (a *accountSubPub) SetPolicy(subject string, policy SubjectPolicy) error {
+	const op = "subpub.SetPolicy"
+
+	own, err := a.own(op, subject)
+	if err != nil {
+		return err
+	}
+	return a.sp.SetPolicy(own, policy)
+}
+
+// ResetPolicy removes the own policy of the account's subject.
+func (a *accountSubPub) ResetPolicy(subject string) error {
+	const op = "subpub.ResetPolicy"
+
+	own, err := a.own(op, subject)
+	if err != nil {
+		return err
+	}
+	return a.sp.ResetPolicy(own)
+}
+
+// Close does nothing: the shared system is closed by its owner.
+func (a *accountSubPub) Close(context.Context) error {
+	return nil
+}
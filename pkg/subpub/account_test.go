@@ -0,0 +1,209 @@
+package subpub
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccounts(t *testing.T) {
+	t.Run("TestAccountsPositiveCases_Isolation",
+		func(t *testing.T) {
+			var (
+				mut    sync.Mutex
+				queues = make(map[string][]string)
+			)
+			e := newEventChannel()
+
+			accounts, err := NewAccounts(e, nil, Account{Name: "team-a"}, Account{Name: "team-b"})
+			require.NoError(t, err, "expected nil error after the accounts' creating")
+
+			teamA, _ := accounts.Account("team-a")
+			teamB, _ := accounts.Account("team-b")
+
+			for name, sp := range map[string]SubPub{"team-a": teamA, "team-b": teamB} {
+				sp.Subscribe("orders", func(msg interface{}) {
+					mut.Lock()
+					defer mut.Unlock()
+
+					queues[name] = append(queues[name], msg.(string))
+				})
+			}
+
+			assert.NoError(t, teamA.Publish("orders", "order-a"), "expected nil error after the publishing")
+			assert.NoError(t, teamB.Publish("orders", "order-b"), "expected nil error after the publishing")
+
+			assert.Equal(t, []string{"orders"}, teamA.Subjects(), "expected the local subjects' names")
+			assert.Equal(t, []string{"team-a:orders", "team-b:orders"}, e.Subjects(), "expected the subjects to be prefixed in the shared system")
+
+			e.Close(context.Background())
+			assert.Equal(t, []string{"order-a"}, queues["team-a"], "expected the account to receive only its own messages")
+			assert.Equal(t, []string{"order-b"}, queues["team-b"], "expected the account to receive only its own messages")
+		})
+
+	t.Run("TestAccountsPositiveCases_Import",
+		func(t *testing.T) {
+			var (
+				mut   sync.Mutex
+				queue = make([]string, 0, 1)
+			)
+			e := newEventChannel()
+
+			accounts, err := NewAccounts(e, nil,
+				Account{Name: "team-a", Exports: []string{"orders"}},
+				Account{Name: "team-b", Imports: []Import{{Account: "team-a", Subject: "orders", As: "a-orders"}}},
+			)
+			require.NoError(t, err, "expected nil error after the accounts' creating")
+
+			teamA, _ := accounts.Account("team-a")
+			teamB, _ := accounts.Account("team-b")
+
+			_, err = teamB.Subscribe("a-orders", func(msg interface{}) {
+				mut.Lock()
+				defer mut.Unlock()
+
+				queue = append(queue, msg.(string))
+			})
+			assert.NoError(t, err, "expected nil error after the subscribing on the import")
+
+			assert.NoError(t, teamA.Publish("orders", "order-0"), "expected nil error after the publishing")
+			assert.ErrorIs(t, teamB.Publish("a-orders", "order-1"), ErrInputData, "expected the imported subject to be read-only")
+
+			e.Close(context.Background())
+			assert.Equal(t, []string{"order-0"}, queue, "expected the exported messages to be delivered to the importer")
+		})
+
+	t.Run("TestAccountsPositiveCases_Scheduled",
+		func(t *testing.T) {
+			clock := newFakeClock(time.Now())
+			e := newEventChannel(WithClock(clock))
+			defer e.Close(context.Background())
+
+			accounts, _ := NewAccounts(e, clock, Account{Name: "team-a"}, Account{Name: "team-b"})
+			teamA, _ := accounts.Account("team-a")
+			teamB, _ := accounts.Account("team-b")
+
+			id, err := teamA.PublishAfter("orders", "order-0", time.Minute)
+			assert.NoError(t, err, "expected nil error after the scheduling")
+
+			require.Len(t, teamA.Scheduled(), 1, "expected the account's scheduled message")
+			assert.Equal(t, "orders", teamA.Scheduled()[0].Subject, "expected the local subject's name")
+			assert.Empty(t, teamB.Scheduled(), "expected the other account's messages to be invisible")
+
+			assert.ErrorIs(t, teamB.CancelScheduled(id), ErrInputData, "expected the other account's message not to be canceled")
+			assert.NoError(t, teamA.CancelScheduled(id), "expected the own message to be canceled")
+		})
+
+	t.Run("TestAccountsNegativeCases_Config",
+		func(t *testing.T) {
+			e := newEventChannel()
+			defer e.Close(context.Background())
+
+			_, err := NewAccounts(e, nil, Account{Name: "team:a"})
+			assert.ErrorIs(t, err, ErrInputData, "expected the error of the account's name")
+
+			_, err = NewAccounts(e, nil, Account{Name: "team-a"}, Account{Name: "team-a"})
+			assert.ErrorIs(t, err, ErrInputData, "expected the error of the duplicated account")
+
+			_, err = NewAccounts(e, nil,
+				Account{Name: "team-a"},
+				Account{Name: "team-b", Imports: []Import{{Account: "team-a", Subject: "orders"}}},
+			)
+			assert.ErrorIs(t, err, ErrInputData, "expected the error of the not exported subject's import")
+
+			accounts, _ := NewAccounts(e, nil)
+			_, err = accounts.Account(DefaultAccount)
+			assert.NoError(t, err, "expected the default account to exist")
+
+			_, err = accounts.Account("team-c")
+			assert.ErrorIs(t, err, ErrInputData, "expected the error of the unknown account")
+		})
+}
+
+func TestAccountLimits(t *testing.T) {
+	t.Run("TestAccountLimitsNegativeCases_Subscriptions",
+		func(t *testing.T) {
+			e := newEventChannel()
+			defer e.Close(context.Background())
+
+			accounts, _ := NewAccounts(e, nil, Account{Name: "team-a", Limits: AccountLimits{MaxSubjects: 2, MaxSubscriptions: 3}})
+			teamA, _ := accounts.Account("team-a")
+			handler := func(interface{}) {}
+
+			sub, err := teamA.Subscribe("orders", handler)
+			assert.NoError(t, err, "expected nil error after the subscribing")
+			_, err = teamA.Subscribe("billing", handler)
+			assert.NoError(t, err, "expected nil error after the subscribing")
+
+			_, err = teamA.Subscribe("payments", handler)
+			assert.ErrorIs(t, err, ErrLimit, "expected the error of the subjects' limit")
+
+			_, err = teamA.Subscribe("orders", handler)
+			assert.NoError(t, err, "expected the subscription on the existing subject")
+
+			_, err = teamA.Subscribe("billing", handler)
+			assert.ErrorIs(t, err, ErrLimit, "expected the error of the subscriptions' limit")
+
+			sub.Unsubscribe()
+			sub.Unsubscribe()
+
+			_, err = teamA.Subscribe("billing", handler)
+			assert.NoError(t, err, "expected the released place to be reused")
+		})
+
+	t.Run("TestAccountLimitsNegativeCases_MsgRate",
+		func(t *testing.T) {
+			clock := newFakeClock(time.Now())
+			e := newEventChannel()
+			defer e.Close(context.Background())
+
+			accounts, _ := NewAccounts(e, clock, Account{Name: "team-a", Limits: AccountLimits{MsgRate: 2}})
+			teamA, _ := accounts.Account("team-a")
+			teamA.Subscribe("orders", func(interface{}) {})
+
+			assert.NoError(t, teamA.Publish("orders", "order-0"), "expected nil error inside the burst")
+			assert.NoError(t, teamA.Publish("orders", "order-1"), "expected nil error inside the burst")
+			assert.ErrorIs(t, teamA.Publish("orders", "order-2"), ErrLimit, "expected the error of the rate's limit")
+
+			clock.Advance(time.Millisecond * 500)
+			assert.NoError(t, teamA.Publish("orders", "order-3"), "expected the tokens to be refilled with the time")
+
+			err := teamA.PublishTx(context.Background(), []SubjectMessage{
+				{Subject: "orders", Msg: "order-4"},
+				{Subject: "orders", Msg: "order-5"},
+			})
+			assert.ErrorIs(t, err, ErrLimit, "expected the transaction to take the token for every message")
+		})
+
+	t.Run("TestAccountLimitsPositiveCases_SetLimits",
+		func(t *testing.T) {
+			clock := newFakeClock(time.Now())
+			e := newEventChannel()
+			defer e.Close(context.Background())
+
+			accounts, _ := NewAccounts(e, clock, Account{Name: "team-a", Limits: AccountLimits{MsgRate: 1, MaxSubscriptions: 1}})
+			teamA, _ := accounts.Account("team-a")
+			teamA.Subscribe("orders", func(interface{}) {})
+
+			assert.NoError(t, teamA.Publish("orders", "order-0"), "expected nil error inside the burst")
+			assert.ErrorIs(t, teamA.Publish("orders", "order-1"), ErrLimit, "expected the error of the rate's limit")
+
+			_, err := teamA.Subscribe("billing", func(interface{}) {})
+			assert.ErrorIs(t, err, ErrLimit, "expected the error of the subscriptions' limit")
+
+			assert.NoError(t, accounts.SetLimits("team-a", AccountLimits{MsgRate: 2, MaxSubscriptions: 2}),
+				"expected nil error of the limits' changing")
+
+			assert.NoError(t, teamA.Publish("orders", "order-2"), "expected the new rate's burst")
+			assert.NoError(t, teamA.Publish("orders", "order-3"), "expected the new rate's burst")
+
+			_, err = teamA.Subscribe("billing", func(interface{}) {})
+			assert.NoError(t, err, "expected the subscription inside the new limit")
+
+			assert.ErrorIs(t, accounts.SetLimits("team-b", AccountLimits{}), ErrInputData, "expected the error of the unknown account")
+		})
+}
@@ -3,8 +3,28 @@ package subpub
 import (
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
+// envelope defines the message that waits for the handling in the subscription's queue.
+type envelope struct {
+	msg interface{}
+
+	// key defines the ordering key of the message in the KeyOrdered mode.
+	key string
+
+	// expiresAt defines the time after which the message is discarded (zero means never).
+	expiresAt time.Time
+
+	// priority defines the message's priority level: the higher levels are handled first.
+	priority int
+}
+
+// expired defines whether the message's TTL elapsed by the now.
+func (e envelope) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
 // channelSub defines the logic of the channel's definite subscription.
 type channelSub struct {
 	// handler defines the logic of message's handling after the publisher's publishing.
@@ -13,17 +33,280 @@ type channelSub struct {
 	// flagSub defines whether the current subscription is still active.
 	flagSub atomic.Bool
 
-	// mut defines the logic of channels swap synchronization.
+	// opts defines the order of the messages' handling.
+	opts subOpts
+
+	// mut defines the logic of the queue's synchronization.
 	mut sync.Mutex
 
-	// done defines the readiness of the last goroutine to hand over the flow of control to the next one
-	// to observe the FIFO order.
-	done chan bool
+	// queue defines the messages that wait for the handling ordered by their priority
+	// and by the order of their publishing within the same priority.
+	queue []envelope
+
+	// inflight defines the count of the currently running handler's calls.
+	inflight int
+
+	// busyKeys defines the keys of the currently handled messages in the KeyOrdered mode.
+	busyKeys map[string]struct{}
+
+	// paused defines whether the handling of the queue's messages is suspended.
+	paused bool
+
+	// held defines whether the handling is suspended untill the wake call,
+	// so the messages of the transaction become visible together.
+	held bool
+
+	// subject defines the subject the subscription is for.
+	subject string
+
+	// bus defines the event channel the subscription belongs to.
+	bus *eventChannel
+
+	// stats defines the counters of the subject's messages.
+	stats *subjectStats
 }
 
 // Unsubscribe defines the logic of the subscription's refusing.
+// The messages that weren't passed to the handler yet are dropped.
 func (c *channelSub) Unsubscribe() {
 	c.flagSub.Store(false)
+
+	c.mut.Lock()
+	c.queue = nil
+	c.mut.Unlock()
+
+	if c.bus != nil {
+		c.bus.release(c.subject)
+	}
+}
+
+// purge drops the messages waiting in the queue and returns their count.
+func (c *channelSub) purge() int {
+	c.mut.Lock()
+	queue := c.queue
+	c.queue = nil
+	c.mut.Unlock()
+
+	for _, env := range queue {
+		c.drop(env)
+	}
+	return len(queue)
+}
+
+// Pause suspends the handling of the messages: they are buffered in the queue
+// up to the subscription's capacity untill the Resume call.
+func (c *channelSub) Pause() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.paused = true
+}
+
+// Resume continues the handling of the messages buffered during the pause.
+func (c *channelSub) Resume() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.paused = false
+	c.dispatch()
+}
+
+// enqueue puts the env into the subscription's queue behind the messages of the same or higher priority
+// and starts its handling if it's possible.
+// On the queue's overflow one of the messages is dropped according to the overflow policy:
+// the DropOldest drops the oldest message of the lowest priority.
+func (c *channelSub) enqueue(env envelope) {
+	if dropped, ok := c.push(env, true); ok {
+		c.drop(dropped)
+	}
+}
+
+// push puts the env into the subscription's queue as the enqueue does
+// and returns the message dropped because of the overflow without its reporting.
+// The handling isn't started untill the wake call if the dispatch is false:
+// the running handlers don't take the queue's messages either.
+func (c *channelSub) push(env envelope, dispatch bool) (envelope, bool) {
+	if c.opts.mode == KeyOrdered {
+		env.key = c.opts.key(env.msg)
+	}
+
+	c.mut.Lock()
+	if !dispatch {
+		c.held = true
+	}
+
+	if c.opts.capacity != 0 && len(c.queue) >= c.opts.capacity {
+		dropped := env
+		if c.opts.overflow == DropOldest {
+			c.insert(env)
+
+			i := c.lowestLevel()
+			dropped = c.queue[i]
+			c.queue = append(c.queue[:i], c.queue[i+1:]...)
+		}
+		if dispatch {
+			c.dispatch()
+		}
+		c.mut.Unlock()
+
+		return dropped, true
+	}
+
+	c.insert(env)
+	if dispatch {
+		c.dispatch()
+	}
+
+	c.mut.Unlock()
+
+	return envelope{}, false
+}
+
+// wake starts the handling of the messages put by the push without the dispatching.
+func (c *channelSub) wake() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.held = false
+	c.dispatch()
+}
+
+// insert puts the env into the queue after the last message with the same or higher priority.
+// Must be called with the mut locked.
+func (c *channelSub) insert(env envelope) {
+	i := len(c.queue)
+	for i > 0 && c.queue[i-1].priority < env.priority {
+		i--
+	}
+
+	c.queue = append(c.queue, envelope{})
+	copy(c.queue[i+1:], c.queue[i:])
+	c.queue[i] = env
+}
+
+// lowestLevel returns the index of the oldest message of the lowest priority in the non-empty queue.
+// Must be called with the mut locked.
+func (c *channelSub) lowestLevel() int {
+	i := len(c.queue) - 1
+	for i > 0 && c.queue[i-1].priority == c.queue[i].priority {
+		i--
+	}
+	return i
+}
+
+// drop counts and reports the message dropped because of the queue's overflow.
+func (c *channelSub) drop(env envelope) {
+	if c.bus == nil {
+		return
+	}
+	c.stats.dropped.Add(1)
+	c.bus.report(Event{Kind: EventDropped, Subject: c.subject, Msg: env.msg})
+}
+
+// dispatch starts the goroutines for every message that may be handled at the moment.
+// Must be called with the mut locked.
+func (c *channelSub) dispatch() {
+	for {
+		env, ok := c.take()
+		if !ok {
+			return
+		}
+		c.bus.wg.Add(1)
+		go c.run(env)
+	}
+}
+
+// take extracts the next message that may be handled at the moment according to the order mode.
+// Must be called with the mut locked.
+func (c *channelSub) take() (envelope, bool) {
+	if !c.flagSub.Load() || c.paused || c.held || (c.opts.limit != 0 && c.inflight >= c.opts.limit) {
+		return envelope{}, false
+	}
+
+	for i, env := range c.queue {
+		if c.opts.mode == KeyOrdered {
+			if _, ok := c.busyKeys[env.key]; ok {
+				continue
+			}
+			if c.busyKeys == nil {
+				c.busyKeys = make(map[string]struct{})
+			}
+			c.busyKeys[env.key] = struct{}{}
+		}
+
+		c.queue = append(c.queue[:i], c.queue[i+1:]...)
+		c.inflight++
+
+		return env, true
+	}
+	return envelope{}, false
+}
+
+// release marks the handling of the env as finished.
+// Must be called with the mut locked.
+func (c *channelSub) release(env envelope) {
+	c.inflight--
+	if c.opts.mode == KeyOrdered {
+		delete(c.busyKeys, env.key)
+	}
+}
+
+// run handles the env and the next messages that become available after its handling.
+// The expired messages are discarded instead of the handling.
+func (c *channelSub) run(env envelope) {
+	defer c.bus.wg.Done()
+
+	for {
+		if env.expired(c.bus.clock.Now()) {
+			c.stats.expired.Add(1)
+			c.bus.report(Event{Kind: EventExpired, Subject: c.subject, Msg: env.msg})
+		} else {
+			c.deliver(env.msg)
+		}
+
+		c.mut.Lock()
+		c.release(env)
+		next, ok := c.take()
+		c.dispatch()
+		c.mut.Unlock()
+
+		if !ok {
+			return
+		}
+		env = next
+	}
+}
+
+// deliver calls the handler with the msg counting and reporting the delivery.
+func (c *channelSub) deliver(msg interface{}) {
+	if !c.bus.observes(EventDelivered) {
+		c.call(msg)
+		c.stats.delivered.Add(1)
+
+		return
+	}
+
+	start := c.bus.clock.Now()
+	c.call(msg)
+	c.stats.delivered.Add(1)
+
+	c.bus.report(Event{Kind: EventDelivered, Subject: c.subject, Msg: msg, Latency: c.bus.clock.Now().Sub(start)})
+}
+
+// pending returns the count of the messages waiting in the queue.
+func (c *channelSub) pending() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return len(c.queue)
+}
+
+// call calls the handler recovering its panic to keep the subscription working.
+func (c *channelSub) call(msg interface{}) {
+	defer func() {
+		recover()
+	}()
+	c.handler(msg)
 }
 
 // channelConfig defines the channel's configuration.
@@ -38,12 +321,15 @@ func newChannelConfig() channelConfig {
 }
 
 // addSub adds a new subscription to the channel.
-func (c *channelConfig) addSub(h MessageHandler) *channelSub {
+func (c *channelConfig) addSub(h MessageHandler, opts ...SubscribeOpt) *channelSub {
 	sub := &channelSub{
 		handler: h,
+		opts:    applySubOpts(opts),
 	}
 	sub.flagSub.Store(true)
+
 	c.handlers = append(c.handlers, sub)
+
 	return sub
 }
 
@@ -59,11 +345,3 @@ func (c *channelConfig) updateSub() {
 
 	c.handlers = newHandler
 }
-
-// close defines closing the channels to prevent the goroutines leak.
-func (c *channelConfig) close() {
-	for _, sub := range c.handlers {
-		<-sub.done
-		close(sub.done)
-	}
-}
@@ -49,3 +49,123 @@ func TestUnsubscribe(t *testing.T) {
 			assert.Equal(t, c.flagSub.Load(), false, "try to deactivate the subscription: wrong result was got")
 		})
 }
+
+func TestTake(t *testing.T) {
+	t.Run("TestTakePositiveCases_StrictFIFO",
+		func(t *testing.T) {
+			c := newChannelConfig()
+			sub := c.addSub(nil)
+			sub.queue = []envelope{{msg: 1}, {msg: 2}}
+
+			env, ok := sub.take()
+			assert.True(t, ok, "expected the first message to be taken")
+			assert.Equal(t, 1, env.msg, "expected the first message in the FIFO order")
+
+			_, ok = sub.take()
+			assert.False(t, ok, "expected no message to be taken while the previous one is handled")
+
+			sub.release(env)
+			env, _ = sub.take()
+			assert.Equal(t, 2, env.msg, "expected the next message after the previous one was handled")
+		})
+
+	t.Run("TestTakePositiveCases_KeyOrderedSkipsBusyKeys",
+		func(t *testing.T) {
+			c := newChannelConfig()
+			sub := c.addSub(nil, WithKeyOrdered(func(msg interface{}) string { return "" }, 0))
+			sub.queue = []envelope{{msg: 1, key: "a"}, {msg: 2, key: "a"}, {msg: 3, key: "b"}}
+
+			env, _ := sub.take()
+			assert.Equal(t, 1, env.msg, "expected the first message of the key a")
+
+			env, ok := sub.take()
+			assert.True(t, ok, "expected the message of the free key to be taken")
+			assert.Equal(t, 3, env.msg, "expected the message of the key b while the key a is busy")
+
+			_, ok = sub.take()
+			assert.False(t, ok, "expected no message to be taken while all the keys are busy")
+		})
+
+	t.Run("TestTakeMarginalCases_Unsubscribed",
+		func(t *testing.T) {
+			c := newChannelConfig()
+			sub := c.addSub(nil)
+			sub.queue = []envelope{{msg: 1}}
+
+			sub.Unsubscribe()
+			_, ok := sub.take()
+			assert.False(t, ok, "expected no message to be taken after the cancelation the sub")
+		})
+}
+
+func TestEnqueueOverflow(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy OverflowPolicy
+		want   []interface{}
+	}{
+		{"TestEnqueueOverflow_DropNewest", DropNewest, []interface{}{0, 1}},
+		{"TestEnqueueOverflow_DropOldest", DropOldest, []interface{}{2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newChannelConfig()
+			sub := c.addSub(nil, WithCapacity(2, tt.policy))
+			sub.Pause()
+
+			for i := 0; i != 4; i++ {
+				sub.enqueue(envelope{msg: i})
+			}
+
+			got := make([]interface{}, 0, len(sub.queue))
+			for _, env := range sub.queue {
+				got = append(got, env.msg)
+			}
+			assert.Equal(t, tt.want, got, "expected the queue to be limited by the capacity")
+		})
+	}
+}
+
+func TestEnqueuePriority(t *testing.T) {
+	t.Run("TestEnqueuePriorityPositiveCases_FIFOWithinLevel",
+		func(t *testing.T) {
+			c := newChannelConfig()
+			sub := c.addSub(nil)
+			sub.Pause()
+
+			sub.enqueue(envelope{msg: "bulk-0"})
+			sub.enqueue(envelope{msg: "bulk-1"})
+			sub.enqueue(envelope{msg: "control-0", priority: 1})
+			sub.enqueue(envelope{msg: "urgent", priority: 2})
+			sub.enqueue(envelope{msg: "control-1", priority: 1})
+			sub.enqueue(envelope{msg: "bulk-2"})
+
+			got := make([]interface{}, 0, len(sub.queue))
+			for _, env := range sub.queue {
+				got = append(got, env.msg)
+			}
+			assert.Equal(t, []interface{}{"urgent", "control-0", "control-1", "bulk-0", "bulk-1", "bulk-2"}, got,
+				"expected the higher priorities first and the FIFO order within the same priority")
+		})
+
+	t.Run("TestEnqueuePriorityPositiveCases_DropOldestOfLowest",
+		func(t *testing.T) {
+			c := newChannelConfig()
+			sub := c.addSub(nil, WithCapacity(3, DropOldest))
+			sub.Pause()
+
+			sub.enqueue(envelope{msg: "bulk-0"})
+			sub.enqueue(envelope{msg: "bulk-1"})
+			sub.enqueue(envelope{msg: "control-0", priority: 1})
+			sub.enqueue(envelope{msg: "control-1", priority: 1})
+			sub.enqueue(envelope{msg: "control-2", priority: 1})
+
+			got := make([]interface{}, 0, len(sub.queue))
+			for _, env := range sub.queue {
+				got = append(got, env.msg)
+			}
+			assert.Equal(t, []interface{}{"control-0", "control-1", "control-2"}, got,
+				"expected the oldest messages of the lowest priority to be dropped")
+		})
+}
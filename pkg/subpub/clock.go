@@ -0,0 +1,35 @@
+package subpub
+
+import "time"
+
+// Clock defines the source of the time for every timer and timestamp of the sub-pub system.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AfterFunc waits for the d duration to elapse and then calls the f.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer defines the timer created by the Clock.
+type Timer interface {
+	// Stop prevents the timer from firing.
+	// It returns false if the timer has already fired or been stopped.
+	Stop() bool
+}
+
+// realClock defines the Clock based on the system's time.
+type realClock struct{}
+
+// NewRealClock returns the Clock based on the system's time that is used by default.
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
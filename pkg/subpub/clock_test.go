@@ -0,0 +1,119 @@
+package subpub
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock defines the Clock that changes its time only on the Advance and Set calls.
+// It mirrors the subpubtest.FakeClock that can't be imported by the package's own tests
+// because of the import cycle: the timers are fired right inside these calls in the order of their deadlines.
+type fakeClock struct {
+	mut sync.Mutex
+	now time.Time
+
+	timers  []*fakeTimer
+	lastSeq uint64
+}
+
+// fakeTimer defines the timer of the fakeClock.
+type fakeTimer struct {
+	clock *fakeClock
+
+	deadline time.Time
+	f        func()
+
+	// seq defines the order of the timers with the same deadline.
+	seq uint64
+}
+
+func newFakeClock(start time.Time) *fakeClock {
+	return &fakeClock{
+		now: start,
+	}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.lastSeq++
+	t := &fakeTimer{
+		clock:    c,
+		deadline: c.now.Add(d),
+		f:        f,
+		seq:      c.lastSeq,
+	}
+
+	c.timers = append(c.timers, t)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		if c.timers[i].deadline.Equal(c.timers[j].deadline) {
+			return c.timers[i].seq < c.timers[j].seq
+		}
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+
+	return t
+}
+
+// Advance moves the fake time forward by the d duration firing the expired timers.
+func (c *fakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the fake time to the t firing the expired timers.
+func (c *fakeClock) Set(t time.Time) {
+	for {
+		c.mut.Lock()
+		if len(c.timers) == 0 || c.timers[0].deadline.After(t) {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mut.Unlock()
+			return
+		}
+
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		if timer.deadline.After(c.now) {
+			c.now = timer.deadline
+		}
+		c.mut.Unlock()
+
+		timer.f()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mut.Lock()
+	defer t.clock.mut.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func TestWithClock(t *testing.T) {
+	clock := newFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	e := newEventChannel(WithClock(clock))
+	assert.Equal(t, clock, e.clock, "expected the configured clock to be used")
+
+	e = newEventChannel(WithClock(nil))
+	assert.Equal(t, NewRealClock(), e.clock, "expected the real clock to be used by default")
+}
@@ -0,0 +1,71 @@
+package subpub
+
+import "time"
+
+// dedupWindow defines the logic of the recently published messages' ids tracking.
+type dedupWindow struct {
+	// ttl defines the time during which the id is remembered (0 means no limit).
+	ttl time.Duration
+
+	// limit defines the max count of the remembered ids (0 means no limit).
+	limit int
+
+	// seen defines the remembered ids with their publishing time.
+	seen map[string]time.Time
+
+	// order defines the remembered ids in the order of their publishing.
+	order []string
+}
+
+func newDedupWindow(ttl time.Duration, limit int) *dedupWindow {
+	return &dedupWindow{
+		ttl:   ttl,
+		limit: limit,
+		seen:  make(map[string]time.Time),
+	}
+}
+
+// resize changes the limits of the window keeping the remembered ids that fit into them.
+func (d *dedupWindow) resize(ttl time.Duration, limit int) {
+	d.ttl, d.limit = ttl, limit
+
+	for d.limit > 0 && len(d.order) > d.limit {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+}
+
+// check returns true if the id was published inside the window,
+// otherwise it remembers the id as published at the now.
+func (d *dedupWindow) check(id string, now time.Time) bool {
+	if d.ttl > 0 {
+		for len(d.order) != 0 && !now.Before(d.seen[d.order[0]].Add(d.ttl)) {
+			delete(d.seen, d.order[0])
+			d.order = d.order[1:]
+		}
+	}
+
+	if _, ok := d.seen[id]; ok {
+		return true
+	}
+
+	d.seen[id] = now
+	d.order = append(d.order, id)
+
+	if d.limit > 0 && len(d.order) > d.limit {
+		delete(d.seen, d.order[0])
+		d.order = d.order[1:]
+	}
+	return false
+}
+
+// expiresAt returns the time when all the remembered ids leave the window.
+// The false is returned if the ids are remembered without the time limit.
+func (d *dedupWindow) expiresAt() (time.Time, bool) {
+	if len(d.order) == 0 {
+		return time.Time{}, true
+	} else if d.ttl == 0 {
+		return time.Time{}, false
+	}
+	return d.seen[d.order[len(d.order)-1]].Add(d.ttl), true
+}
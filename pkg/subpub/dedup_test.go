@@ -0,0 +1,175 @@
+package subpub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDedupWindow(t *testing.T) {
+	t.Run("TestDedupWindowPositiveCases_ByTime",
+		func(t *testing.T) {
+			now := time.Now()
+			d := newDedupWindow(time.Minute, 0)
+
+			assert.False(t, d.check("id-0", now), "expected the first id not to be the duplicate")
+			assert.True(t, d.check("id-0", now.Add(time.Second*30)), "expected the duplicate inside the window")
+			assert.False(t, d.check("id-0", now.Add(time.Minute)), "expected the id to be forgotten after the window")
+		})
+
+	t.Run("TestDedupWindowPositiveCases_ByCount",
+		func(t *testing.T) {
+			now := time.Now()
+			d := newDedupWindow(0, 2)
+
+			d.check("id-0", now)
+			d.check("id-1", now)
+			assert.True(t, d.check("id-0", now), "expected the duplicate inside the window")
+
+			d.check("id-2", now)
+			assert.False(t, d.check("id-0", now), "expected the oldest id to be forgotten after the overflow")
+		})
+}
+
+func TestPublishDedup(t *testing.T) {
+	var (
+		testChannel = "test-channel"
+		clock       = newFakeClock(time.Now())
+		queue       = make([]string, 0, 3)
+	)
+	e := newEventChannel(WithClock(clock), WithSubjectPolicy(testChannel, SubjectPolicy{DedupWindow: time.Minute}))
+
+	e.Subscribe(testChannel, func(msg interface{}) {
+		queue = append(queue, msg.(string))
+	})
+
+	var dup bool
+
+	assert.NoError(t, e.Publish(testChannel, "test-message-0", WithMsgID("id-0"), WithDuplicate(&dup)), "expected nil error after the publishing")
+	assert.False(t, dup, "expected the first message not to be the duplicate")
+
+	assert.NoError(t, e.Publish(testChannel, "test-message-0", WithMsgID("id-0"), WithDuplicate(&dup)),
+		"expected the duplicate to be acknowledged")
+	assert.True(t, dup, "expected the duplicate to be reported")
+	assert.NoError(t, e.Publish(testChannel, "test-message-1"), "expected the messages without the id not to be deduplicated")
+
+	clock.Advance(time.Minute)
+	assert.NoError(t, e.Publish(testChannel, "test-message-0", WithMsgID("id-0")),
+		"expected the id to be accepted after the window")
+
+	assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+	assert.Equal(t, []string{"test-message-0", "test-message-1", "test-message-0"}, queue,
+		"expected the duplicate not to be delivered")
+	assert.Equal(t, SubjectStats{Published: 3, Delivered: 3, Duplicates: 1}, e.Stats(testChannel),
+		"expected the duplicate to be counted in the stats")
+}
+
+// publishDuplicate publishes the message with the id and returns whether it was skipped as the duplicate.
+func publishDuplicate(t *testing.T, sp SubPub, subject, id string) bool {
+	var dup bool
+	require.NoError(t, sp.Publish(subject, "test-message", WithMsgID(id), WithDuplicate(&dup)), "expected nil error after the publishing")
+
+	return dup
+}
+
+func TestSetPolicy(t *testing.T) {
+	var (
+		testChannel = "test-channel"
+		clock       = newFakeClock(time.Now())
+	)
+	e := newEventChannel(WithClock(clock), WithDefaultPolicy(SubjectPolicy{DedupCount: 3}))
+	defer e.Close(context.Background())
+
+	e.Subscribe(testChannel, func(interface{}) {})
+
+	for _, id := range []string{"id-0", "id-1", "id-2"} {
+		e.Publish(testChannel, "test-message", WithMsgID(id))
+	}
+
+	assert.NoError(t, e.SetPolicy("", SubjectPolicy{DedupCount: 2}), "expected nil error of the policy's changing")
+	assert.False(t, publishDuplicate(t, e, testChannel, "id-0"),
+		"expected the oldest id to be forgotten after the window's shrinking")
+	assert.True(t, publishDuplicate(t, e, testChannel, "id-2"),
+		"expected the recent id to be kept after the window's shrinking")
+
+	assert.NoError(t, e.SetPolicy(testChannel, SubjectPolicy{}), "expected nil error of the policy's changing")
+	assert.False(t, publishDuplicate(t, e, testChannel, "id-2"),
+		"expected the subject's own policy to turn the deduplication off")
+
+	assert.NoError(t, e.ResetPolicy(testChannel), "expected nil error of the policy's resetting")
+	assert.True(t, publishDuplicate(t, e, testChannel, "id-2"),
+		"expected the default policy to be applied after the subject's policy resetting")
+	assert.ErrorIs(t, e.ResetPolicy(""), ErrInputData, "expected the error of the empty subject")
+}
+
+func TestPruneSubject(t *testing.T) {
+	t.Run("TestPruneSubjectPositiveCases_AfterWindow",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Now())
+			)
+			e := newEventChannel(WithClock(clock), WithSubjectPolicy(testChannel, SubjectPolicy{DedupWindow: time.Minute}))
+			defer e.Close(context.Background())
+
+			sub, _ := e.Subscribe(testChannel, func(interface{}) {})
+			assert.False(t, publishDuplicate(t, e, testChannel, "id-0"), "expected the first message not to be the duplicate")
+			sub.Unsubscribe()
+
+			clock.Advance(time.Second * 30)
+			sub, _ = e.Subscribe(testChannel, func(interface{}) {})
+			assert.True(t, publishDuplicate(t, e, testChannel, "id-0"),
+				"expected the window to be kept for the resubscription inside it")
+			sub.Unsubscribe()
+
+			clock.Advance(time.Minute)
+
+			e.mut.Lock()
+			defer e.mut.Unlock()
+
+			assert.NotContains(t, e.dedups, testChannel, "expected the expired window of the refused subject to be removed")
+			assert.NotContains(t, e.stats, testChannel, "expected the counters of the refused subject to be removed")
+		})
+
+	t.Run("TestPruneSubjectPositiveCases_WithoutWindow",
+		func(t *testing.T) {
+			testChannel := "test-channel"
+			e := newEventChannel()
+			defer e.Close(context.Background())
+
+			sub, _ := e.Subscribe(testChannel, func(interface{}) {})
+			second, _ := e.Subscribe(testChannel, func(interface{}) {})
+			e.Publish(testChannel, "test-message")
+
+			sub.Unsubscribe()
+			assert.Equal(t, uint64(1), e.Stats(testChannel).Published,
+				"expected the counters to be kept while the subject has the subscriptions")
+
+			second.Unsubscribe()
+			assert.Equal(t, SubjectStats{}, e.Stats(testChannel),
+				"expected the counters to be removed after the last subscription's refusing")
+		})
+
+	t.Run("TestPruneSubjectNegativeCases_UnlimitedWindow",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Now())
+			)
+			e := newEventChannel(WithClock(clock), WithSubjectPolicy(testChannel, SubjectPolicy{DedupCount: 1}))
+			defer e.Close(context.Background())
+
+			sub, _ := e.Subscribe(testChannel, func(interface{}) {})
+			publishDuplicate(t, e, testChannel, "id-0")
+			sub.Unsubscribe()
+
+			clock.Advance(time.Hour)
+
+			e.Subscribe(testChannel, func(interface{}) {})
+			assert.True(t, publishDuplicate(t, e, testChannel, "id-0"),
+				"expected the window without the time limit to be kept")
+		})
+}
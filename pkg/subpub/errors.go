@@ -5,4 +5,7 @@ import "errors"
 var (
 	ErrInputData       = errors.New("error of the input params: restricted value was got")
 	ErrSystemCondition = errors.New("error of the system's condition: the call is restricted")
+
+	// ErrLimit is returned when the call exceeds the limits of the account or the publishing's rate (see RateLimitError).
+	ErrLimit = errors.New("error of the limits: the limit was exceeded")
 )
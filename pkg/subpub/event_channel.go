@@ -3,8 +3,10 @@ package subpub
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // eventChannel is the main channel for sub-pub logic implementation.
@@ -12,6 +14,9 @@ type eventChannel struct {
 	// channels defines the subscriptions on the channels and its corresponding handlers.
 	channels map[string]channelConfig
 
+	// chanSubs defines the channel's subscriptions that must be closed after the work done.
+	chanSubs map[*chanSub]struct{}
+
 	// wg defines the object for correct closing.
 	wg sync.WaitGroup
 
@@ -20,16 +25,55 @@ type eventChannel struct {
 
 	// mut helps syncronize the access to the channels.
 	mut sync.Mutex
+
+	// clock defines the source of the time for the timers and timestamps.
+	clock Clock
+
+	// policies defines the rules for the subjects' messages.
+	policies map[string]SubjectPolicy
+
+	// defaultPolicy defines the rules for the messages of the subjects without their own policy.
+	defaultPolicy SubjectPolicy
+
+	// dedups defines the windows of the recently published messages' ids by the subjects.
+	dedups map[string]*dedupWindow
+
+	// observers defines the receivers of the messages' lifecycle events.
+	observers []observerEntry
+
+	// events defines the lifecycle events collected under the mut to be reported after its unlocking.
+	events []Event
+
+	// stats defines the counters of the subjects' messages.
+	stats map[string]*subjectStats
+
+	// scheduleStore defines the durable storage of the scheduled messages (nil means the memory only).
+	scheduleStore ScheduleStore
+
+	// sched defines the logic of the delayed publishing.
+	sched *scheduler
 }
 
-func newEventChannel() *eventChannel {
-	return &eventChannel{
+func newEventChannel(opts ...SubPubOpt) *eventChannel {
+	e := &eventChannel{
 		channels: make(map[string]channelConfig),
+		chanSubs: make(map[*chanSub]struct{}),
+		clock:    NewRealClock(),
+		policies: make(map[string]SubjectPolicy),
+		dedups:   make(map[string]*dedupWindow),
+		stats:    make(map[string]*subjectStats),
 	}
+
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.sched = newScheduler(e, e.scheduleStore)
+
+	return e
 }
 
 // Subscribe defines the logic of the subscription on the subject.
-func (e *eventChannel) Subscribe(subject string, cb MessageHandler) (Subscription, error) {
+func (e *eventChannel) Subscribe(subject string, cb MessageHandler, opts ...SubscribeOpt) (Subscription, error) {
 	const op = "subpub.Subscribe"
 
 	if e.flagDone.Load() {
@@ -38,90 +82,400 @@ func (e *eventChannel) Subscribe(subject string, cb MessageHandler) (Subscriptio
 		return nil, fmt.Errorf("error of the %s: %w: try to subscribe with the nil handler", op, ErrInputData)
 	} else if subject == "" {
 		return nil, fmt.Errorf("error of the %s: %w: try to subscribe on the empty subject", op, ErrInputData)
+	} else if o := applySubOpts(opts); o.mode == KeyOrdered && o.key == nil {
+		return nil, fmt.Errorf("error of the %s: %w: try to subscribe in the KeyOrdered mode with the nil key func", op, ErrInputData)
 	}
 
 	e.mut.Lock()
-	defer e.mut.Unlock()
 
-	if conf, ok := e.channels[subject]; ok {
-		sub := conf.addSub(cb)
-		e.channels[subject] = conf
-		return sub, nil
+	conf, ok := e.channels[subject]
+	if !ok {
+		conf = newChannelConfig()
 	}
-	conf := newChannelConfig()
-	sub := conf.addSub(cb)
+	sub := conf.addSub(cb, opts...)
+	sub.subject = subject
+	sub.bus = e
+	sub.stats = e.subjectStats(subject)
 
 	e.channels[subject] = conf
 
+	e.mut.Unlock()
+	e.sched.resume(subject)
+
 	return sub, nil
 }
 
 // Publish defines the logic of the publishing the event.
-func (e *eventChannel) Publish(subject string, msg interface{}) error {
+func (e *eventChannel) Publish(subject string, msg interface{}, opts ...PublishOpt) error {
 	const op = "subpub.Publish"
 
+	o := applyPubOpts(opts)
+
 	if e.flagDone.Load() {
 		return fmt.Errorf("error of the %s: %w: try to subscribe after the work done", op, ErrSystemCondition)
 	} else if subject == "" {
 		return fmt.Errorf("error of the %s: %w: try to publish into the empty subject", op, ErrInputData)
 	} else if msg == nil {
 		return fmt.Errorf("error of the %s: %w: try to publish the nil msg", op, ErrInputData)
+	} else if o.ttl < 0 {
+		return fmt.Errorf("error of the %s: %w: try to publish with the negative ttl", op, ErrInputData)
+	}
+
+	e.mut.Lock()
+
+	if e.flagDone.Load() {
+		e.mut.Unlock()
+		return fmt.Errorf("error of the %s: %w: try to publish after the work done", op, ErrSystemCondition)
 	} else if _, ok := e.channels[subject]; !ok {
+		e.mut.Unlock()
 		return fmt.Errorf("error of the %s: %w: try to publish into the unexisting channel", op, ErrInputData)
 	}
 
-	e.mut.Lock()
-	defer e.mut.Unlock()
+	subs, ok := e.publish(subject, msg, o, true)
+	if o.duplicate != nil {
+		*o.duplicate = !ok
+	}
+	events := e.takeEvents()
+
+	// the Close waits for the waking of the held messages
+	e.wg.Add(1)
+	defer e.wg.Done()
 
+	e.mut.Unlock()
+
+	// the handling is started after the reporting, so the message is reported as published before its delivery
+	e.reportAll(events)
+	for _, sub := range subs {
+		sub.wake()
+	}
+
+	return nil
+}
+
+// publish puts the msg into the queues of the subject's active subscriptions and returns them.
+// If the hold is set the handling isn't started, so the caller must wake the returned subscriptions.
+// The false is returned if the msg is the duplicate inside the subject's dedup window.
+// Must be called with the mut locked after the check of the subject's existence.
+func (e *eventChannel) publish(subject string, msg interface{}, o pubOpts, hold bool) ([]*channelSub, bool) {
 	conf := e.channels[subject]
 	conf.updateSub()
 
+	policy := e.policy(subject)
+	if o.msgID != "" && policy.dedup() && e.dedupWindow(subject, policy).check(o.msgID, e.clock.Now()) {
+		e.subjectStats(subject).duplicates.Add(1)
+		e.collect(Event{Kind: EventDuplicate, Subject: subject, Msg: msg})
+
+		return nil, false
+	}
+
+	e.subjectStats(subject).published.Add(1)
+	e.collect(Event{Kind: EventPublished, Subject: subject, Msg: msg})
+
 	if len(conf.handlers) == 0 {
 		delete(e.channels, subject)
-		return nil
-	}
+		e.prune(subject)
 
+		return nil, true
+	}
 	e.channels[subject] = conf
 
-	wgStart := sync.WaitGroup{}
-	wgStart.Add(len(e.channels[subject].handlers))
+	env := envelope{msg: msg, priority: o.priority}
+	if o.ttl == 0 {
+		o.ttl = policy.TTL
+	}
+	if o.ttl > 0 {
+		env.expiresAt = e.clock.Now().Add(o.ttl)
+	}
 
-	for _, sub := range e.channels[subject].handlers {
-		e.wg.Add(1)
-		go func() {
-			defer e.wg.Done()
-			wgStart.Done()
+	for _, sub := range conf.handlers {
+		if dropped, ok := sub.push(env, !hold); ok {
+			sub.stats.dropped.Add(1)
+			e.collect(Event{Kind: EventDropped, Subject: subject, Msg: dropped.msg})
+		}
+	}
+
+	return conf.handlers, true
+}
 
-			sub.mut.Lock()
-			lastDone := sub.done
-			nextDone := make(chan bool)
+// PublishAt defines the logic of the publishing the event at the definite time.
+func (e *eventChannel) PublishAt(subject string, msg interface{}, at time.Time, opts ...PublishOpt) (string, error) {
+	const op = "subpub.PublishAt"
 
-			sub.done = nextDone
-			sub.mut.Unlock()
+	o := applyPubOpts(opts)
 
-			if lastDone != nil {
-				<-lastDone
+	if e.flagDone.Load() {
+		return "", fmt.Errorf("error of the %s: %w: try to publish after the work done", op, ErrSystemCondition)
+	} else if subject == "" {
+		return "", fmt.Errorf("error of the %s: %w: try to publish into the empty subject", op, ErrInputData)
+	} else if msg == nil {
+		return "", fmt.Errorf("error of the %s: %w: try to publish the nil msg", op, ErrInputData)
+	} else if o.ttl < 0 {
+		return "", fmt.Errorf("error of the %s: %w: try to publish with the negative ttl", op, ErrInputData)
+	}
+
+	id, err := e.sched.schedule(ScheduledMessage{
+		Subject:   subject,
+		Msg:       msg,
+		DeliverAt: at,
+		TTL:       o.ttl,
+		Priority:  o.priority,
+		MsgID:     o.msgID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error of the %s: %w", op, err)
+	}
+	return id, nil
+}
+
+// PublishAfter defines the logic of the publishing the event after the delay.
+func (e *eventChannel) PublishAfter(subject string, msg interface{}, delay time.Duration, opts ...PublishOpt) (string, error) {
+	return e.PublishAt(subject, msg, e.clock.Now().Add(delay), opts...)
+}
+
+// Scheduled returns the messages that wait for their publishing time.
+func (e *eventChannel) Scheduled() []ScheduledMessage {
+	return e.sched.list()
+}
+
+// CancelScheduled cancels the publishing of the scheduled message.
+func (e *eventChannel) CancelScheduled(id string) error {
+	return e.sched.cancel(id)
+}
+
+// Stats returns the counters of the subject's messages.
+func (e *eventChannel) Stats(subject string) SubjectStats {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	stats, ok := e.stats[subject]
+	if !ok {
+		return SubjectStats{}
+	}
+	snapshot := stats.snapshot()
+
+	for _, sub := range e.channels[subject].handlers {
+		snapshot.Pending += uint64(sub.pending())
+	}
+	return snapshot
+}
+
+// Subjects returns the sorted names of the subjects that have the active subscriptions.
+func (e *eventChannel) Subjects() []string {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	subjects := make([]string, 0, len(e.channels))
+	for subject, conf := range e.channels {
+		for _, sub := range conf.handlers {
+			if sub.flagSub.Load() {
+				subjects = append(subjects, subject)
+				break
 			}
-			defer func() {
-				if err := recover(); err != nil {
-					nextDone <- true
-				}
-			}()
+		}
+	}
+	sort.Strings(subjects)
 
-			sub.handler(msg)
+	return subjects
+}
 
-			nextDone <- true
-		}()
+// Purge drops the messages waiting in the queues of the subject's subscriptions.
+func (e *eventChannel) Purge(subject string) int {
+	e.mut.Lock()
+	handlers := e.channels[subject].handlers
+	e.mut.Unlock()
+
+	count := 0
+	for _, sub := range handlers {
+		count += sub.purge()
+	}
+	return count
+}
+
+// SetPolicy defines the logic of the subject's policy changing.
+func (e *eventChannel) SetPolicy(subject string, policy SubjectPolicy) error {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	if subject == "" {
+		e.defaultPolicy = policy
+	} else {
+		e.policies[subject] = policy
+	}
+
+	for subj, d := range e.dedups {
+		if subject == "" || subj == subject {
+			p := e.policy(subj)
+			d.resize(p.DedupWindow, p.DedupCount)
+		}
 	}
-	wgStart.Wait()
+	return nil
+}
+
+// ResetPolicy defines the logic of the subject's own policy removing.
+func (e *eventChannel) ResetPolicy(subject string) error {
+	const op = "subpub.ResetPolicy"
+
+	if subject == "" {
+		return fmt.Errorf("error of the %s: %w: the subject is empty", op, ErrInputData)
+	}
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	delete(e.policies, subject)
 
+	if d, ok := e.dedups[subject]; ok {
+		d.resize(e.defaultPolicy.DedupWindow, e.defaultPolicy.DedupCount)
+	}
 	return nil
 }
 
-// close defines the logic of releasing the resources connected with the channels.
-func (e *eventChannel) close() {
-	for _, ch := range e.channels {
-		ch.close()
+// policy returns the rules for the subject's messages.
+func (e *eventChannel) policy(subject string) SubjectPolicy {
+	if policy, ok := e.policies[subject]; ok {
+		return policy
+	}
+	return e.defaultPolicy
+}
+
+// dedupWindow returns the dedup window of the subject creating it if it's needed.
+// Must be called with the mut locked.
+func (e *eventChannel) dedupWindow(subject string, policy SubjectPolicy) *dedupWindow {
+	d, ok := e.dedups[subject]
+	if !ok {
+		d = newDedupWindow(policy.DedupWindow, policy.DedupCount)
+		e.dedups[subject] = d
+	}
+	return d
+}
+
+// subjectStats returns the counters of the subject creating them if it's needed.
+// Must be called with the mut locked.
+func (e *eventChannel) subjectStats(subject string) *subjectStats {
+	stats, ok := e.stats[subject]
+	if !ok {
+		stats = &subjectStats{}
+		e.stats[subject] = stats
+	}
+	return stats
+}
+
+// release prunes the subject's state once its last subscription is refused.
+func (e *eventChannel) release(subject string) {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	e.prune(subject)
+}
+
+// subscribed defines whether the subject has the active subscriptions.
+// Must be called with the mut locked.
+func (e *eventChannel) subscribed(subject string) bool {
+	for _, sub := range e.channels[subject].handlers {
+		if sub.flagSub.Load() {
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes the dedup window and the counters of the subject without the subscriptions
+// once the window's remembered ids expire, so the refused subjects don't keep the memory.
+// The window that remembers the ids without the time limit is kept.
+// Must be called with the mut locked.
+func (e *eventChannel) prune(subject string) {
+	if e.subscribed(subject) {
+		return
+	}
+
+	if d, ok := e.dedups[subject]; ok {
+		at, ok := d.expiresAt()
+		if !ok {
+			return
+		}
+
+		if wait := at.Sub(e.clock.Now()); wait > 0 {
+			e.clock.AfterFunc(wait, func() {
+				e.mut.Lock()
+				defer e.mut.Unlock()
+
+				e.prune(subject)
+			})
+			return
+		}
+	}
+
+	delete(e.dedups, subject)
+	delete(e.stats, subject)
+}
+
+// report notifies the observers about the message's lifecycle event.
+func (e *eventChannel) report(ev Event) {
+	for _, entry := range e.observers {
+		if entry.kinds&(1<<ev.Kind) != 0 {
+			entry.obs.Observe(ev)
+		}
+	}
+}
+
+// collect keeps the lifecycle event to be reported after the mut's unlocking,
+// so the observers may call the sub-pub system back.
+// Must be called with the mut locked.
+func (e *eventChannel) collect(ev Event) {
+	if e.observes(ev.Kind) {
+		e.events = append(e.events, ev)
+	}
+}
+
+// takeEvents returns the collected lifecycle events and forgets them.
+// Must be called with the mut locked.
+func (e *eventChannel) takeEvents() []Event {
+	events := e.events
+	e.events = nil
+
+	return events
+}
+
+// reportAll notifies the observers about the lifecycle events in their order.
+func (e *eventChannel) reportAll(events []Event) {
+	for _, ev := range events {
+		e.report(ev)
+	}
+}
+
+// observes checks whether any observer receives the events of the kind.
+func (e *eventChannel) observes(kind EventKind) bool {
+	for _, entry := range e.observers {
+		if entry.kinds&(1<<kind) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// channelSubs returns the channel's subscriptions.
+func (e *eventChannel) channelSubs() []*chanSub {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	chanSubs := make([]*chanSub, 0, len(e.chanSubs))
+	for c := range e.chanSubs {
+		chanSubs = append(chanSubs, c)
+	}
+	return chanSubs
+}
+
+// stopChanSubs makes the handlers of the channel's subscriptions stop waiting for their readers.
+func (e *eventChannel) stopChanSubs() {
+	for _, c := range e.channelSubs() {
+		c.stop()
+	}
+}
+
+// closeChanSubs closes the channels of the channel's subscriptions.
+func (e *eventChannel) closeChanSubs() {
+	for _, c := range e.channelSubs() {
+		c.close()
 	}
 }
 
@@ -129,19 +483,38 @@ func (e *eventChannel) close() {
 func (e *eventChannel) Close(ctx context.Context) error {
 	const op = "subpub.Close"
 
+	e.mut.Lock()
 	e.flagDone.Store(true)
+	e.mut.Unlock()
+
+	e.sched.stop()
+	e.stopChanSubs()
 
 	select {
 	case <-ctx.Done():
+		e.closeChanSubs()
 		return fmt.Errorf("error of the %s: fast shutdown: %s", op, ctx.Err())
 
 	default:
-		e.mut.Lock()
-		e.close()
-		e.mut.Unlock()
+		done := make(chan struct{})
+		go func() {
+			e.wg.Wait()
+			close(done)
+		}()
 
-		e.wg.Wait()
+		select {
+		case <-ctx.Done():
+			e.closeChanSubs()
+			return fmt.Errorf("error of the %s: fast shutdown: %s", op, ctx.Err())
+		case <-done:
+		}
+
+		e.closeChanSubs()
 	}
 
+	if err := e.sched.flush(); err != nil {
+		return fmt.Errorf("error of the %s: %w: the published scheduled messages weren't removed from the store "+
+			"and will be published again after the restart: %s", op, ErrSystemCondition, err)
+	}
 	return nil
 }
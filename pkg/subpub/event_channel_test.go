@@ -4,6 +4,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -103,16 +106,20 @@ func TestPublishPositiveCases(t *testing.T) {
 				testMessage = "test-message"
 			)
 			var (
+				wg       sync.WaitGroup
 				queue1   = make([]string, 0, 10)
 				queue2   = make([]string, 0, 10)
 				queue3   = make([]string, 0, 10)
 				handler1 = func(msg interface{}) {
+					defer wg.Done()
 					queue1 = append(queue1, msg.(string))
 				}
 				handler2 = func(msg interface{}) {
+					defer wg.Done()
 					queue2 = append(queue2, msg.(string))
 				}
 				handler3 = func(msg interface{}) {
+					defer wg.Done()
 					queue3 = append(queue3, msg.(string))
 				}
 			)
@@ -122,11 +129,12 @@ func TestPublishPositiveCases(t *testing.T) {
 			sub2, _ := e.Subscribe(fmt.Sprintf("%s-%d", testChannel, 2), handler2)
 			e.Subscribe(fmt.Sprintf("%s-%d", testChannel, 3), handler3)
 
+			wg.Add(3)
 			for i := 0; i != 3; i++ {
 				err := e.Publish(fmt.Sprintf("%s-%d", testChannel, i+1), testMessage)
 				assert.NoError(t, err, "expected correct Publish work on the configured channel")
 			}
-			time.Sleep(time.Second * 5)
+			wg.Wait()
 
 			assert.Equal(t, []string{testMessage}, queue1, "expected correct queue1: actual wrong queue was got")
 			assert.Equal(t, []string{testMessage}, queue2, "expected correct queue2: actual wrong queue was got")
@@ -137,11 +145,12 @@ func TestPublishPositiveCases(t *testing.T) {
 
 			queue1, queue2 = []string{}, []string{}
 
+			wg.Add(1)
 			for i := 0; i != 3; i++ {
 				err := e.Publish(fmt.Sprintf("%s-%d", testChannel, i+1), testMessage)
 				assert.NoError(t, err, "expected the correct Publish executing after the cancelation the sub")
 			}
-			time.Sleep(time.Second * 5)
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
 
 			assert.Equal(t, []string{}, queue1, "expected empty queue1: actual wrong queue was got")
 			assert.Equal(t, []string{}, queue2, "expected empty queue2: actual wrong queue was got")
@@ -155,16 +164,20 @@ func TestPublishPositiveCases(t *testing.T) {
 				testMessage = "test-message"
 			)
 			var (
+				wg       sync.WaitGroup
 				queue1   = make([]string, 0, 10)
 				queue2   = make([]string, 0, 10)
 				queue3   = make([]string, 0, 10)
 				handler1 = func(msg interface{}) {
+					defer wg.Done()
 					queue1 = append(queue1, msg.(string))
 				}
 				handler2 = func(msg interface{}) {
+					defer wg.Done()
 					queue2 = append(queue2, msg.(string))
 				}
 				handler3 = func(msg interface{}) {
+					defer wg.Done()
 					queue3 = append(queue3, msg.(string))
 				}
 			)
@@ -174,11 +187,12 @@ func TestPublishPositiveCases(t *testing.T) {
 			sub2, _ := e.Subscribe(testChannel, handler2)
 			e.Subscribe(testChannel, handler3)
 
+			wg.Add(6)
 			for i := 0; i != 2; i++ {
 				err := e.Publish(testChannel, testMessage)
 				assert.NoError(t, err, "expected correct Publish work on the configured channel")
 			}
-			time.Sleep(time.Second * 5)
+			wg.Wait()
 
 			assert.Equal(t, []string{testMessage, testMessage}, queue1, "expected correct queue1: actual wrong queue was got")
 			assert.Equal(t, []string{testMessage, testMessage}, queue2, "expected correct queue2: actual wrong queue was got")
@@ -189,10 +203,11 @@ func TestPublishPositiveCases(t *testing.T) {
 
 			queue1, queue2 = []string{}, []string{}
 
+			wg.Add(1)
 			err := e.Publish(testChannel, testMessage)
 			assert.NoError(t, err, "expected the correct Publish executing after the cancelation the sub")
 
-			time.Sleep(time.Second * 5)
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
 
 			assert.Equal(t, []string{}, queue1, "expected empty queue1: actual wrong queue was got")
 			assert.Equal(t, []string{}, queue2, "expected empty queue2: actual wrong queue was got")
@@ -209,7 +224,6 @@ func TestPublishPositiveCases(t *testing.T) {
 			e := newEventChannel()
 
 			e.Subscribe(testChannel, func(msg interface{}) {
-				time.Sleep(time.Second * 2)
 				queue = append(queue, msg.(string))
 			})
 
@@ -217,7 +231,7 @@ func TestPublishPositiveCases(t *testing.T) {
 				e.Publish(testChannel, fmt.Sprintf("%s-%d", testMessage, i))
 			}
 
-			time.Sleep(time.Second * 15)
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
 			assert.Equal(t, 5, len(queue),
 				"expected full completing the publishsing: actual it hasn't been completed")
 
@@ -329,12 +343,13 @@ func TestClose(t *testing.T) {
 			var (
 				testChannel = "test-channel"
 				testMessage = "test-message"
+				delivered   atomic.Int64
 			)
 			e := newEventChannel()
 			ctx := context.Background()
 
 			e.Subscribe(testChannel, func(msg interface{}) {
-				time.Sleep(time.Millisecond * 200)
+				delivered.Add(1)
 			})
 
 			for i := 0; i != 30; i++ {
@@ -343,6 +358,7 @@ func TestClose(t *testing.T) {
 
 			assert.NoError(t, e.Close(ctx), "expected nil error after closing: actual some err was got")
 			assert.Equal(t, true, e.flagDone.Load(), "expected shutdown condition after event channel closing")
+			assert.Equal(t, int64(30), delivered.Load(), "expected the closing to wait for the published messages' delivery")
 		})
 
 	t.Run("TestClose_IncorrectShutDown",
@@ -358,3 +374,227 @@ func TestClose(t *testing.T) {
 			assert.Equal(t, true, e.flagDone.Load(), "expected shutdown condition after event channel closing")
 		})
 }
+
+func TestPublishOrderModes(t *testing.T) {
+	t.Run("TestPublishOrderModes_UnorderedConcurrencyLimit",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				testMessage = "test-message"
+				cur, peak   atomic.Int64
+				wg          sync.WaitGroup
+				entered     = make(chan struct{}, 9)
+				release     = make(chan struct{})
+			)
+			e := newEventChannel()
+
+			e.Subscribe(testChannel, func(msg interface{}) {
+				defer wg.Done()
+
+				n := cur.Add(1)
+				for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+				}
+				entered <- struct{}{}
+				<-release
+				cur.Add(-1)
+			}, WithUnordered(3))
+
+			wg.Add(9)
+			for i := 0; i != 9; i++ {
+				e.Publish(testChannel, fmt.Sprintf("%s-%d", testMessage, i))
+			}
+
+			for i := 0; i != 3; i++ {
+				<-entered
+			}
+			assert.Equal(t, uint64(6), e.Stats(testChannel).Pending, "expected the rest of the messages to wait for the free handler")
+
+			close(release)
+			wg.Wait()
+
+			assert.Equal(t, int64(3), peak.Load(), "expected the concurrent handling up to the limit")
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+		})
+
+	t.Run("TestPublishOrderModes_KeyOrdered",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				mut         sync.Mutex
+				queues      = map[string][]string{}
+				wg          sync.WaitGroup
+				started     = make(chan struct{}, 3)
+				release     = make(chan struct{})
+			)
+			e := newEventChannel()
+
+			key := func(msg interface{}) string {
+				return strings.Split(msg.(string), "-")[0]
+			}
+			e.Subscribe(testChannel, func(msg interface{}) {
+				defer wg.Done()
+
+				// the first messages of the keys are handled concurrently untill all of them are started
+				if strings.HasSuffix(msg.(string), "-0") {
+					started <- struct{}{}
+					<-release
+				}
+				mut.Lock()
+				queues[key(msg)] = append(queues[key(msg)], msg.(string))
+				mut.Unlock()
+			}, WithKeyOrdered(key, 0))
+
+			wg.Add(15)
+			for i := 0; i != 5; i++ {
+				for _, k := range []string{"a", "b", "c"} {
+					e.Publish(testChannel, fmt.Sprintf("%s-%d", k, i))
+				}
+			}
+
+			for i := 0; i != 3; i++ {
+				<-started
+			}
+			close(release)
+			wg.Wait()
+
+			for _, k := range []string{"a", "b", "c"} {
+				for i := 0; i != 5; i++ {
+					assert.Equal(t, fmt.Sprintf("%s-%d", k, i), queues[k][i],
+						"expected corresponding queue value: actual order inside the key is wrong")
+				}
+			}
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+		})
+
+	t.Run("TestPublishOrderModes_KeyOrderedNilKeyFunc",
+		func(t *testing.T) {
+			_, err := newEventChannel().Subscribe("test-channel", func(msg interface{}) {}, WithKeyOrdered(nil, 0))
+			assert.ErrorIs(t, err, ErrInputData, "expected error after the subscribing with the nil key func")
+		})
+}
+
+func TestPauseResume(t *testing.T) {
+	t.Run("TestPauseResumePositiveCases_BufferingWhilePaused",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				testMessage = "test-message"
+				mut         sync.Mutex
+				queue       = make([]string, 0, 10)
+				wg          sync.WaitGroup
+			)
+			e := newEventChannel()
+
+			sub, _ := e.Subscribe(testChannel, func(msg interface{}) {
+				defer wg.Done()
+
+				mut.Lock()
+				queue = append(queue, msg.(string))
+				mut.Unlock()
+			}, WithCapacity(3, DropOldest))
+
+			sub.Pause()
+			for i := 0; i != 5; i++ {
+				e.Publish(testChannel, fmt.Sprintf("%s-%d", testMessage, i))
+			}
+			assert.Equal(t, uint64(3), e.Stats(testChannel).Pending, "expected the messages to be buffered while the subscription is paused")
+
+			mut.Lock()
+			assert.Equal(t, 0, len(queue), "expected no delivery while the subscription is paused")
+			mut.Unlock()
+
+			wg.Add(3)
+			sub.Resume()
+			wg.Wait()
+
+			assert.Equal(t, []string{"test-message-2", "test-message-3", "test-message-4"}, queue,
+				"expected the buffered messages to be delivered after the resume without the dropped oldest ones")
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+		})
+
+	t.Run("TestPauseResumePositiveCases_CloseWithPausedSubscription",
+		func(t *testing.T) {
+			e := newEventChannel()
+
+			sub, _ := e.Subscribe("test-channel", func(msg interface{}) {})
+			sub.Pause()
+			e.Publish("test-channel", "test-message")
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			assert.NoError(t, e.Close(ctx), "expected no waiting for the paused subscriptions on closing")
+		})
+}
+
+func TestPublishPriority(t *testing.T) {
+	var (
+		testChannel = "test-channel"
+		started     = make(chan struct{})
+		gate        = make(chan struct{})
+		queue       = make([]string, 0, 4)
+	)
+	e := newEventChannel()
+
+	e.Subscribe(testChannel, func(msg interface{}) {
+		if msg.(string) == "test-message-first" {
+			close(started)
+			<-gate
+		}
+		queue = append(queue, msg.(string))
+	})
+
+	e.Publish(testChannel, "test-message-first")
+	<-started
+
+	e.Publish(testChannel, "test-message-bulk")
+	e.Publish(testChannel, "test-message-control", WithPriority(1))
+	e.Publish(testChannel, "test-message-low", WithPriority(-1))
+	close(gate)
+
+	assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+	assert.Equal(t, []string{"test-message-first", "test-message-control", "test-message-bulk", "test-message-low"}, queue,
+		"expected the waiting messages to be handled in the order of their priorities")
+}
+
+func TestSubjects(t *testing.T) {
+	e := newEventChannel()
+	defer e.Close(context.Background())
+
+	e.Subscribe("test-channel-b", func(msg interface{}) {})
+	e.Subscribe("test-channel-a", func(msg interface{}) {})
+
+	sub, _ := e.Subscribe("test-channel-c", func(msg interface{}) {})
+	sub.Unsubscribe()
+
+	assert.Equal(t, []string{"test-channel-a", "test-channel-b"}, e.Subjects(),
+		"expected the sorted subjects with the active subscriptions")
+}
+
+func TestPurge(t *testing.T) {
+	var (
+		testChannel = "test-channel"
+		obs         = &eventsRecorder{}
+		queue       = make([]string, 0, 1)
+	)
+	e := newEventChannel(WithObserver(obs))
+
+	sub, _ := e.Subscribe(testChannel, func(msg interface{}) {
+		queue = append(queue, msg.(string))
+	})
+	sub.Pause()
+
+	e.Publish(testChannel, "test-message-0")
+	e.Publish(testChannel, "test-message-1")
+
+	assert.Equal(t, 2, e.Purge(testChannel), "expected the waiting messages to be purged")
+	assert.Equal(t, 0, e.Purge("test-channel-unexists"), "expected nothing to be purged from the unexisting subject")
+
+	sub.Resume()
+	e.Publish(testChannel, "test-message-2")
+
+	assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+	assert.Equal(t, []string{"test-message-2"}, queue, "expected the purged messages not to be delivered")
+	assert.Equal(t, uint64(2), e.Stats(testChannel).Dropped, "expected the purged messages to be counted as the dropped")
+	assert.Len(t, obs.events, 2, "expected the purged messages to be reported")
+}
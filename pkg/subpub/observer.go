@@ -0,0 +1,103 @@
+package subpub
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// EventKind defines the kind of the message's lifecycle event.
+type EventKind int
+
+const (
+	// EventExpired is reported when the message's TTL elapsed before the handler's call.
+	EventExpired EventKind = iota
+
+	// EventDropped is reported when the message was dropped because of the queue's overflow
+	// or when the scheduled message couldn't be published at its time.
+	EventDropped
+
+	// EventPublished is reported when the message was accepted into the subject.
+	EventPublished
+
+	// EventDelivered is reported after the handler's call with the message.
+	EventDelivered
+
+	// EventDuplicate is reported when the message was rejected by the subject's dedup window.
+	EventDuplicate
+)
+
+// defaultEventKinds defines the kinds of the events reported to the observer by default.
+var defaultEventKinds = []EventKind{EventExpired, EventDropped}
+
+// Event defines the notification about the message's lifecycle.
+type Event struct {
+	Kind    EventKind
+	Subject string
+	Msg     interface{}
+
+	// Latency defines the duration of the handler's call for the EventDelivered.
+	Latency time.Duration
+}
+
+// Observer defines the receiver of the message's lifecycle events.
+// Observe is called synchronously, so it mustn't block. It's called without the sub-pub system's locks,
+// so it may call the sub-pub system back (e.g. its Stats or Publish).
+type Observer interface {
+	Observe(ev Event)
+}
+
+// ObserverFunc defines the adapter that allows to use the ordinary func as the Observer.
+type ObserverFunc func(ev Event)
+
+func (f ObserverFunc) Observe(ev Event) {
+	f(ev)
+}
+
+// observerEntry defines the observer with the kinds of the events it receives.
+type observerEntry struct {
+	obs Observer
+
+	// kinds defines the bit mask of the received events' kinds.
+	kinds uint
+}
+
+// SubjectStats defines the counters of the subject's messages.
+type SubjectStats struct {
+	// Published defines the count of the messages published into the subject.
+	Published uint64
+
+	// Delivered defines the count of the handler's calls.
+	Delivered uint64
+
+	// Expired defines the count of the messages discarded because of the TTL.
+	Expired uint64
+
+	// Dropped defines the count of the messages dropped because of the queues' overflow.
+	Dropped uint64
+
+	// Duplicates defines the count of the messages rejected by the dedup window.
+	Duplicates uint64
+
+	// Pending defines the count of the messages waiting in the queues of the subject's subscriptions.
+	Pending uint64
+}
+
+// subjectStats defines the thread-safe counters of the subject's messages.
+type subjectStats struct {
+	published  atomic.Uint64
+	delivered  atomic.Uint64
+	expired    atomic.Uint64
+	dropped    atomic.Uint64
+	duplicates atomic.Uint64
+}
+
+// snapshot returns the current values of the counters.
+func (s *subjectStats) snapshot() SubjectStats {
+	return SubjectStats{
+		Published:  s.published.Load(),
+		Delivered:  s.delivered.Load(),
+		Expired:    s.expired.Load(),
+		Dropped:    s.dropped.Load(),
+		Duplicates: s.duplicates.Load(),
+	}
+}
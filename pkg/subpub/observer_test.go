@@ -0,0 +1,178 @@
+package subpub
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// eventsRecorder defines the Observer that stores all the reported events.
+type eventsRecorder struct {
+	mut    sync.Mutex
+	events []Event
+}
+
+func (r *eventsRecorder) Observe(ev Event) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.events = append(r.events, ev)
+}
+
+func TestMessageTTL(t *testing.T) {
+	t.Run("TestMessageTTLPositiveCases_ExpiredBeforeHandling",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Now())
+				obs         = &eventsRecorder{}
+				gate        = make(chan struct{})
+				queue       = make([]string, 0, 3)
+			)
+			e := newEventChannel(WithClock(clock), WithObserver(obs))
+
+			e.Subscribe(testChannel, func(msg interface{}) {
+				<-gate
+				queue = append(queue, msg.(string))
+			})
+
+			e.Publish(testChannel, "test-message-0")
+			e.Publish(testChannel, "test-message-1", WithTTL(time.Second))
+			e.Publish(testChannel, "test-message-2")
+
+			clock.Advance(time.Second * 2)
+			close(gate)
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.Equal(t, []string{"test-message-0", "test-message-2"}, queue,
+				"expected the expired message to be discarded before the handling")
+
+			assert.Equal(t, SubjectStats{Published: 3, Delivered: 2, Expired: 1}, e.Stats(testChannel),
+				"expected the expired message to be counted in the stats")
+			assert.Equal(t, []Event{{Kind: EventExpired, Subject: testChannel, Msg: "test-message-1"}}, obs.events,
+				"expected the expired message to be reported to the observer")
+		})
+
+	t.Run("TestMessageTTLPositiveCases_SubjectPolicy",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Now())
+				started     = make(chan struct{}, 3)
+				gate        = make(chan struct{})
+			)
+			e := newEventChannel(WithClock(clock), WithSubjectPolicy(testChannel, SubjectPolicy{TTL: time.Second}))
+
+			e.Subscribe(testChannel, func(msg interface{}) {
+				started <- struct{}{}
+				<-gate
+			})
+
+			e.Publish(testChannel, "test-message-0")
+			<-started
+
+			e.Publish(testChannel, "test-message-1")
+			e.Publish(testChannel, "test-message-2", WithTTL(time.Minute))
+
+			clock.Advance(time.Second * 2)
+			close(gate)
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.Equal(t, SubjectStats{Published: 3, Delivered: 2, Expired: 1}, e.Stats(testChannel),
+				"expected the subject's TTL to be overridden by the message's one")
+		})
+
+	t.Run("TestMessageTTLNegativeCases_NegativeTTL",
+		func(t *testing.T) {
+			e := newEventChannel()
+			e.Subscribe("test-channel", func(msg interface{}) {})
+
+			assert.ErrorIs(t, e.Publish("test-channel", "test-message", WithTTL(-time.Second)), ErrInputData,
+				"expected error after the publishing with the negative ttl")
+		})
+}
+
+func TestDroppedReport(t *testing.T) {
+	var (
+		testChannel = "test-channel"
+		obs         = &eventsRecorder{}
+	)
+	e := newEventChannel(WithObserver(obs))
+
+	sub, _ := e.Subscribe(testChannel, func(msg interface{}) {}, WithCapacity(1, DropNewest))
+	sub.Pause()
+
+	e.Publish(testChannel, "test-message-0")
+	e.Publish(testChannel, "test-message-1")
+
+	assert.Equal(t, SubjectStats{Published: 2, Dropped: 1, Pending: 1}, e.Stats(testChannel),
+		"expected the dropped message to be counted and the kept one to be pending in the stats")
+	assert.Equal(t, []Event{{Kind: EventDropped, Subject: testChannel, Msg: "test-message-1"}}, obs.events,
+		"expected the dropped message to be reported to the observer")
+}
+
+func TestObserverKinds(t *testing.T) {
+	var (
+		testChannel = "test-channel"
+		clock       = newFakeClock(time.Now())
+		all         = &eventsRecorder{}
+		defaults    = &eventsRecorder{}
+	)
+	e := newEventChannel(
+		WithClock(clock),
+		WithDefaultPolicy(SubjectPolicy{DedupWindow: time.Minute}),
+		WithObserver(all, EventPublished, EventDelivered, EventDuplicate),
+		WithObserver(defaults),
+	)
+
+	e.Subscribe(testChannel, func(msg interface{}) {})
+
+	e.Publish(testChannel, "test-message-0", WithMsgID("id-0"))
+	e.Publish(testChannel, "test-message-1", WithMsgID("id-0"))
+
+	assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+
+	kinds := make([]EventKind, 0, len(all.events))
+	for _, ev := range all.events {
+		kinds = append(kinds, ev.Kind)
+	}
+	assert.ElementsMatch(t, []EventKind{EventPublished, EventDuplicate, EventDelivered}, kinds,
+		"expected the events of the requested kinds to be reported")
+	assert.Empty(t, defaults.events, "expected only the expired and dropped messages to be reported by default")
+}
+
+func TestObserverCallsBack(t *testing.T) {
+	var (
+		testChannel = "test-channel"
+		e           *eventChannel
+		stats       = make([]SubjectStats, 0, 2)
+		queue       = make([]string, 0, 2)
+	)
+	e = newEventChannel(WithObserver(ObserverFunc(func(ev Event) {
+		stats = append(stats, e.Stats(ev.Subject))
+		if ev.Msg == "ping" {
+			e.Publish(testChannel, "pong")
+		}
+	}), EventPublished))
+
+	e.Subscribe(testChannel, func(msg interface{}) { queue = append(queue, msg.(string)) })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		e.Publish(testChannel, "ping")
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("expected the observer to call the sub-pub system back without the deadlock")
+	}
+
+	assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+	assert.Len(t, stats, 2, "expected the observer to read the stats of the both messages")
+	assert.Equal(t, []string{"ping", "pong"}, queue, "expected the message published by the observer to be delivered")
+}
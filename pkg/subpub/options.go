@@ -0,0 +1,186 @@
+package subpub
+
+import "time"
+
+// OrderMode defines the order of the messages' handling inside the single subscription.
+type OrderMode int
+
+const (
+	// StrictFIFO handles the messages one by one in the order of their publishing.
+	StrictFIFO OrderMode = iota
+
+	// Unordered handles the messages in parallel up to the concurrency limit.
+	Unordered
+
+	// KeyOrdered handles the messages with the same key one by one in the order of their publishing
+	// and the messages with the different keys in parallel up to the concurrency limit.
+	KeyOrdered
+)
+
+// KeyFunc defines the func that extracts the ordering key from the message.
+type KeyFunc func(msg interface{}) string
+
+// OverflowPolicy defines what happens with the message published into the full subscription's queue.
+type OverflowPolicy int
+
+const (
+	// DropNewest drops the message that is being published.
+	DropNewest OverflowPolicy = iota
+
+	// DropOldest drops the oldest message of the queue to free the place for the published one.
+	DropOldest
+)
+
+// subOpts defines the subscription's configuration.
+type subOpts struct {
+	mode OrderMode
+
+	// limit defines the max count of the concurrent handler's calls (0 means no limit).
+	limit int
+
+	key KeyFunc
+
+	// capacity defines the max count of the messages waiting in the queue (0 means no limit).
+	capacity int
+
+	overflow OverflowPolicy
+}
+
+func newSubOpts() subOpts {
+	return subOpts{
+		mode:  StrictFIFO,
+		limit: 1,
+	}
+}
+
+// applySubOpts returns the subscription's configuration built from the opts.
+func applySubOpts(opts []SubscribeOpt) subOpts {
+	o := newSubOpts()
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// SubscribeOpt defines the func of the subscription's configuration.
+type SubscribeOpt func(o *subOpts)
+
+// WithStrictFIFO sets the StrictFIFO mode that is used by default.
+func WithStrictFIFO() SubscribeOpt {
+	return func(o *subOpts) {
+		o.mode = StrictFIFO
+		o.limit = 1
+		o.key = nil
+	}
+}
+
+// WithUnordered sets the Unordered mode with up to n concurrent handler's calls.
+// The non-positive n removes the limit.
+func WithUnordered(n int) SubscribeOpt {
+	return func(o *subOpts) {
+		o.mode = Unordered
+		o.limit = max(n, 0)
+		o.key = nil
+	}
+}
+
+// WithKeyOrdered sets the KeyOrdered mode with the key extracted by the key func
+// and up to n concurrent handler's calls. The non-positive n removes the limit.
+func WithKeyOrdered(key KeyFunc, n int) SubscribeOpt {
+	return func(o *subOpts) {
+		o.mode = KeyOrdered
+		o.limit = max(n, 0)
+		o.key = key
+	}
+}
+
+// WithCapacity limits the count of the messages waiting in the subscription's queue
+// including the messages buffered while the subscription is paused.
+// The policy defines which message is dropped on the overflow. The non-positive n removes the limit.
+func WithCapacity(n int, policy OverflowPolicy) SubscribeOpt {
+	return func(o *subOpts) {
+		o.capacity = max(n, 0)
+		o.overflow = policy
+	}
+}
+
+// pubOpts defines the configuration of the single publishing.
+type pubOpts struct {
+	// ttl defines the time after which the message is discarded instead of the handling (0 means no limit).
+	ttl time.Duration
+
+	// priority defines the message's priority level in the subscriptions' queues.
+	priority int
+
+	// msgID defines the publisher's id of the message used for the deduplication.
+	msgID string
+
+	// duplicate receives whether the message was skipped as the duplicate (nil means it isn't reported).
+	duplicate *bool
+}
+
+// PublishOpt defines the func of the publishing's configuration.
+type PublishOpt func(o *pubOpts)
+
+// applyPubOpts returns the publishing's configuration built from the opts.
+func applyPubOpts(opts []PublishOpt) pubOpts {
+	o := pubOpts{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithTTL sets the message's time to live: the message that wasn't passed to the handler
+// during the ttl is discarded. It overrides the subject's policy TTL.
+func WithTTL(ttl time.Duration) PublishOpt {
+	return func(o *pubOpts) {
+		o.ttl = ttl
+	}
+}
+
+// SubjectPolicy defines the rules that are applied to every message of the subject.
+type SubjectPolicy struct {
+	// TTL defines the default messages' time to live (0 means no limit).
+	TTL time.Duration
+
+	// DedupWindow defines the time during which the messages with the same id are treated
+	// as the duplicates (0 means no limit by the time).
+	DedupWindow time.Duration
+
+	// DedupCount defines the count of the last messages' ids remembered for the deduplication
+	// (0 means no limit by the count).
+	// The deduplication is turned off when both DedupWindow and DedupCount are 0.
+	DedupCount int
+}
+
+// dedup defines whether the deduplication is turned on by the policy.
+func (p SubjectPolicy) dedup() bool {
+	return p.DedupWindow > 0 || p.DedupCount > 0
+}
+
+// WithPriority sets the message's priority level: the messages of the higher level overtake
+// the messages of the lower levels waiting in the subscriptions' queues.
+// The messages of the same level are handled in the FIFO order (0 is the default level).
+func WithPriority(priority int) PublishOpt {
+	return func(o *pubOpts) {
+		o.priority = priority
+	}
+}
+
+// WithMsgID sets the publisher's id of the message: the message with the id that was already
+// published inside the subject's dedup window isn't delivered again, but it's acknowledged:
+// the Publish returns nil and reports the duplicate by the WithDuplicate only.
+func WithMsgID(id string) PublishOpt {
+	return func(o *pubOpts) {
+		o.msgID = id
+	}
+}
+
+// WithDuplicate makes the Publish set the dup to whether the message was skipped as the duplicate
+// inside the subject's dedup window (see WithMsgID). The scheduled publishing doesn't report it.
+func WithDuplicate(dup *bool) PublishOpt {
+	return func(o *pubOpts) {
+		o.duplicate = dup
+	}
+}
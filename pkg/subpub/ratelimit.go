@@ -0,0 +1,425 @@
+package subpub
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateSweepInterval defines the interval of the removing of the idle publishers' and subjects' buckets.
+const rateSweepInterval = time.Minute
+
+// tokenBucket defines the limiter of the messages' rate: the tokens are refilled
+// with the rate per second up to the burst and every message takes one token.
+type tokenBucket struct {
+	clock Clock
+	rate  float64
+	burst float64
+
+	mut    sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket creates the full bucket, the burst isn't less than the single second's rate.
+func newTokenBucket(clock Clock, rate float64, burst int) *tokenBucket {
+	b := &tokenBucket{
+		clock: clock,
+		rate:  rate,
+		burst: float64(burst),
+		last:  clock.Now(),
+	}
+
+	if b.burst < rate {
+		b.burst = rate
+	}
+	if b.burst < 1 {
+		b.burst = 1
+	}
+	b.tokens = b.burst
+
+	return b
+}
+
+// refill adds the tokens of the time elapsed since the last call, the b.mut must be locked.
+func (b *tokenBucket) refill() {
+	now := b.clock.Now()
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+}
+
+// lack returns the time after which the n tokens will be available (zero means they are available now),
+// the b.mut must be locked.
+func (b *tokenBucket) lack(n int) time.Duration {
+	b.refill()
+
+	if lack := float64(n) - b.tokens; lack > 0 {
+		return time.Duration(lack/b.rate*float64(time.Second)) + 1
+	}
+	return 0
+}
+
+// wait returns the time after which the n tokens will be available (zero means they are available now).
+func (b *tokenBucket) wait(n int) time.Duration {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	return b.lack(n)
+}
+
+// take takes the n tokens if they are available, otherwise it returns the time of the waiting for them.
+func (b *tokenBucket) take(n int) (time.Duration, bool) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	if wait := b.lack(n); wait > 0 {
+		return wait, false
+	}
+	b.tokens -= float64(n)
+
+	return 0, true
+}
+
+// full checks whether the bucket was refilled up to the burst, so it may be recreated without the changes.
+func (b *tokenBucket) full() bool {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	b.refill()
+
+	return b.tokens >= b.burst
+}
+
+// RateLimit defines the limit of the messages' rate: the Rate messages per second with the Burst
+// messages at once (the Rate is used if it's greater). The zero Rate means the absence of the limit.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// SubjectRateLimit defines the rate's limit of every subject matching the Pattern (see MatchSubject).
+type SubjectRateLimit struct {
+	Pattern string
+	RateLimit
+}
+
+// RateLimits defines the limits of the publishing's rate.
+type RateLimits struct {
+	// Keys defines the limits of the publishers by the kinds of their keys, e.g. the "client" or the "peer":
+	// every publisher's key of the kind has its own limit.
+	Keys map[string]RateLimit
+
+	// Subjects defines the limits of the subjects: the first matching pattern is applied
+	// and every subject has its own limit.
+	Subjects []SubjectRateLimit
+}
+
+// Validate checks the limits' values without applying them.
+func (l RateLimits) Validate() error {
+	return l.validate("subpub.RateLimits.Validate")
+}
+
+// validate checks the limits' values.
+func (l RateLimits) validate(op string) error {
+	for kind, limit := range l.Keys {
+		if kind == "" {
+			return fmt.Errorf("error of the %s: %w: the kind of the publishers' keys is empty", op, ErrInputData)
+		}
+		if limit.Rate < 0 || limit.Burst < 0 {
+			return fmt.Errorf("error of the %s: %w: the rate's limit of the '%s' must be non-negative", op, ErrInputData, kind)
+		}
+	}
+
+	for _, limit := range l.Subjects {
+		if limit.Pattern == "" {
+			return fmt.Errorf("error of the %s: %w: the subject's pattern is empty", op, ErrInputData)
+		}
+		if limit.Rate < 0 || limit.Burst < 0 {
+			return fmt.Errorf("error of the %s: %w: the rate's limit of the '%s' must be non-negative", op, ErrInputData, limit.Pattern)
+		}
+	}
+	return nil
+}
+
+// subjectLimit returns the limit of the subject's first matching pattern.
+func (l RateLimits) subjectLimit(subject string) (SubjectRateLimit, bool) {
+	for _, limit := range l.Subjects {
+		if MatchSubject(limit.Pattern, subject) {
+			return limit, limit.Rate > 0
+		}
+	}
+	return SubjectRateLimit{}, false
+}
+
+// RateKeys defines the publisher's keys by their kinds, e.g. the client's identity by the "client".
+type RateKeys map[string]string
+
+// RateLimitError is returned when the publishing exceeds the rate's limit, it wraps the ErrLimit.
+type RateLimitError struct {
+	// Limit defines the name of the exceeded limit, e.g. the kind of the key or the subject.
+	Limit string
+
+	// RetryAfter defines the time after which the same publishing will be allowed.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: the rate of the %s was exceeded, retry after %s", ErrLimit, e.Limit, e.RetryAfter)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrLimit
+}
+
+// rateBucketKey defines the key of the publisher's or the subject's bucket.
+type rateBucketKey struct {
+	// kind defines the kind of the publisher's key (empty means the subject's bucket).
+	kind string
+	key  string
+}
+
+// rateBucket defines the bucket of the single publisher or subject with its limit.
+type rateBucket struct {
+	*tokenBucket
+	limit RateLimit
+}
+
+// RateLimiter defines the limiter of the publishing's rate by the publishers' keys and the subjects.
+type RateLimiter struct {
+	clock Clock
+
+	mut    sync.Mutex
+	limits RateLimits
+
+	// buckets stores the buckets of the publishers and the subjects, the full ones are removed periodically.
+	buckets   map[rateBucketKey]rateBucket
+	lastSweep time.Time
+}
+
+// NewRateLimiter creates the limiter of the publishing's rate, the clock is used by the buckets' refilling.
+func NewRateLimiter(clock Clock, limits RateLimits) (*RateLimiter, error) {
+	const op = "subpub.NewRateLimiter"
+
+	if err := limits.validate(op); err != nil {
+		return nil, err
+	}
+
+	if clock == nil {
+		clock = NewRealClock()
+	}
+
+	return &RateLimiter{
+		clock:     clock,
+		limits:    limits,
+		buckets:   make(map[rateBucketKey]rateBucket),
+		lastSweep: clock.Now(),
+	}, nil
+}
+
+// SetLimits changes the limits at runtime: the buckets of the changed limits start full.
+func (l *RateLimiter) SetLimits(limits RateLimits) error {
+	const op = "subpub.RateLimiter.SetLimits"
+
+	if err := limits.validate(op); err != nil {
+		return err
+	}
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.limits = limits
+	for key, bucket := range l.buckets {
+		if limit, ok := l.limitOf(key); !ok || limit != bucket.limit {
+			delete(l.buckets, key)
+		}
+	}
+
+	return nil
+}
+
+// limitOf returns the current limit of the bucket's key, the l.mut must be locked.
+func (l *RateLimiter) limitOf(key rateBucketKey) (RateLimit, bool) {
+	if key.kind == "" {
+		limit, ok := l.limits.subjectLimit(key.key)
+		return limit.RateLimit, ok
+	}
+
+	limit, ok := l.limits.Keys[key.kind]
+	return limit, ok && limit.Rate > 0
+}
+
+// Allow takes the tokens of the publisher with the keys for every message of the subjects
+// (the subject is repeated for every its message): either all the limits allow the messages
+// and they are taken, or nothing is taken and the *RateLimitError is returned.
+func (l *RateLimiter) Allow(keys RateKeys, subjects ...string) error {
+	const op = "subpub.RateLimiter.Allow"
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	l.sweep()
+
+	counts := make(map[rateBucketKey]int, len(keys)+len(subjects))
+	names := make(map[rateBucketKey]string, len(keys)+len(subjects))
+
+	for kind, key := range keys {
+		bucketKey := rateBucketKey{kind: kind, key: key}
+		if _, ok := l.limitOf(bucketKey); ok && kind != "" {
+			counts[bucketKey] = len(subjects)
+			names[bucketKey] = fmt.Sprintf("%s '%s'", kind, key)
+		}
+	}
+
+	for _, subject := range subjects {
+		bucketKey := rateBucketKey{key: subject}
+		if _, ok := l.limitOf(bucketKey); ok {
+			counts[bucketKey]++
+			names[bucketKey] = fmt.Sprintf("subject '%s'", subject)
+		}
+	}
+
+	buckets := make(map[rateBucketKey]rateBucket, len(counts))
+	var exceeded *RateLimitError
+
+	for key, n := range counts {
+		bucket := l.bucket(key)
+		if float64(n) > bucket.burst {
+			return fmt.Errorf("error of the %s: %w: the %d messages exceed the burst of the %s", op, ErrInputData, n, names[key])
+		}
+
+		if wait := bucket.wait(n); wait > 0 && (exceeded == nil || wait > exceeded.RetryAfter) {
+			exceeded = &RateLimitError{Limit: names[key], RetryAfter: wait}
+		}
+		buckets[key] = bucket
+	}
+
+	if exceeded != nil {
+		return fmt.Errorf("error of the %s: %w", op, exceeded)
+	}
+
+	for key, bucket := range buckets {
+		bucket.take(counts[key])
+	}
+	return nil
+}
+
+// bucket returns the bucket of the key creating it if it's absent, the l.mut must be locked.
+func (l *RateLimiter) bucket(key rateBucketKey) rateBucket {
+	bucket, ok := l.buckets[key]
+	if !ok {
+		limit, _ := l.limitOf(key)
+		bucket = rateBucket{
+			tokenBucket: newTokenBucket(l.clock, limit.Rate, limit.Burst),
+			limit:       limit,
+		}
+		l.buckets[key] = bucket
+	}
+	return bucket
+}
+
+// sweep removes the full buckets, they are recreated full by the next messages, the l.mut must be locked.
+func (l *RateLimiter) sweep() {
+	now := l.clock.Now()
+	if now.Sub(l.lastSweep) < rateSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		if bucket.full() {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// MatchSubject checks whether the subject matches the pattern, where the '*' matches any sequence of chars.
+func MatchSubject(pattern, subject string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == subject
+	}
+
+	if !strings.HasPrefix(subject, parts[0]) {
+		return false
+	}
+	subject = subject[len(parts[0]):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		idx := strings.Index(subject, part)
+		if idx < 0 {
+			return false
+		}
+		subject = subject[idx+len(part):]
+	}
+
+	return strings.HasSuffix(subject, parts[len(parts)-1])
+}
+
+// rateLimitedSubPub defines the SubPub whose publishing is limited by the publisher's keys and the subjects.
+type rateLimitedSubPub struct {
+	SubPub
+	limiter *RateLimiter
+	keys    RateKeys
+}
+
+// NewRateLimited returns the SubPub that publishes into the sp as the publisher with the keys
+// under the limiter's limits, the messages over the limits are rejected with the *RateLimitError.
+// The limiter may be shared by the SubPubs of the different publishers, e.g. the clients.
+func NewRateLimited(sp SubPub, limiter *RateLimiter, keys RateKeys) SubPub {
+	return &rateLimitedSubPub{
+		SubPub:  sp,
+		limiter: limiter,
+		keys:    keys,
+	}
+}
+
+// allowTx checks the limits of the transaction's messages.
+func (r *rateLimitedSubPub) allowTx(msgs []SubjectMessage) error {
+	subjects := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		subjects = append(subjects, msg.Subject)
+	}
+	return r.limiter.Allow(r.keys, subjects...)
+}
+
+func (r *rateLimitedSubPub) Publish(subject string, msg interface{}, opts ...PublishOpt) error {
+	if err := r.limiter.Allow(r.keys, subject); err != nil {
+		return err
+	}
+	return r.SubPub.Publish(subject, msg, opts...)
+}
+
+func (r *rateLimitedSubPub) PublishTx(ctx context.Context, msgs []SubjectMessage) error {
+	if err := r.allowTx(msgs); err != nil {
+		return err
+	}
+	return r.SubPub.PublishTx(ctx, msgs)
+}
+
+func (r *rateLimitedSubPub) PublishTxAt(ctx context.Context, msgs []SubjectMessage, at time.Time) (string, error) {
+	if err := r.allowTx(msgs); err != nil {
+		return "", err
+	}
+	return r.SubPub.PublishTxAt(ctx, msgs, at)
+}
+
+func (r *rateLimitedSubPub) PublishAt(subject string, msg interface{}, at time.Time, opts ...PublishOpt) (string, error) {
+	if err := r.limiter.Allow(r.keys, subject); err != nil {
+		return "", err
+	}
+	return r.SubPub.PublishAt(subject, msg, at, opts...)
+}
+
+func (r *rateLimitedSubPub) PublishAfter(subject string, msg interface{}, delay time.Duration, opts ...PublishOpt) (string, error) {
+	if err := r.limiter.Allow(r.keys, subject); err != nil {
+		return "", err
+	}
+	return r.SubPub.PublishAfter(subject, msg, delay, opts...)
+}
@@ -0,0 +1,145 @@
+package subpub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("TestRateLimiterPositiveCases_Keys",
+		func(t *testing.T) {
+			clock := newFakeClock(time.Now())
+			limiter, err := NewRateLimiter(clock, RateLimits{Keys: map[string]RateLimit{"client": {Rate: 1, Burst: 2}}})
+			require.NoError(t, err, "expected nil error after the limiter's creating")
+
+			clientA, clientB := RateKeys{"client": "client-a"}, RateKeys{"client": "client-b"}
+
+			assert.NoError(t, limiter.Allow(clientA, "orders"), "expected nil error inside the burst")
+			assert.NoError(t, limiter.Allow(clientA, "orders"), "expected nil error inside the burst")
+
+			err = limiter.Allow(clientA, "orders")
+			var rateErr *RateLimitError
+			require.True(t, errors.As(err, &rateErr), "expected the error of the rate's limit")
+			assert.ErrorIs(t, err, ErrLimit, "expected the rate's error to wrap the ErrLimit")
+			assert.InDelta(t, time.Second, rateErr.RetryAfter, float64(time.Millisecond), "expected the time of the single token's refilling")
+
+			assert.NoError(t, limiter.Allow(clientB, "orders"), "expected the separate limit of the other client")
+
+			clock.Advance(rateErr.RetryAfter)
+			assert.NoError(t, limiter.Allow(clientA, "orders"), "expected the token to be refilled after the retry-after")
+		})
+
+	t.Run("TestRateLimiterPositiveCases_Subjects",
+		func(t *testing.T) {
+			limiter, err := NewRateLimiter(newFakeClock(time.Now()), RateLimits{Subjects: []SubjectRateLimit{
+				{Pattern: "orders.*", RateLimit: RateLimit{Rate: 1}},
+				{Pattern: "*", RateLimit: RateLimit{Rate: 10}},
+			}})
+			require.NoError(t, err, "expected nil error after the limiter's creating")
+
+			assert.NoError(t, limiter.Allow(nil, "orders.eu"), "expected nil error inside the burst")
+			assert.ErrorIs(t, limiter.Allow(nil, "orders.eu"), ErrLimit, "expected the first matching pattern's limit")
+			assert.NoError(t, limiter.Allow(nil, "orders.us"), "expected the separate limit of every subject")
+
+			assert.NoError(t, limiter.Allow(nil, "logs", "logs", "logs"), "expected the other pattern's limit")
+		})
+
+	t.Run("TestRateLimiterPositiveCases_AllOrNothing",
+		func(t *testing.T) {
+			limiter, _ := NewRateLimiter(newFakeClock(time.Now()), RateLimits{
+				Keys:     map[string]RateLimit{"client": {Rate: 2}},
+				Subjects: []SubjectRateLimit{{Pattern: "orders", RateLimit: RateLimit{Rate: 1}}},
+			})
+			client := RateKeys{"client": "client-a"}
+
+			assert.NoError(t, limiter.Allow(client, "orders"), "expected nil error inside the limits")
+			assert.ErrorIs(t, limiter.Allow(client, "orders"), ErrLimit, "expected the error of the subject's limit")
+			assert.NoError(t, limiter.Allow(client, "billing"), "expected the client's token not to be taken by the rejected message")
+		})
+
+	t.Run("TestRateLimiterPositiveCases_SetLimits",
+		func(t *testing.T) {
+			limiter, _ := NewRateLimiter(newFakeClock(time.Now()), RateLimits{Keys: map[string]RateLimit{"peer": {Rate: 1}}})
+			peer := RateKeys{"peer": "127.0.0.1"}
+
+			assert.NoError(t, limiter.Allow(peer, "orders"), "expected nil error inside the burst")
+			assert.ErrorIs(t, limiter.Allow(peer, "orders"), ErrLimit, "expected the error of the peer's limit")
+
+			assert.NoError(t, limiter.SetLimits(RateLimits{}), "expected nil error of the limits' changing")
+			assert.NoError(t, limiter.Allow(peer, "orders"), "expected the removed limit not to be applied")
+
+			assert.ErrorIs(t, limiter.SetLimits(RateLimits{Keys: map[string]RateLimit{"peer": {Rate: -1}}}), ErrInputData,
+				"expected the error of the negative rate")
+		})
+
+	t.Run("TestRateLimiterNegativeCases_Config",
+		func(t *testing.T) {
+			cases := []RateLimits{
+				{Keys: map[string]RateLimit{"": {Rate: 1}}},
+				{Keys: map[string]RateLimit{"client": {Rate: 1, Burst: -1}}},
+				{Subjects: []SubjectRateLimit{{RateLimit: RateLimit{Rate: 1}}}},
+			}
+
+			for _, limits := range cases {
+				_, err := NewRateLimiter(nil, limits)
+				assert.ErrorIs(t, err, ErrInputData, "expected the error of the limits %v", limits)
+			}
+		})
+
+	t.Run("TestRateLimiterNegativeCases_OverBurst",
+		func(t *testing.T) {
+			limiter, _ := NewRateLimiter(nil, RateLimits{Keys: map[string]RateLimit{"client": {Rate: 1, Burst: 2}}})
+
+			err := limiter.Allow(RateKeys{"client": "client-a"}, "orders", "orders", "orders")
+			assert.ErrorIs(t, err, ErrInputData, "expected the error of the messages that can't fit into the burst")
+		})
+}
+
+func TestRateLimited(t *testing.T) {
+	e := newEventChannel()
+	defer e.Close(context.Background())
+	e.Subscribe("orders", func(interface{}) {})
+
+	limiter, _ := NewRateLimiter(newFakeClock(time.Now()), RateLimits{Keys: map[string]RateLimit{"client": {Rate: 2}}})
+	sp := NewRateLimited(e, limiter, RateKeys{"client": "client-a"})
+
+	assert.NoError(t, sp.Publish("orders", "order-0"), "expected nil error inside the limit")
+
+	err := sp.PublishTx(context.Background(), []SubjectMessage{{Subject: "orders", Msg: "order-1"}, {Subject: "orders", Msg: "order-2"}})
+	var rateErr *RateLimitError
+	assert.True(t, errors.As(err, &rateErr), "expected the transaction to take the token for every message")
+
+	_, err = sp.PublishAfter("orders", "order-3", time.Hour)
+	assert.NoError(t, err, "expected nil error inside the limit")
+
+	_, err = sp.PublishAt("orders", "order-4", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, ErrLimit, "expected the scheduled messages to be limited")
+
+	assert.Equal(t, uint64(1), e.Stats("orders").Published, "expected only the allowed message to be published")
+}
+
+func TestMatchSubject(t *testing.T) {
+	cases := []struct {
+		pattern string
+		subject string
+		match   bool
+	}{
+		{"orders", "orders", true},
+		{"orders", "orders.created", false},
+		{"orders.*", "orders.created", true},
+		{"orders.*", "payments.created", false},
+		{"*.created", "orders.created", true},
+		{"orders.*.eu", "orders.created.eu", true},
+		{"orders.*.eu", "orders.created.us", false},
+		{"*", "anything", true},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.match, MatchSubject(c.pattern, c.subject), "expected the correct matching of the '%s' by the '%s'", c.subject, c.pattern)
+	}
+}
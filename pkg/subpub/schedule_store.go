@@ -0,0 +1,136 @@
+package subpub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileScheduleStore defines the ScheduleStore that keeps the scheduled messages in the JSON file.
+// The messages must be JSON-encodable: after the loading they are decoded into the generic values
+// (e.g. the strings stay the strings).
+type FileScheduleStore struct {
+	path string
+
+	mut sync.Mutex
+
+	// msgs defines the stored messages in the order of their saving.
+	msgs []ScheduledMessage
+}
+
+// NewFileScheduleStore opens the store in the file of the path reading the previously saved messages.
+func NewFileScheduleStore(path string) (*FileScheduleStore, error) {
+	const op = "subpub.NewFileScheduleStore"
+
+	s := &FileScheduleStore{
+		path: path,
+		msgs: make([]ScheduledMessage, 0, 10),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error of the %s: %s", op, err)
+	}
+
+	if err := json.Unmarshal(data, &s.msgs); err != nil {
+		return nil, fmt.Errorf("error of the %s: the file '%s' is corrupted: %s", op, path, err)
+	}
+	return s, nil
+}
+
+// Save stores the scheduled messages with the single rewriting of the file.
+func (s *FileScheduleStore) Save(msgs ...ScheduledMessage) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	prev := s.msgs
+	s.msgs = append(append(make([]ScheduledMessage, 0, len(prev)+len(msgs)), prev...), msgs...)
+
+	if err := s.flush(); err != nil {
+		s.msgs = prev
+		return err
+	}
+	return nil
+}
+
+// Delete removes the scheduled messages by their ids.
+func (s *FileScheduleStore) Delete(ids ...string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	removed := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		removed[id] = struct{}{}
+	}
+
+	msgs := make([]ScheduledMessage, 0, len(s.msgs))
+	for _, msg := range s.msgs {
+		if _, ok := removed[msg.ID]; !ok {
+			msgs = append(msgs, msg)
+		}
+	}
+
+	if len(msgs) == len(s.msgs) {
+		return nil
+	}
+
+	prev := s.msgs
+	s.msgs = msgs
+
+	if err := s.flush(); err != nil {
+		s.msgs = prev
+		return err
+	}
+	return nil
+}
+
+// Load returns all the stored scheduled messages in the order of their saving.
+func (s *FileScheduleStore) Load() ([]ScheduledMessage, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	msgs := make([]ScheduledMessage, len(s.msgs))
+	copy(msgs, s.msgs)
+
+	return msgs, nil
+}
+
+// flush rewrites the file atomically through the temporary file's renaming.
+// Must be called with the mut locked.
+func (s *FileScheduleStore) flush() error {
+	const op = "subpub.flush"
+
+	data, err := json.Marshal(s.msgs)
+	if err != nil {
+		return fmt.Errorf("error of the %s: %s", op, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("error of the %s: %s", op, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error of the %s: %s", op, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error of the %s: %s", op, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error of the %s: %s", op, err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("error of the %s: %s", op, err)
+	}
+	return nil
+}
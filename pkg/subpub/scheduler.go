@@ -0,0 +1,386 @@
+package subpub
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ScheduledMessage defines the message that waits for its publishing time.
+type ScheduledMessage struct {
+	ID      string
+	Subject string
+	Msg     interface{}
+
+	// DeliverAt defines the time of the message's publishing.
+	DeliverAt time.Time
+
+	// TTL defines the message's time to live counted from the DeliverAt (0 means no limit).
+	TTL time.Duration
+
+	// Priority defines the message's priority level.
+	Priority int
+
+	// MsgID defines the publisher's id of the message used for the deduplication.
+	MsgID string
+
+	// TxID defines the id of the transaction the message is published with (empty means no transaction).
+	// The messages of the transaction are cancelled together by this id.
+	TxID string
+}
+
+// opts returns the publishing's options of the scheduled message.
+func (s ScheduledMessage) opts() []PublishOpt {
+	opts := make([]PublishOpt, 0, 3)
+	if s.TTL != 0 {
+		opts = append(opts, WithTTL(s.TTL))
+	}
+	if s.Priority != 0 {
+		opts = append(opts, WithPriority(s.Priority))
+	}
+	if s.MsgID != "" {
+		opts = append(opts, WithMsgID(s.MsgID))
+	}
+	return opts
+}
+
+// ScheduleStore defines the durable storage of the scheduled messages
+// that lets them survive the restarts.
+type ScheduleStore interface {
+	// Save stores the scheduled messages atomically: either all of them are stored or none.
+	Save(msgs ...ScheduledMessage) error
+
+	// Delete removes the scheduled messages by their ids.
+	Delete(ids ...string) error
+
+	// Load returns all the stored scheduled messages in the order of their saving.
+	Load() ([]ScheduledMessage, error)
+}
+
+// scheduledEntry defines the scheduled messages that are published together with their timer.
+type scheduledEntry struct {
+	msgs []ScheduledMessage
+
+	// timer defines the timer of the publishing (nil means the entry waits for the subscription).
+	timer Timer
+
+	// restored defines whether the entry was loaded from the store after the restart.
+	restored bool
+}
+
+// ids returns the ids of the entry's messages.
+func (s *scheduledEntry) ids() []string {
+	ids := make([]string, 0, len(s.msgs))
+	for _, msg := range s.msgs {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+// scheduler defines the logic of the delayed publishing.
+type scheduler struct {
+	bus   *eventChannel
+	store ScheduleStore
+
+	mut sync.Mutex
+
+	// entries defines the scheduled messages by the ids of the messages or of their transactions.
+	entries map[string]*scheduledEntry
+
+	// loadErr defines the error of the store's loading that makes the scheduler unavailable.
+	loadErr error
+
+	// undeleted defines the ids of the published messages that weren't removed from the store:
+	// their removing is retried with the next ones and on the closing.
+	undeleted []string
+}
+
+func newScheduler(bus *eventChannel, store ScheduleStore) *scheduler {
+	s := &scheduler{
+		bus:     bus,
+		store:   store,
+		entries: make(map[string]*scheduledEntry),
+	}
+
+	if store == nil {
+		return s
+	}
+
+	msgs, err := store.Load()
+	if err != nil {
+		s.loadErr = err
+		return s
+	}
+
+	groups := make(map[string][]ScheduledMessage)
+	keys := make([]string, 0, len(msgs))
+
+	for _, msg := range msgs {
+		key := msg.ID
+		if msg.TxID != "" {
+			key = msg.TxID
+		}
+
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], msg)
+	}
+
+	for _, key := range keys {
+		s.start(key, groups[key], true)
+	}
+	return s
+}
+
+// schedule saves the msg and starts its timer.
+func (s *scheduler) schedule(msg ScheduledMessage) (string, error) {
+	const op = "subpub.schedule"
+
+	id, err := s.save(func(id string) []ScheduledMessage {
+		msg.ID = id
+		return []ScheduledMessage{msg}
+	})
+	if err != nil {
+		return "", fmt.Errorf("error of the %s: %w", op, err)
+	}
+	return id, nil
+}
+
+// scheduleTx saves the msgs as the single transaction and starts its timer.
+// The messages get the ids of the "<transaction's id>.<index>" form.
+func (s *scheduler) scheduleTx(msgs []ScheduledMessage) (string, error) {
+	const op = "subpub.scheduleTx"
+
+	id, err := s.save(func(id string) []ScheduledMessage {
+		for i := range msgs {
+			msgs[i].ID = fmt.Sprintf("%s.%d", id, i)
+			msgs[i].TxID = id
+		}
+		return msgs
+	})
+	if err != nil {
+		return "", fmt.Errorf("error of the %s: %w", op, err)
+	}
+	return id, nil
+}
+
+// save generates the new id, stores the messages built by the build and starts their timer.
+func (s *scheduler) save(build func(id string) []ScheduledMessage) (string, error) {
+	if s.loadErr != nil {
+		return "", fmt.Errorf("%w: the scheduled messages weren't loaded: %s", ErrSystemCondition, s.loadErr)
+	}
+
+	id, err := newScheduleID()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrSystemCondition, err)
+	}
+	msgs := build(id)
+
+	if s.store != nil {
+		if err := s.store.Save(msgs...); err != nil {
+			return "", fmt.Errorf("%w: %s", ErrSystemCondition, err)
+		}
+	}
+	s.start(id, msgs, false)
+
+	return id, nil
+}
+
+// start starts the timer of the msgs stored by the key.
+func (s *scheduler) start(key string, msgs []ScheduledMessage, restored bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.entries[key] = &scheduledEntry{
+		msgs:     msgs,
+		timer:    s.bus.clock.AfterFunc(msgs[0].DeliverAt.Sub(s.bus.clock.Now()), func() { s.fire(key) }),
+		restored: restored,
+	}
+}
+
+// fire publishes the scheduled messages when their time comes.
+// The message that can't be published (e.g. the subject has no subscribers) is discarded
+// and reported as the EventDropped, the transaction is discarded as the whole.
+// The restored entry isn't discarded because of the subject's absence: it waits for the subscription
+// since nobody could subscribe before the restored timers fired.
+func (s *scheduler) fire(key string) {
+	s.mut.Lock()
+	entry, ok := s.entries[key]
+	if !ok || s.bus.flagDone.Load() {
+		s.mut.Unlock()
+		return
+	}
+	delete(s.entries, key)
+	s.mut.Unlock()
+
+	var err error
+	if msg := entry.msgs[0]; msg.TxID == "" {
+		err = s.bus.Publish(msg.Subject, msg.Msg, msg.opts()...)
+	} else {
+		tx := make([]SubjectMessage, 0, len(entry.msgs))
+		for _, msg := range entry.msgs {
+			tx = append(tx, SubjectMessage{Subject: msg.Subject, Msg: msg.Msg, Opts: msg.opts()})
+		}
+		err = s.bus.PublishTx(context.Background(), tx)
+	}
+
+	if err != nil && entry.restored && errors.Is(err, ErrInputData) && s.wait(key, entry) {
+		return
+	} else if err != nil {
+		for _, msg := range entry.msgs {
+			s.bus.report(Event{Kind: EventDropped, Subject: msg.Subject, Msg: msg.Msg})
+		}
+	}
+
+	s.remove(entry.ids())
+}
+
+// remove removes the published messages from the store together with the ones that weren't removed before.
+// The ids are kept for the next try if the store fails.
+func (s *scheduler) remove(ids []string) error {
+	if s.store == nil {
+		return nil
+	}
+
+	s.mut.Lock()
+	ids = append(s.undeleted, ids...)
+	s.undeleted = nil
+	s.mut.Unlock()
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if err := s.store.Delete(ids...); err != nil {
+		s.mut.Lock()
+		s.undeleted = append(s.undeleted, ids...)
+		s.mut.Unlock()
+
+		return err
+	}
+	return nil
+}
+
+// wait keeps the entry until the subscription on any of its subjects.
+// The false is returned if the sub-pub system is already closed.
+func (s *scheduler) wait(key string, entry *scheduledEntry) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if s.bus.flagDone.Load() {
+		return false
+	}
+	entry.timer = nil
+	s.entries[key] = entry
+
+	return true
+}
+
+// resume starts the timers of the restored entries that wait for the subscription on the subject.
+func (s *scheduler) resume(subject string) {
+	if s == nil {
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for key, entry := range s.entries {
+		if entry.timer != nil {
+			continue
+		}
+
+		for _, msg := range entry.msgs {
+			if msg.Subject == subject {
+				key := key
+				entry.timer = s.bus.clock.AfterFunc(0, func() { s.fire(key) })
+				break
+			}
+		}
+	}
+}
+
+// cancel removes the scheduled message or the whole transaction by its id.
+func (s *scheduler) cancel(id string) error {
+	const op = "subpub.cancel"
+
+	s.mut.Lock()
+	entry, ok := s.entries[id]
+	if ok {
+		delete(s.entries, id)
+		if entry.timer != nil {
+			entry.timer.Stop()
+		}
+	}
+	s.mut.Unlock()
+
+	if !ok {
+		return fmt.Errorf("error of the %s: %w: try to cancel the unexisting scheduled message '%s'", op, ErrInputData, id)
+	}
+
+	if s.store != nil {
+		if err := s.store.Delete(entry.ids()...); err != nil {
+			return fmt.Errorf("error of the %s: %w: %s", op, ErrSystemCondition, err)
+		}
+	}
+	return nil
+}
+
+// list returns the scheduled messages in the order of their publishing time.
+func (s *scheduler) list() []ScheduledMessage {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	msgs := make([]ScheduledMessage, 0, len(s.entries))
+	for _, entry := range s.entries {
+		msgs = append(msgs, entry.msgs...)
+	}
+
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if msgs[i].DeliverAt.Equal(msgs[j].DeliverAt) {
+			return msgs[i].ID < msgs[j].ID
+		}
+		return msgs[i].DeliverAt.Before(msgs[j].DeliverAt)
+	})
+	return msgs
+}
+
+// stop stops all the timers keeping the messages in the store.
+func (s *scheduler) stop() {
+	if s == nil {
+		return
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	for _, entry := range s.entries {
+		if entry.timer != nil {
+			entry.timer.Stop()
+		}
+	}
+}
+
+// flush retries the removing of the published messages from the store before the closing.
+func (s *scheduler) flush() error {
+	if s == nil {
+		return nil
+	}
+	return s.remove(nil)
+}
+
+// newScheduleID generates the random id of the scheduled message.
+func newScheduleID() (string, error) {
+	buf := make([]byte, 16)
+
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
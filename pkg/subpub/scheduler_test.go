@@ -0,0 +1,251 @@
+package subpub
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishAt(t *testing.T) {
+	t.Run("TestPublishAtPositiveCases_DelayedDelivery",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+				queue       = make([]string, 0, 2)
+			)
+			e := newEventChannel(WithClock(clock))
+
+			e.Subscribe(testChannel, func(msg interface{}) {
+				queue = append(queue, msg.(string))
+			})
+
+			idLater, err := e.PublishAfter(testChannel, "test-message-1", time.Hour)
+			assert.NoError(t, err, "expected nil error after the scheduling")
+
+			idSooner, err := e.PublishAt(testChannel, "test-message-0", clock.Now().Add(time.Minute))
+			assert.NoError(t, err, "expected nil error after the scheduling")
+			assert.NotEqual(t, idLater, idSooner, "expected the unique ids of the scheduled messages")
+
+			scheduled := e.Scheduled()
+			if assert.Len(t, scheduled, 2, "expected both messages to wait for the publishing") {
+				assert.Equal(t, idSooner, scheduled[0].ID, "expected the messages in the order of the publishing time")
+				assert.Equal(t, clock.Now().Add(time.Hour), scheduled[1].DeliverAt, "expected the delay counted from the clock's time")
+			}
+			assert.Equal(t, uint64(0), e.Stats(testChannel).Published, "expected nothing to be published before the time")
+
+			clock.Advance(time.Hour)
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.ElementsMatch(t, []string{"test-message-0", "test-message-1"}, queue, "expected the scheduled messages to be delivered")
+			assert.Empty(t, e.Scheduled(), "expected no messages to wait after the publishing")
+		})
+
+	t.Run("TestPublishAtPositiveCases_Cancel",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Now())
+				delivered   = false
+			)
+			e := newEventChannel(WithClock(clock))
+
+			e.Subscribe(testChannel, func(msg interface{}) {
+				delivered = true
+			})
+
+			id, _ := e.PublishAfter(testChannel, "test-message", time.Minute)
+			assert.NoError(t, e.CancelScheduled(id), "expected nil error after the canceling")
+			assert.Empty(t, e.Scheduled(), "expected the canceled message to be removed")
+
+			clock.Advance(time.Minute)
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.False(t, delivered, "expected the canceled message not to be delivered")
+		})
+
+	t.Run("TestPublishAtPositiveCases_StoreSurvivesRestart",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				path        = filepath.Join(t.TempDir(), "schedule.json")
+				clock       = newFakeClock(time.Now())
+				queue       = make([]string, 0, 1)
+			)
+			store, err := NewFileScheduleStore(path)
+			assert.NoError(t, err, "expected nil error after the store's opening")
+
+			e := newEventChannel(WithClock(clock), WithScheduleStore(store))
+			id, _ := e.PublishAfter(testChannel, "test-message", time.Minute, WithTTL(time.Second))
+			e.Close(context.Background())
+
+			store, err = NewFileScheduleStore(path)
+			assert.NoError(t, err, "expected nil error after the store's reopening")
+
+			clock = newFakeClock(clock.Now())
+			e = newEventChannel(WithClock(clock), WithScheduleStore(store))
+
+			scheduled := e.Scheduled()
+			if assert.Len(t, scheduled, 1, "expected the scheduled message to be restored") {
+				assert.Equal(t, id, scheduled[0].ID, "expected the id to be kept")
+				assert.Equal(t, time.Second, scheduled[0].TTL, "expected the ttl to be kept")
+			}
+
+			e.Subscribe(testChannel, func(msg interface{}) {
+				queue = append(queue, msg.(string))
+			})
+			clock.Advance(time.Minute)
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.Equal(t, []string{"test-message"}, queue, "expected the restored message to be delivered")
+
+			msgs, _ := store.Load()
+			assert.Empty(t, msgs, "expected the delivered message to be removed from the store")
+		})
+
+	t.Run("TestPublishAtPositiveCases_RestoredWaitsForSubscription",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				path        = filepath.Join(t.TempDir(), "schedule.json")
+				clock       = newFakeClock(time.Now())
+				queue       = make([]string, 0, 1)
+				dropped     = 0
+			)
+			store, err := NewFileScheduleStore(path)
+			assert.NoError(t, err, "expected nil error after the store's opening")
+
+			e := newEventChannel(WithClock(clock), WithScheduleStore(store))
+			id, _ := e.PublishAfter(testChannel, "test-message", time.Minute)
+			e.Close(context.Background())
+
+			store, err = NewFileScheduleStore(path)
+			assert.NoError(t, err, "expected nil error after the store's reopening")
+
+			clock = newFakeClock(clock.Now().Add(time.Hour))
+			e = newEventChannel(WithClock(clock), WithScheduleStore(store),
+				WithObserver(ObserverFunc(func(Event) { dropped++ }), EventDropped))
+			clock.Advance(0)
+
+			scheduled := e.Scheduled()
+			if assert.Len(t, scheduled, 1, "expected the past-due message to wait for the subscription") {
+				assert.Equal(t, id, scheduled[0].ID, "expected the id to be kept")
+			}
+			assert.Zero(t, dropped, "expected the waiting message not to be reported as dropped")
+
+			e.Subscribe(testChannel, func(msg interface{}) {
+				queue = append(queue, msg.(string))
+			})
+			clock.Advance(0)
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.Equal(t, []string{"test-message"}, queue, "expected the restored message to be delivered")
+
+			msgs, _ := store.Load()
+			assert.Empty(t, msgs, "expected the delivered message to be removed from the store")
+		})
+
+	t.Run("TestPublishAtPositiveCases_DroppedReported",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Now())
+				events      = make([]Event, 0, 1)
+			)
+			e := newEventChannel(WithClock(clock),
+				WithObserver(ObserverFunc(func(ev Event) { events = append(events, ev) }), EventDropped))
+
+			e.PublishAfter(testChannel, "test-message", time.Minute)
+			clock.Advance(time.Minute)
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.Empty(t, e.Scheduled(), "expected the unpublished message to be discarded")
+			if assert.Len(t, events, 1, "expected the unpublished message to be reported") {
+				assert.Equal(t, EventDropped, events[0].Kind, "expected the EventDropped")
+				assert.Equal(t, testChannel, events[0].Subject, "expected the message's subject")
+				assert.Equal(t, "test-message", events[0].Msg, "expected the unpublished message")
+			}
+		})
+}
+
+// failingStore defines the schedule's store that fails to delete the messages while broken is set.
+type failingStore struct {
+	*FileScheduleStore
+
+	// broken defines whether the deleting fails.
+	broken bool
+}
+
+// Delete defines the logic of the deleting that fails while the store is broken.
+func (f *failingStore) Delete(ids ...string) error {
+	if f.broken {
+		return errors.New("test-error")
+	}
+	return f.FileScheduleStore.Delete(ids...)
+}
+
+func TestPublishAtNegativeCases(t *testing.T) {
+	t.Run("TestPublishAtNegativeCases_DeleteRetried",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Now())
+			)
+			fileStore, err := NewFileScheduleStore(filepath.Join(t.TempDir(), "schedule.json"))
+			assert.NoError(t, err, "expected nil error after the store's opening")
+
+			store := &failingStore{FileScheduleStore: fileStore, broken: true}
+			e := newEventChannel(WithClock(clock), WithScheduleStore(store))
+			e.Subscribe(testChannel, func(msg interface{}) {})
+
+			e.PublishAfter(testChannel, "test-message-1", time.Minute)
+			clock.Advance(time.Minute)
+
+			msgs, _ := store.Load()
+			assert.Len(t, msgs, 1, "expected the published message to be kept after the failed deleting")
+
+			store.broken = false
+			e.PublishAfter(testChannel, "test-message-2", time.Minute)
+			clock.Advance(time.Minute)
+
+			msgs, _ = store.Load()
+			assert.Empty(t, msgs, "expected the failed deleting to be retried with the next message")
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+		})
+
+	t.Run("TestPublishAtNegativeCases_DeleteFailureReported",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = newFakeClock(time.Now())
+			)
+			fileStore, err := NewFileScheduleStore(filepath.Join(t.TempDir(), "schedule.json"))
+			assert.NoError(t, err, "expected nil error after the store's opening")
+
+			store := &failingStore{FileScheduleStore: fileStore, broken: true}
+			e := newEventChannel(WithClock(clock), WithScheduleStore(store))
+			e.Subscribe(testChannel, func(msg interface{}) {})
+
+			e.PublishAfter(testChannel, "test-message", time.Minute)
+			clock.Advance(time.Minute)
+
+			assert.ErrorIs(t, e.Close(context.Background()), ErrSystemCondition,
+				"expected error after closing with the undeleted published message")
+		})
+
+	e := newEventChannel()
+	defer e.Close(context.Background())
+
+	_, err := e.PublishAt("", "test-message", time.Now())
+	assert.ErrorIs(t, err, ErrInputData, "expected error after the scheduling into the empty subject")
+
+	_, err = e.PublishAfter("test-channel", nil, time.Second)
+	assert.ErrorIs(t, err, ErrInputData, "expected error after the scheduling of the nil msg")
+
+	assert.ErrorIs(t, e.CancelScheduled("unexisting-id"), ErrInputData,
+		"expected error after the canceling of the unexisting message")
+}
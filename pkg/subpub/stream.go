@@ -0,0 +1,142 @@
+package subpub
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// chanSub defines the subscription that delivers the messages into the channel.
+type chanSub struct {
+	Subscription
+
+	msgCh chan Message
+
+	// done is closed on the subscription's refusing to release the blocked handlers.
+	done chan struct{}
+	once sync.Once
+
+	// stopping is closed on the sub-pub system's closing: the handlers stop waiting
+	// for the reader and discard the messages that don't fit the channel's buffer.
+	stopping     chan struct{}
+	stoppingOnce sync.Once
+
+	// rwm helps the handlers to finish before the msgCh closing.
+	rwm sync.RWMutex
+
+	// onClose is called once after the msgCh closing.
+	onClose func()
+}
+
+func newChanSub(buf int) *chanSub {
+	return &chanSub{
+		msgCh:    make(chan Message, buf),
+		done:     make(chan struct{}),
+		stopping: make(chan struct{}),
+	}
+}
+
+// handle defines the logic of the message's sending into the channel.
+func (c *chanSub) handle(msg interface{}) {
+	c.rwm.RLock()
+	defer c.rwm.RUnlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.msgCh <- msg:
+	case <-c.done:
+	case <-c.stopping:
+		select {
+		case c.msgCh <- msg:
+		default:
+		}
+	}
+}
+
+// stop makes the handlers stop waiting for the reader before the sub-pub system's closing.
+func (c *chanSub) stop() {
+	c.stoppingOnce.Do(func() {
+		close(c.stopping)
+	})
+}
+
+// Unsubscribe defines the logic of the subscription's refusing and the channel's closing.
+func (c *chanSub) Unsubscribe() {
+	c.Subscription.Unsubscribe()
+	c.close()
+}
+
+// close closes the channel after the running handlers were finished.
+func (c *chanSub) close() {
+	c.once.Do(func() {
+		close(c.done)
+
+		c.rwm.Lock()
+		close(c.msgCh)
+		c.rwm.Unlock()
+
+		if c.onClose != nil {
+			c.onClose()
+		}
+	})
+}
+
+// SubscribeChan defines the logic of the subscription on the subject with the channel's delivery.
+func (e *eventChannel) SubscribeChan(subject string, buf int) (<-chan Message, Subscription, error) {
+	if buf < 0 {
+		buf = 0
+	}
+	c := newChanSub(buf)
+
+	sub, err := e.Subscribe(subject, c.handle)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.Subscription = sub
+	c.onClose = func() {
+		e.mut.Lock()
+		delete(e.chanSubs, c)
+		e.mut.Unlock()
+	}
+
+	e.mut.Lock()
+	e.chanSubs[c] = struct{}{}
+	if e.flagDone.Load() {
+		c.stop()
+	}
+	e.mut.Unlock()
+
+	return c.msgCh, c, nil
+}
+
+// Messages defines the logic of the subscription on the subject with the iterator's delivery.
+func (e *eventChannel) Messages(ctx context.Context, subject string) (iter.Seq[Message], error) {
+	msgCh, sub, err := e.SubscribeChan(subject, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return messagesSeq(ctx, msgCh, sub), nil
+}
+
+// messagesSeq returns the sequence of the msgCh's messages that cancels the sub
+// when the ctx is done or when the consumer stops ranging.
+func messagesSeq(ctx context.Context, msgCh <-chan Message, sub Subscription) iter.Seq[Message] {
+	stop := context.AfterFunc(ctx, sub.Unsubscribe)
+
+	return func(yield func(Message) bool) {
+		defer sub.Unsubscribe()
+		defer stop()
+
+		for msg := range msgCh {
+			if !yield(msg) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package subpub
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeChan(t *testing.T) {
+	t.Run("TestSubscribeChanPositiveCases_QueueOrderCheck",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				testMessage = "test-message"
+			)
+			e := newEventChannel()
+
+			msgCh, sub, err := e.SubscribeChan(testChannel, 0)
+			assert.NoError(t, err, "expected nil error after the right case of SubscribeChan was called")
+
+			for i := 0; i != 5; i++ {
+				e.Publish(testChannel, fmt.Sprintf("%s-%d", testMessage, i))
+			}
+
+			for i := 0; i != 5; i++ {
+				select {
+				case msg := <-msgCh:
+					assert.Equal(t, fmt.Sprintf("%s-%d", testMessage, i), msg,
+						"expected corresponding channel value: actual order is wrong")
+				case <-time.After(time.Second * 5):
+					t.Fatal("expected the message in the channel: actual timeout was reached")
+				}
+			}
+
+			sub.Unsubscribe()
+			_, ok := <-msgCh
+			assert.False(t, ok, "expected the closed channel after the cancelation the sub")
+		})
+
+	t.Run("TestSubscribeChanPositiveCases_CloseReleasesChannels",
+		func(t *testing.T) {
+			e := newEventChannel()
+
+			msgCh, _, _ := e.SubscribeChan("test-channel", 1)
+			e.Publish("test-channel", "test-message")
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.Equal(t, "test-message", <-msgCh, "expected the delivered message to be kept in the channel")
+
+			_, ok := <-msgCh
+			assert.False(t, ok, "expected the closed channel after the event channel closing")
+			assert.Equal(t, 0, len(e.chanSubs), "expected no channel subscriptions after closing")
+		})
+
+	t.Run("TestSubscribeChanPositiveCases_CloseReleasesStalledReader",
+		func(t *testing.T) {
+			e := newEventChannel()
+
+			msgCh, _, _ := e.SubscribeChan("test-channel", 1)
+			for i := 0; i != 5; i++ {
+				e.Publish("test-channel", fmt.Sprintf("test-message-%d", i))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+
+			assert.NoError(t, e.Close(ctx), "expected no waiting for the reader that never drains the channel")
+			assert.Equal(t, "test-message-0", <-msgCh, "expected the buffered message to be kept in the channel")
+
+			_, ok := <-msgCh
+			assert.False(t, ok, "expected the closed channel after the event channel closing")
+		})
+
+	t.Run("TestSubscribeChanPositiveCases_UnsubscribeReleasesBlockedHandler",
+		func(t *testing.T) {
+			e := newEventChannel()
+
+			_, sub, _ := e.SubscribeChan("test-channel", 0)
+			e.Publish("test-channel", "test-message")
+
+			sub.Unsubscribe()
+			assert.NoError(t, e.Close(context.Background()), "expected no blocked handlers after the cancelation the sub")
+		})
+}
+
+func TestSubscribeChanNegativeCases(t *testing.T) {
+	var closedEvenChannel = &eventChannel{}
+	closedEvenChannel.flagDone.Store(true)
+
+	_, _, err := newEventChannel().SubscribeChan("", 0)
+	assert.ErrorIs(t, err, ErrInputData, "expected error after the subscribing on the empty subject")
+
+	_, _, err = closedEvenChannel.SubscribeChan("test-channel", 0)
+	assert.ErrorIs(t, err, ErrSystemCondition, "expected error after the subscribing on the closed event channel")
+}
+
+func TestMessages(t *testing.T) {
+	t.Run("TestMessagesPositiveCases_StopRanging",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				testMessage = "test-message"
+				got         = make([]string, 0, 3)
+			)
+			e := newEventChannel()
+
+			seq, err := e.Messages(context.Background(), testChannel)
+			assert.NoError(t, err, "expected nil error after the right case of Messages was called")
+
+			for i := 0; i != 5; i++ {
+				e.Publish(testChannel, fmt.Sprintf("%s-%d", testMessage, i))
+			}
+
+			for msg := range seq {
+				got = append(got, msg.(string))
+				if len(got) == 3 {
+					break
+				}
+			}
+			assert.Equal(t, []string{"test-message-0", "test-message-1", "test-message-2"}, got,
+				"expected corresponding sequence values: actual order is wrong")
+
+			assert.NoError(t, e.Close(context.Background()), "expected no blocked handlers after the ranging stop")
+			assert.Equal(t, 0, len(e.chanSubs), "expected the subscription's cleanup after the ranging stop")
+		})
+
+	t.Run("TestMessagesPositiveCases_ContextDone",
+		func(t *testing.T) {
+			e := newEventChannel()
+			ctx, cancel := context.WithCancel(context.Background())
+
+			seq, _ := e.Messages(ctx, "test-channel")
+			go func() {
+				e.Publish("test-channel", "test-message")
+				cancel()
+			}()
+
+			count := 0
+			for range seq {
+				count++
+			}
+			assert.LessOrEqual(t, count, 1, "expected the ranging's end after the context's cancelation")
+		})
+}
@@ -1,6 +1,13 @@
 package subpub
 
-import "context"
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// Message defines the data that is published into the subjects.
+type Message = interface{}
 
 // MessageHandler is a callback function that processes messages
 // delivered to subscribers.
@@ -9,20 +16,144 @@ type MessageHandler func(msg interface{})
 type Subscription interface {
 	// Unsubscribe will remove interest in the current subject subscription is for.
 	Unsubscribe()
+
+	// Pause suspends the delivery of the messages to the subscriber keeping its place:
+	// the messages are buffered up to the subscription's capacity under its overflow policy.
+	Pause()
+
+	// Resume continues the delivery of the messages buffered during the pause.
+	Resume()
 }
 
 type SubPub interface {
 	// Subscribe creates an asynchronous queue subscriber on the given subject.
-	Subscribe(subject string, cb MessageHandler) (Subscription, error)
+	// The messages are handled in the StrictFIFO order unless the other OrderMode is set by the opts.
+	Subscribe(subject string, cb MessageHandler, opts ...SubscribeOpt) (Subscription, error)
+
+	// SubscribeChan creates the subscription on the given subject that delivers the messages
+	// into the returned channel with the buf capacity keeping the FIFO order.
+	// The channel is closed after the Unsubscribe call or after the sub-pub system's closing.
+	// On the closing the messages that don't fit the channel's buffer are discarded instead of waiting for the reader.
+	SubscribeChan(subject string, buf int) (<-chan Message, Subscription, error)
+
+	// Messages creates the subscription on the given subject and returns the sequence of its messages.
+	// The subscription is cancelled when the ctx is done or when the consumer stops ranging.
+	// The sequence may be ranged only once.
+	Messages(ctx context.Context, subject string) (iter.Seq[Message], error)
 
 	// Publish publishes the msg argument to the given subject.
-	Publish(subject string, msg interface{}) error
+	// The message with the id (see WithMsgID) that was already published inside the subject's
+	// dedup window is acknowledged without the error, but isn't delivered again (see WithDuplicate).
+	Publish(subject string, msg interface{}, opts ...PublishOpt) error
+
+	// PublishTx publishes the messages into their subjects all together or not at all:
+	// every subject is checked first and then all the messages are put into the queues
+	// before the handling of any of them is started. The duplicates inside the subjects'
+	// dedup windows are skipped without the error.
+	PublishTx(ctx context.Context, msgs []SubjectMessage) error
+
+	// PublishTxAt schedules the PublishTx of the messages at the definite time and returns
+	// the id of the transaction. With the durable store the messages are saved atomically.
+	PublishTxAt(ctx context.Context, msgs []SubjectMessage, at time.Time) (string, error)
+
+	// PublishAt schedules the publishing of the msg to the given subject at the definite time
+	// and returns the id of the scheduled message. The message that can't be published at its time
+	// (e.g. the subject has no subscribers) is discarded and reported as the EventDropped.
+	// The message restored from the store waits for the subscription on its subject instead.
+	PublishAt(subject string, msg interface{}, at time.Time, opts ...PublishOpt) (string, error)
+
+	// PublishAfter schedules the publishing of the msg to the given subject after the delay
+	// and returns the id of the scheduled message.
+	PublishAfter(subject string, msg interface{}, delay time.Duration, opts ...PublishOpt) (string, error)
+
+	// Scheduled returns the messages that wait for their publishing time.
+	Scheduled() []ScheduledMessage
+
+	// CancelScheduled cancels the publishing of the scheduled message by its id
+	// or of the whole scheduled transaction by the transaction's id.
+	CancelScheduled(id string) error
+
+	// Subjects returns the sorted names of the subjects that have the active subscriptions.
+	Subjects() []string
+
+	// Purge drops the messages waiting in the queues of the subject's subscriptions
+	// and returns their count. The dropped messages are counted and reported as the EventDropped.
+	Purge(subject string) int
+
+	// Stats returns the counters of the given subject's messages.
+	// The counters and the dedup window of the subject are removed after its last subscription's refusing
+	// once the window's ids expire, the window without the time limit is kept.
+	Stats(subject string) SubjectStats
+
+	// SetPolicy changes the policy of the subject's next messages at runtime, the empty subject
+	// changes the default policy. The remembered messages' ids are kept within the new dedup limits.
+	SetPolicy(subject string, policy SubjectPolicy) error
+
+	// ResetPolicy removes the subject's own policy, the default policy is applied to its next messages.
+	ResetPolicy(subject string) error
 
 	// Close will shutdown the sub-pub system.
 	// May be blocked by data delivery untill the context is canceled.
+	// The messages buffered by the paused subscriptions aren't waited for.
+	// The scheduled messages are kept in the schedule's store untill the next start.
+	// Returns the ErrSystemCondition if the published scheduled messages weren't removed from the store.
 	Close(ctx context.Context) error
 }
 
-func NewSubPub() SubPub {
-	return newEventChannel()
+// SubPubOpt defines the func of the sub-pub system's configuration.
+type SubPubOpt func(e *eventChannel)
+
+// WithClock sets the source of the time for the sub-pub system.
+// The system's time is used by default.
+func WithClock(clock Clock) SubPubOpt {
+	return func(e *eventChannel) {
+		if clock != nil {
+			e.clock = clock
+		}
+	}
+}
+
+// WithSubjectPolicy sets the policy for the every message of the subject.
+func WithSubjectPolicy(subject string, policy SubjectPolicy) SubPubOpt {
+	return func(e *eventChannel) {
+		e.policies[subject] = policy
+	}
+}
+
+// WithDefaultPolicy sets the policy for the messages of the subjects that have no their own policy.
+func WithDefaultPolicy(policy SubjectPolicy) SubPubOpt {
+	return func(e *eventChannel) {
+		e.defaultPolicy = policy
+	}
+}
+
+// WithObserver adds the receiver of the messages' lifecycle events of the given kinds.
+// Only the EventExpired and the EventDropped are reported if the kinds aren't set.
+func WithObserver(obs Observer, kinds ...EventKind) SubPubOpt {
+	return func(e *eventChannel) {
+		if obs == nil {
+			return
+		}
+		if len(kinds) == 0 {
+			kinds = defaultEventKinds
+		}
+
+		entry := observerEntry{obs: obs}
+		for _, kind := range kinds {
+			entry.kinds |= 1 << kind
+		}
+		e.observers = append(e.observers, entry)
+	}
+}
+
+// WithScheduleStore sets the durable storage of the scheduled messages that lets them survive the restarts.
+// By default the scheduled messages are kept in the memory only.
+func WithScheduleStore(store ScheduleStore) SubPubOpt {
+	return func(e *eventChannel) {
+		e.scheduleStore = store
+	}
+}
+
+func NewSubPub(opts ...SubPubOpt) SubPub {
+	return newEventChannel(opts...)
 }
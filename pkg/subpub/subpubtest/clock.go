@@ -0,0 +1,122 @@
+package subpubtest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+)
+
+var _ subpub.Clock = (*FakeClock)(nil)
+
+// fakeTimer defines the timer of the FakeClock.
+type fakeTimer struct {
+	clock *FakeClock
+
+	deadline time.Time
+	f        func()
+
+	// seq defines the order of the timers with the same deadline.
+	seq uint64
+}
+
+// Stop prevents the timer from firing.
+func (t *fakeTimer) Stop() bool {
+	t.clock.mut.Lock()
+	defer t.clock.mut.Unlock()
+
+	for i, timer := range t.clock.timers {
+		if timer == t {
+			t.clock.timers = append(t.clock.timers[:i], t.clock.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// FakeClock is the subpub.Clock that changes its time only on the Advance and Set calls.
+// The timers are fired right inside these calls in the order of their deadlines,
+// so the time-dependent logic is tested without the real sleeping.
+type FakeClock struct {
+	mut sync.Mutex
+	now time.Time
+
+	timers  []*fakeTimer
+	lastSeq uint64
+}
+
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{
+		now: start,
+	}
+}
+
+// Now returns the current fake time.
+func (c *FakeClock) Now() time.Time {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return c.now
+}
+
+// AfterFunc creates the timer that calls the f when the fake time reaches the deadline.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) subpub.Timer {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.lastSeq++
+	t := &fakeTimer{
+		clock:    c,
+		deadline: c.now.Add(d),
+		f:        f,
+		seq:      c.lastSeq,
+	}
+
+	c.timers = append(c.timers, t)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		if c.timers[i].deadline.Equal(c.timers[j].deadline) {
+			return c.timers[i].seq < c.timers[j].seq
+		}
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+
+	return t
+}
+
+// Advance moves the fake time forward by the d duration firing the expired timers.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the fake time to the t firing the expired timers.
+// The timers created by the fired funcs are fired too if they are expired.
+func (c *FakeClock) Set(t time.Time) {
+	for {
+		c.mut.Lock()
+		if len(c.timers) == 0 || c.timers[0].deadline.After(t) {
+			if t.After(c.now) {
+				c.now = t
+			}
+			c.mut.Unlock()
+			return
+		}
+
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		if timer.deadline.After(c.now) {
+			c.now = timer.deadline
+		}
+		c.mut.Unlock()
+
+		timer.f()
+	}
+}
+
+// Timers returns the count of the timers that haven't fired yet.
+func (c *FakeClock) Timers() int {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return len(c.timers)
+}
@@ -0,0 +1,60 @@
+package subpubtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeClock(t *testing.T) {
+	t.Run("TestFakeClockPositiveCases_FiringOrder",
+		func(t *testing.T) {
+			var (
+				start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+				fired = make([]string, 0, 3)
+			)
+			c := NewFakeClock(start)
+
+			c.AfterFunc(time.Second*2, func() { fired = append(fired, "second") })
+			c.AfterFunc(time.Second, func() { fired = append(fired, "first") })
+			c.AfterFunc(time.Second*5, func() { fired = append(fired, "third") })
+
+			c.Advance(time.Second * 3)
+			assert.Equal(t, []string{"first", "second"}, fired, "expected only the expired timers to be fired in the deadlines' order")
+			assert.Equal(t, start.Add(time.Second*3), c.Now(), "expected the time to be moved by the advance")
+			assert.Equal(t, 1, c.Timers(), "expected the single timer to wait for the firing")
+
+			c.Advance(time.Second * 2)
+			assert.Equal(t, []string{"first", "second", "third"}, fired, "expected all the timers to be fired")
+		})
+
+	t.Run("TestFakeClockPositiveCases_Stop",
+		func(t *testing.T) {
+			fired := false
+			c := NewFakeClock(time.Now())
+
+			timer := c.AfterFunc(time.Second, func() { fired = true })
+			assert.True(t, timer.Stop(), "expected the active timer to be stopped")
+			assert.False(t, timer.Stop(), "expected false after the second stop")
+
+			c.Advance(time.Minute)
+			assert.False(t, fired, "expected the stopped timer not to be fired")
+		})
+
+	t.Run("TestFakeClockPositiveCases_NestedTimers",
+		func(t *testing.T) {
+			count := 0
+			c := NewFakeClock(time.Now())
+
+			var tick func()
+			tick = func() {
+				count++
+				c.AfterFunc(time.Second, tick)
+			}
+			c.AfterFunc(time.Second, tick)
+
+			c.Advance(time.Second * 3)
+			assert.Equal(t, 3, count, "expected the timers created by the fired funcs to be fired too")
+		})
+}
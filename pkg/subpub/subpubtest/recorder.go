@@ -0,0 +1,139 @@
+package subpubtest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+)
+
+// Record defines the single message that was caught by the Recorder.
+type Record struct {
+	Subject string
+	Msg     interface{}
+}
+
+// Recorder defines the thread-safe storage of the messages grouped by the subjects.
+// It may be filled by the fake SubPub or by the handlers returned from the Handler method,
+// so it's suitable for the real subpub.SubPub as well.
+type Recorder struct {
+	mut     sync.Mutex
+	records []Record
+
+	// notify is closed and replaced on every new record to wake up the waiters.
+	notify chan struct{}
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		records: make([]Record, 0, 10),
+		notify:  make(chan struct{}),
+	}
+}
+
+// Record saves the msg for the subject.
+func (r *Recorder) Record(subject string, msg interface{}) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.records = append(r.records, Record{Subject: subject, Msg: msg})
+
+	close(r.notify)
+	r.notify = make(chan struct{})
+}
+
+// Handler returns the handler that records every delivered message for the subject.
+func (r *Recorder) Handler(subject string) subpub.MessageHandler {
+	return func(msg interface{}) {
+		r.Record(subject, msg)
+	}
+}
+
+// Published returns the messages recorded for the subject in the order of their recording.
+func (r *Recorder) Published(subject string) []interface{} {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	msgs := make([]interface{}, 0, len(r.records))
+	for _, rec := range r.records {
+		if rec.Subject == subject {
+			msgs = append(msgs, rec.Msg)
+		}
+	}
+	return msgs
+}
+
+// Records returns all the recorded messages in the order of their recording.
+func (r *Recorder) Records() []Record {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	return append([]Record(nil), r.records...)
+}
+
+// Len returns the count of all the recorded messages.
+func (r *Recorder) Len() int {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	return len(r.records)
+}
+
+// Reset removes all the recorded messages.
+func (r *Recorder) Reset() {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.records = r.records[:0]
+}
+
+// WaitForMessages blocks until at least n messages are recorded or the ctx is done.
+func (r *Recorder) WaitForMessages(ctx context.Context, n int) error {
+	const op = "subpubtest.WaitForMessages"
+
+	for {
+		r.mut.Lock()
+		count, notify := len(r.records), r.notify
+		r.mut.Unlock()
+
+		if count >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error of the %s: %d of %d messages were recorded: %w", op, count, n, ctx.Err())
+		case <-notify:
+		}
+	}
+}
+
+// TestingT defines the part of the *testing.T that is used by the assertions.
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// AssertPublished checks that exactly the want messages were recorded for the subject in the same order.
+func AssertPublished(t TestingT, r *Recorder, subject string, want ...interface{}) bool {
+	t.Helper()
+
+	got := r.Published(subject)
+	if len(want) == 0 {
+		want = []interface{}{}
+	}
+
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("unexpected messages on the subject '%s':\nExp: %v <=> Got: %v", subject, want, got)
+		return false
+	}
+	return true
+}
+
+// AssertNotPublished checks that no messages were recorded for the subject.
+func AssertNotPublished(t TestingT, r *Recorder, subject string) bool {
+	t.Helper()
+	return AssertPublished(t, r, subject)
+}
@@ -0,0 +1,79 @@
+package subpubtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockT defines the TestingT that catches the assertions' failures.
+type mockT struct {
+	failed bool
+}
+
+func (m *mockT) Helper() {}
+
+func (m *mockT) Errorf(format string, args ...interface{}) {
+	m.failed = true
+}
+
+func TestWaitForMessages(t *testing.T) {
+	t.Run("TestWaitForMessagesPositiveCases_RealSubPub",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				testMessage = "test-message"
+			)
+			r := NewRecorder()
+			sp := subpub.NewSubPub()
+			defer sp.Close(context.Background())
+
+			sp.Subscribe(testChannel, r.Handler(testChannel))
+			for i := 0; i != 5; i++ {
+				sp.Publish(testChannel, fmt.Sprintf("%s-%d", testMessage, i))
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+			defer cancel()
+
+			assert.NoError(t, r.WaitForMessages(ctx, 5), "expected all the messages to be delivered")
+			AssertPublished(t, r, testChannel,
+				"test-message-0", "test-message-1", "test-message-2", "test-message-3", "test-message-4")
+		})
+
+	t.Run("TestWaitForMessagesNegativeCases_ContextDone",
+		func(t *testing.T) {
+			r := NewRecorder()
+			r.Record("test-channel", "test-message")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			assert.ErrorIs(t, r.WaitForMessages(ctx, 2), context.Canceled,
+				"expected the context's error when not enough messages were recorded")
+		})
+}
+
+func TestAssertPublished(t *testing.T) {
+	r := NewRecorder()
+	r.Record("test-channel-1", "test-message-1")
+	r.Record("test-channel-2", "test-message-2")
+
+	m := &mockT{}
+	assert.True(t, AssertPublished(m, r, "test-channel-1", "test-message-1"))
+	assert.False(t, m.failed, "expected no failure on the matched messages")
+
+	assert.False(t, AssertPublished(m, r, "test-channel-1", "test-message-2"))
+	assert.True(t, m.failed, "expected the failure on the mismatched messages")
+
+	m = &mockT{}
+	assert.False(t, AssertNotPublished(m, r, "test-channel-2"))
+	assert.True(t, m.failed, "expected the failure on the recorded subject")
+
+	r.Reset()
+	assert.Equal(t, 0, r.Len(), "expected no records after the reset")
+}
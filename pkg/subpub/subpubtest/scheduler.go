@@ -0,0 +1,167 @@
+package subpubtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+)
+
+// scheduledEntry defines the fake's scheduled messages that are published together with their timer.
+type scheduledEntry struct {
+	msgs  []subpub.ScheduledMessage
+	opts  [][]subpub.PublishOpt
+	timer subpub.Timer
+}
+
+// PublishAt schedules the publishing of the msg on the fake's clock.
+// The ids of the scheduled messages are the sequential numbers starting from "1".
+func (s *SubPub) PublishAt(subject string, msg interface{}, at time.Time, opts ...subpub.PublishOpt) (string, error) {
+	const op = "subpubtest.PublishAt"
+
+	if s.flagDone.Load() {
+		return "", fmt.Errorf("error of the %s: %w: try to publish after the work done", op, subpub.ErrSystemCondition)
+	} else if subject == "" {
+		return "", fmt.Errorf("error of the %s: %w: try to publish into the empty subject", op, subpub.ErrInputData)
+	} else if msg == nil {
+		return "", fmt.Errorf("error of the %s: %w: try to publish the nil msg", op, subpub.ErrInputData)
+	}
+
+	return s.schedule(func(id string) *scheduledEntry {
+		return &scheduledEntry{
+			msgs: []subpub.ScheduledMessage{{
+				ID:        id,
+				Subject:   subject,
+				Msg:       msg,
+				DeliverAt: at,
+			}},
+			opts: [][]subpub.PublishOpt{opts},
+		}
+	}, at), nil
+}
+
+// PublishAfter schedules the publishing of the msg after the delay on the fake's clock.
+func (s *SubPub) PublishAfter(subject string, msg interface{}, delay time.Duration, opts ...subpub.PublishOpt) (string, error) {
+	return s.PublishAt(subject, msg, s.clock.Now().Add(delay), opts...)
+}
+
+// PublishTxAt schedules the PublishTx of the msgs on the fake's clock.
+// The messages get the ids of the "<transaction's id>.<index>" form.
+func (s *SubPub) PublishTxAt(ctx context.Context, msgs []subpub.SubjectMessage, at time.Time) (string, error) {
+	const op = "subpubtest.PublishTxAt"
+
+	if s.flagDone.Load() {
+		return "", fmt.Errorf("error of the %s: %w: try to publish after the work done", op, subpub.ErrSystemCondition)
+	} else if len(msgs) == 0 {
+		return "", fmt.Errorf("error of the %s: %w: try to publish the empty transaction", op, subpub.ErrInputData)
+	} else if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("error of the %s: %w: %s", op, subpub.ErrSystemCondition, err)
+	}
+
+	for i, m := range msgs {
+		if m.Subject == "" {
+			return "", fmt.Errorf("error of the %s: %w: try to publish the message %d into the empty subject", op, subpub.ErrInputData, i)
+		} else if m.Msg == nil {
+			return "", fmt.Errorf("error of the %s: %w: try to publish the nil msg %d", op, subpub.ErrInputData, i)
+		}
+	}
+
+	return s.schedule(func(id string) *scheduledEntry {
+		entry := &scheduledEntry{
+			msgs: make([]subpub.ScheduledMessage, 0, len(msgs)),
+			opts: make([][]subpub.PublishOpt, 0, len(msgs)),
+		}
+
+		for i, m := range msgs {
+			entry.msgs = append(entry.msgs, subpub.ScheduledMessage{
+				ID:        fmt.Sprintf("%s.%d", id, i),
+				Subject:   m.Subject,
+				Msg:       m.Msg,
+				DeliverAt: at,
+				TxID:      id,
+			})
+			entry.opts = append(entry.opts, m.Opts)
+		}
+		return entry
+	}, at), nil
+}
+
+// schedule stores the entry built by the build under the new id and starts its timer.
+func (s *SubPub) schedule(build func(id string) *scheduledEntry, at time.Time) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.lastScheduleID++
+	id := strconv.Itoa(s.lastScheduleID)
+
+	entry := build(id)
+	entry.timer = s.clock.AfterFunc(at.Sub(s.clock.Now()), func() {
+		s.fire(id)
+	})
+	s.scheduled[id] = entry
+
+	return id
+}
+
+// Scheduled returns the messages that wait for their publishing time.
+func (s *SubPub) Scheduled() []subpub.ScheduledMessage {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	msgs := make([]subpub.ScheduledMessage, 0, len(s.scheduled))
+	for _, entry := range s.scheduled {
+		msgs = append(msgs, entry.msgs...)
+	}
+
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if msgs[i].DeliverAt.Equal(msgs[j].DeliverAt) {
+			return msgs[i].ID < msgs[j].ID
+		}
+		return msgs[i].DeliverAt.Before(msgs[j].DeliverAt)
+	})
+	return msgs
+}
+
+// CancelScheduled cancels the publishing of the scheduled message or transaction by its id.
+func (s *SubPub) CancelScheduled(id string) error {
+	const op = "subpubtest.CancelScheduled"
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	entry, ok := s.scheduled[id]
+	if !ok {
+		return fmt.Errorf("error of the %s: %w: try to cancel the unexisting scheduled message '%s'", op, subpub.ErrInputData, id)
+	}
+
+	entry.timer.Stop()
+	delete(s.scheduled, id)
+
+	return nil
+}
+
+// fire publishes the scheduled messages when their time comes.
+func (s *SubPub) fire(id string) {
+	s.mut.Lock()
+	entry, ok := s.scheduled[id]
+	delete(s.scheduled, id)
+	s.mut.Unlock()
+
+	if !ok {
+		return
+	}
+
+	if msg := entry.msgs[0]; msg.TxID == "" {
+		s.Publish(msg.Subject, msg.Msg, entry.opts[0]...)
+		return
+	}
+
+	tx := make([]subpub.SubjectMessage, 0, len(entry.msgs))
+	for i, msg := range entry.msgs {
+		tx = append(tx, subpub.SubjectMessage{Subject: msg.Subject, Msg: msg.Msg, Opts: entry.opts[i]})
+	}
+	s.PublishTx(context.Background(), tx)
+}
@@ -0,0 +1,37 @@
+package subpubtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishAt(t *testing.T) {
+	t.Run("TestPublishAtPositiveCases_FakeClock",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				clock       = NewFakeClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+			)
+			s := New(WithClock(clock))
+
+			s.Subscribe(testChannel, func(msg interface{}) {})
+
+			id, err := s.PublishAfter(testChannel, "test-message-0", time.Minute)
+			assert.NoError(t, err, "expected nil error after the scheduling")
+			assert.Equal(t, "1", id, "expected the sequential ids of the scheduled messages")
+
+			canceled, _ := s.PublishAfter(testChannel, "test-message-1", time.Minute)
+			assert.NoError(t, s.CancelScheduled(canceled), "expected nil error after the canceling")
+			assert.Error(t, s.CancelScheduled(canceled), "expected error after the second canceling")
+
+			assert.Len(t, s.Scheduled(), 1, "expected the single message to wait for the publishing")
+			AssertNotPublished(t, s.Recorder, testChannel)
+
+			clock.Advance(time.Minute)
+
+			AssertPublished(t, s.Recorder, testChannel, "test-message-0")
+			assert.Empty(t, s.Scheduled(), "expected no messages to wait after the publishing")
+		})
+}
@@ -0,0 +1,96 @@
+package subpubtest
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+)
+
+// chanSub defines the fake's subscription that delivers the messages into the channel.
+// Because of the synchronous delivery the Publish call is blocked while the channel is full.
+type chanSub struct {
+	subpub.Subscription
+
+	msgCh chan subpub.Message
+	done  chan struct{}
+	once  sync.Once
+	rwm   sync.RWMutex
+}
+
+func (c *chanSub) handle(msg interface{}) {
+	c.rwm.RLock()
+	defer c.rwm.RUnlock()
+
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.msgCh <- msg:
+	case <-c.done:
+	}
+}
+
+// Unsubscribe defines the logic of the subscription's refusing and the channel's closing.
+func (c *chanSub) Unsubscribe() {
+	c.Subscription.Unsubscribe()
+
+	c.once.Do(func() {
+		close(c.done)
+
+		c.rwm.Lock()
+		close(c.msgCh)
+		c.rwm.Unlock()
+	})
+}
+
+// SubscribeChan defines the logic of the subscription on the subject with the channel's delivery.
+func (s *SubPub) SubscribeChan(subject string, buf int) (<-chan subpub.Message, subpub.Subscription, error) {
+	if buf < 0 {
+		buf = 0
+	}
+	c := &chanSub{
+		msgCh: make(chan subpub.Message, buf),
+		done:  make(chan struct{}),
+	}
+
+	sub, err := s.Subscribe(subject, c.handle)
+	if err != nil {
+		return nil, nil, err
+	}
+	c.Subscription = sub
+
+	s.mut.Lock()
+	s.chanSubs = append(s.chanSubs, c)
+	s.mut.Unlock()
+
+	return c.msgCh, c, nil
+}
+
+// messagesBuf defines the capacity of the Messages' channel that lets the synchronous
+// Publish calls not to wait for the consumer's ranging.
+const messagesBuf = 1024
+
+// Messages defines the logic of the subscription on the subject with the iterator's delivery.
+func (s *SubPub) Messages(ctx context.Context, subject string) (iter.Seq[subpub.Message], error) {
+	msgCh, sub, err := s.SubscribeChan(subject, messagesBuf)
+	if err != nil {
+		return nil, err
+	}
+	stop := context.AfterFunc(ctx, sub.Unsubscribe)
+
+	return func(yield func(subpub.Message) bool) {
+		defer sub.Unsubscribe()
+		defer stop()
+
+		for msg := range msgCh {
+			if !yield(msg) {
+				return
+			}
+		}
+	}, nil
+}
@@ -0,0 +1,43 @@
+package subpubtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribeChan(t *testing.T) {
+	s := New()
+
+	msgCh, sub, err := s.SubscribeChan("test-channel", 2)
+	assert.NoError(t, err, "expected nil error after the right case of SubscribeChan was called")
+
+	s.Publish("test-channel", "test-message-0")
+	s.Publish("test-channel", "test-message-1")
+
+	assert.Equal(t, "test-message-0", <-msgCh, "expected corresponding channel value: actual order is wrong")
+	assert.Equal(t, "test-message-1", <-msgCh, "expected corresponding channel value: actual order is wrong")
+
+	sub.Unsubscribe()
+	_, ok := <-msgCh
+	assert.False(t, ok, "expected the closed channel after the cancelation the sub")
+}
+
+func TestMessages(t *testing.T) {
+	s := New()
+	got := make([]interface{}, 0, 2)
+
+	seq, err := s.Messages(context.Background(), "test-channel")
+	assert.NoError(t, err, "expected nil error after the right case of Messages was called")
+
+	s.Publish("test-channel", "test-message-0")
+	s.Publish("test-channel", "test-message-1")
+	s.Close(context.Background())
+
+	for msg := range seq {
+		got = append(got, msg)
+	}
+	assert.Equal(t, []interface{}{"test-message-0", "test-message-1"}, got,
+		"expected all the messages before the closing: actual sequence is wrong")
+}
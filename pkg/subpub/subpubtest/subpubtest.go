@@ -0,0 +1,411 @@
+// Package subpubtest provides the utilities for testing the code that depends on subpub.SubPub
+// without sleeping and waiting for the goroutines.
+//
+// The fake ignores the publishing's options (WithMsgID, WithDuplicate, WithTTL and WithPriority)
+// and the subjects' policies: every published message is delivered in the publishing's order,
+// the duplicates aren't skipped and the messages never expire.
+package subpubtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+)
+
+var _ subpub.SubPub = (*SubPub)(nil)
+
+// subscription defines the fake's subscription on the subject.
+type subscription struct {
+	handler subpub.MessageHandler
+
+	// flagSub defines whether the current subscription is still active.
+	flagSub atomic.Bool
+
+	mut sync.Mutex
+
+	// paused defines whether the deliveries are buffered untill the Resume call.
+	paused   bool
+	buffered []interface{}
+
+	// delivered defines the count of the subject's handlers' calls.
+	delivered *atomic.Uint64
+}
+
+// Unsubscribe defines the logic of the subscription's refusing.
+func (s *subscription) Unsubscribe() {
+	s.flagSub.Store(false)
+
+	s.mut.Lock()
+	s.buffered = nil
+	s.mut.Unlock()
+}
+
+// Pause makes the subscription buffer the deliveries untill the Resume call.
+func (s *subscription) Pause() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.paused = true
+}
+
+// Resume delivers the buffered messages right inside the call.
+func (s *subscription) Resume() {
+	s.mut.Lock()
+	buffered := s.buffered
+	s.paused, s.buffered = false, nil
+	s.mut.Unlock()
+
+	for _, msg := range buffered {
+		deliver(s, msg)
+	}
+}
+
+// delivery defines the message that waits for the manual flush.
+type delivery struct {
+	sub *subscription
+	msg interface{}
+}
+
+// SubPub is the synchronous and deterministic in-memory implementation of subpub.SubPub.
+// By default every message is delivered to the handlers right inside the Publish call,
+// in the order of the subscriptions. With the ManualFlush option the messages are queued
+// untill the Flush method is called.
+//
+// Every successfully published message is recorded by the embedded Recorder.
+type SubPub struct {
+	*Recorder
+
+	mut  sync.Mutex
+	subs map[string][]*subscription
+
+	// manual defines whether the deliveries wait for the Flush call.
+	manual  bool
+	pending []delivery
+
+	// chanSubs defines the channel's subscriptions that are closed after the work done.
+	chanSubs []*chanSub
+
+	// delivered defines the count of the handlers' calls by the subjects.
+	delivered map[string]*atomic.Uint64
+
+	// clock defines the time source of the scheduled messages.
+	clock subpub.Clock
+
+	scheduled      map[string]*scheduledEntry
+	lastScheduleID int
+
+	flagDone atomic.Bool
+}
+
+// Opt defines the func of the fake's configuration.
+type Opt func(s *SubPub)
+
+// ManualFlush makes the fake queue the deliveries untill the Flush method is called.
+func ManualFlush(s *SubPub) {
+	s.manual = true
+}
+
+// WithClock sets the time source of the scheduled messages, e.g. the FakeClock.
+// The system's time is used by default.
+func WithClock(clock subpub.Clock) Opt {
+	return func(s *SubPub) {
+		s.clock = clock
+	}
+}
+
+func New(opts ...Opt) *SubPub {
+	s := &SubPub{
+		Recorder:  NewRecorder(),
+		subs:      make(map[string][]*subscription),
+		delivered: make(map[string]*atomic.Uint64),
+		clock:     subpub.NewRealClock(),
+		scheduled: make(map[string]*scheduledEntry),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Subscribe defines the logic of the subscription on the subject.
+// The opts are accepted for the compatibility only: the fake always handles the messages one by one
+// and never drops them.
+func (s *SubPub) Subscribe(subject string, cb subpub.MessageHandler, opts ...subpub.SubscribeOpt) (subpub.Subscription, error) {
+	const op = "subpubtest.Subscribe"
+
+	if s.flagDone.Load() {
+		return nil, fmt.Errorf("error of the %s: %w: try to subscribe after the work done", op, subpub.ErrSystemCondition)
+	} else if cb == nil {
+		return nil, fmt.Errorf("error of the %s: %w: try to subscribe with the nil handler", op, subpub.ErrInputData)
+	} else if subject == "" {
+		return nil, fmt.Errorf("error of the %s: %w: try to subscribe on the empty subject", op, subpub.ErrInputData)
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if _, ok := s.delivered[subject]; !ok {
+		s.delivered[subject] = &atomic.Uint64{}
+	}
+
+	sub := &subscription{handler: cb, delivered: s.delivered[subject]}
+	sub.flagSub.Store(true)
+	s.subs[subject] = append(s.subs[subject], sub)
+
+	return sub, nil
+}
+
+// Publish defines the logic of the publishing the event.
+// It follows the rules of the subpub.SubPub: the subject must have been subscribed on before.
+// The opts are accepted for the compatibility only: the fake never discards the messages.
+func (s *SubPub) Publish(subject string, msg interface{}, opts ...subpub.PublishOpt) error {
+	const op = "subpubtest.Publish"
+
+	if s.flagDone.Load() {
+		return fmt.Errorf("error of the %s: %w: try to publish after the work done", op, subpub.ErrSystemCondition)
+	} else if subject == "" {
+		return fmt.Errorf("error of the %s: %w: try to publish into the empty subject", op, subpub.ErrInputData)
+	} else if msg == nil {
+		return fmt.Errorf("error of the %s: %w: try to publish the nil msg", op, subpub.ErrInputData)
+	}
+
+	s.mut.Lock()
+
+	if _, ok := s.subs[subject]; !ok {
+		s.mut.Unlock()
+		return fmt.Errorf("error of the %s: %w: try to publish into the unexisting channel", op, subpub.ErrInputData)
+	}
+	deliveries := s.publish(subject, msg)
+
+	s.mut.Unlock()
+
+	for _, d := range deliveries {
+		deliver(d.sub, d.msg)
+	}
+	return nil
+}
+
+// PublishTx checks every subject first and then publishes all the messages.
+// The publishing's opts of the messages are accepted but ignored.
+func (s *SubPub) PublishTx(ctx context.Context, msgs []subpub.SubjectMessage) error {
+	const op = "subpubtest.PublishTx"
+
+	if s.flagDone.Load() {
+		return fmt.Errorf("error of the %s: %w: try to publish after the work done", op, subpub.ErrSystemCondition)
+	} else if len(msgs) == 0 {
+		return fmt.Errorf("error of the %s: %w: try to publish the empty transaction", op, subpub.ErrInputData)
+	} else if err := ctx.Err(); err != nil {
+		return fmt.Errorf("error of the %s: %w: %s", op, subpub.ErrSystemCondition, err)
+	}
+
+	s.mut.Lock()
+
+	for i, m := range msgs {
+		if m.Msg == nil {
+			s.mut.Unlock()
+			return fmt.Errorf("error of the %s: %w: try to publish the nil msg %d", op, subpub.ErrInputData, i)
+		} else if _, ok := s.subs[m.Subject]; !ok {
+			s.mut.Unlock()
+			return fmt.Errorf("error of the %s: %w: try to publish into the unexisting channel '%s'", op, subpub.ErrInputData, m.Subject)
+		}
+	}
+
+	deliveries := make([]delivery, 0, len(msgs))
+	for _, m := range msgs {
+		deliveries = append(deliveries, s.publish(m.Subject, m.Msg)...)
+	}
+
+	s.mut.Unlock()
+
+	for _, d := range deliveries {
+		deliver(d.sub, d.msg)
+	}
+	return nil
+}
+
+// publish records the msg and returns its deliveries to the subject's active subscriptions.
+// The deliveries are queued instead if the ManualFlush is set.
+// Must be called with the mut locked.
+func (s *SubPub) publish(subject string, msg interface{}) []delivery {
+	active := make([]*subscription, 0, len(s.subs[subject]))
+	for _, sub := range s.subs[subject] {
+		if sub.flagSub.Load() {
+			active = append(active, sub)
+		}
+	}
+
+	if len(active) == 0 {
+		delete(s.subs, subject)
+		return nil
+	}
+	s.subs[subject] = active
+
+	s.Record(subject, msg)
+
+	deliveries := make([]delivery, 0, len(active))
+	for _, sub := range active {
+		deliveries = append(deliveries, delivery{sub: sub, msg: msg})
+	}
+
+	if s.manual {
+		s.pending = append(s.pending, deliveries...)
+		return nil
+	}
+	return deliveries
+}
+
+// Flush delivers the messages that were queued before the call in the FIFO order
+// and returns the count of the delivered messages.
+// The messages published by the handlers during the flush wait for the next call.
+func (s *SubPub) Flush() int {
+	s.mut.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mut.Unlock()
+
+	count := 0
+	for _, d := range pending {
+		if d.sub.flagSub.Load() && deliver(d.sub, d.msg) {
+			count++
+		}
+	}
+	return count
+}
+
+// Pending returns the count of the deliveries that wait for the Flush call.
+func (s *SubPub) Pending() int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return len(s.pending)
+}
+
+// Close shutdowns the fake delivering the queued messages.
+func (s *SubPub) Close(ctx context.Context) error {
+	const op = "subpubtest.Close"
+
+	s.flagDone.Store(true)
+
+	s.mut.Lock()
+	for _, entry := range s.scheduled {
+		entry.timer.Stop()
+	}
+	s.mut.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("error of the %s: fast shutdown: %s", op, ctx.Err())
+	default:
+		for s.Pending() != 0 {
+			s.Flush()
+		}
+
+		s.mut.Lock()
+		chanSubs := s.chanSubs
+		s.chanSubs = nil
+		s.mut.Unlock()
+
+		for _, c := range chanSubs {
+			c.Unsubscribe()
+		}
+	}
+	return nil
+}
+
+// deliver calls the subscription's handler recovering its panic as the subpub.SubPub does.
+// The msg is buffered if the subscription is paused: false is returned in this case.
+func deliver(sub *subscription, msg interface{}) bool {
+	sub.mut.Lock()
+	if sub.paused {
+		sub.buffered = append(sub.buffered, msg)
+		sub.mut.Unlock()
+		return false
+	}
+	sub.mut.Unlock()
+
+	sub.delivered.Add(1)
+	defer func() {
+		recover()
+	}()
+	sub.handler(msg)
+
+	return true
+}
+
+// Stats returns the counters of the subject's messages.
+func (s *SubPub) Stats(subject string) subpub.SubjectStats {
+	stats := subpub.SubjectStats{
+		Published: uint64(len(s.Published(subject))),
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if delivered, ok := s.delivered[subject]; ok {
+		stats.Delivered = delivered.Load()
+	}
+	return stats
+}
+
+// SetPolicy is accepted for the compatibility only: the fake doesn't apply the subjects' policies.
+func (s *SubPub) SetPolicy(string, subpub.SubjectPolicy) error {
+	return nil
+}
+
+// ResetPolicy is accepted for the compatibility only: the fake doesn't apply the subjects' policies.
+func (s *SubPub) ResetPolicy(string) error {
+	return nil
+}
+
+// Subjects returns the sorted names of the subjects that have the active subscriptions.
+func (s *SubPub) Subjects() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	subjects := make([]string, 0, len(s.subs))
+	for subject, subs := range s.subs {
+		for _, sub := range subs {
+			if sub.flagSub.Load() {
+				subjects = append(subjects, subject)
+				break
+			}
+		}
+	}
+	sort.Strings(subjects)
+
+	return subjects
+}
+
+// Purge drops the subject's deliveries waiting for the Flush call or buffered by the paused subscriptions.
+func (s *SubPub) Purge(subject string) int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	count := 0
+	subs := make(map[*subscription]struct{}, len(s.subs[subject]))
+
+	for _, sub := range s.subs[subject] {
+		subs[sub] = struct{}{}
+
+		sub.mut.Lock()
+		count += len(sub.buffered)
+		sub.buffered = nil
+		sub.mut.Unlock()
+	}
+
+	pending := make([]delivery, 0, len(s.pending))
+	for _, d := range s.pending {
+		if _, ok := subs[d.sub]; !ok {
+			pending = append(pending, d)
+		}
+	}
+	count += len(s.pending) - len(pending)
+	s.pending = pending
+
+	return count
+}
@@ -0,0 +1,161 @@
+package subpubtest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/MaKcm14/sub-pub/pkg/subpub"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishPositiveCases(t *testing.T) {
+	t.Run("TestPublishPositiveCases_SyncDelivery",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				testMessage = "test-message"
+				queue       = make([]string, 0, 10)
+			)
+			s := New()
+
+			sub, _ := s.Subscribe(testChannel, func(msg interface{}) {
+				queue = append(queue, msg.(string))
+			})
+
+			for i := 0; i != 3; i++ {
+				assert.NoError(t, s.Publish(testChannel, fmt.Sprintf("%s-%d", testMessage, i)),
+					"expected correct Publish work on the configured channel")
+			}
+			assert.Equal(t, []string{"test-message-0", "test-message-1", "test-message-2"}, queue,
+				"expected the delivery right inside the Publish call: actual wrong queue was got")
+
+			sub.Unsubscribe()
+			assert.NoError(t, s.Publish(testChannel, testMessage),
+				"expected the correct Publish executing after the cancelation the sub")
+			assert.Equal(t, 3, len(queue), "expected no delivery after the cancelation the sub")
+
+			AssertPublished(t, s.Recorder, testChannel, "test-message-0", "test-message-1", "test-message-2")
+		})
+
+	t.Run("TestPublishPositiveCases_ManualFlush",
+		func(t *testing.T) {
+			var (
+				testChannel = "test-channel"
+				testMessage = "test-message"
+				queue       = make([]string, 0, 10)
+			)
+			s := New(ManualFlush)
+
+			s.Subscribe(testChannel, func(msg interface{}) {
+				queue = append(queue, msg.(string))
+			})
+			s.Publish(testChannel, testMessage)
+			s.Publish(testChannel, testMessage)
+
+			assert.Equal(t, 0, len(queue), "expected no delivery before the flush")
+			assert.Equal(t, 2, s.Pending(), "expected the queued deliveries before the flush")
+
+			assert.Equal(t, 2, s.Flush(), "expected all the queued messages to be delivered")
+			assert.Equal(t, []string{testMessage, testMessage}, queue, "expected the delivery after the flush")
+			assert.Equal(t, 0, s.Pending(), "expected no queued deliveries after the flush")
+		})
+}
+
+func TestPublishNegativeCases(t *testing.T) {
+	s := New()
+	s.Subscribe("test-subject", func(msg interface{}) {})
+
+	closed := New()
+	closed.Close(context.Background())
+
+	tests := []struct {
+		name    string
+		s       *SubPub
+		subject string
+		msg     interface{}
+		want    error
+	}{
+		{"TestPublishNegativeCases_WrongSubject", s, "", "test-message", subpub.ErrInputData},
+		{"TestPublishNegativeCases_WrongMessage", s, "test-subject", nil, subpub.ErrInputData},
+		{"TestPublishNegativeCases_UnexistingChannel", s, "test-unexisting", "test-message", subpub.ErrInputData},
+		{"TestPublishNegativeCases_WrongState", closed, "test-subject", "test-message", subpub.ErrSystemCondition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, tt.s.Publish(tt.subject, tt.msg), tt.want)
+		})
+	}
+	assert.Equal(t, 0, s.Len(), "expected no records after the failed publishing")
+}
+
+func TestClose(t *testing.T) {
+	t.Run("TestClose_FlushesPending",
+		func(t *testing.T) {
+			count := 0
+			s := New(ManualFlush)
+
+			s.Subscribe("test-channel", func(msg interface{}) {
+				count++
+			})
+			s.Publish("test-channel", "test-message")
+
+			assert.NoError(t, s.Close(context.Background()), "expected nil error after closing")
+			assert.Equal(t, 1, count, "expected the pending messages to be delivered on close")
+		})
+}
+
+func TestPauseResume(t *testing.T) {
+	queue := make([]string, 0, 10)
+	s := New()
+
+	sub, _ := s.Subscribe("test-channel", func(msg interface{}) {
+		queue = append(queue, msg.(string))
+	})
+	sub.Pause()
+
+	s.Publish("test-channel", "test-message-0")
+	s.Publish("test-channel", "test-message-1")
+	assert.Equal(t, 0, len(queue), "expected no delivery while the subscription is paused")
+
+	sub.Resume()
+	assert.Equal(t, []string{"test-message-0", "test-message-1"}, queue,
+		"expected the buffered messages to be delivered right inside the Resume call")
+}
+
+func TestPublishTx(t *testing.T) {
+	s := New()
+
+	s.Subscribe("orders.created", func(msg interface{}) {})
+	s.Subscribe("billing.pending", func(msg interface{}) {})
+
+	err := s.PublishTx(context.Background(), []subpub.SubjectMessage{
+		{Subject: "orders.created", Msg: "order-0"},
+		{Subject: "billing.pending", Msg: "bill-0"},
+	})
+	assert.NoError(t, err, "expected nil error after the transactional publishing")
+
+	err = s.PublishTx(context.Background(), []subpub.SubjectMessage{
+		{Subject: "orders.created", Msg: "order-1"},
+		{Subject: "unexisting", Msg: "msg"},
+	})
+	assert.ErrorIs(t, err, subpub.ErrInputData, "expected error after the publishing into the unexisting subject")
+
+	AssertPublished(t, s.Recorder, "orders.created", "order-0")
+	AssertPublished(t, s.Recorder, "billing.pending", "bill-0")
+}
+
+func TestPurge(t *testing.T) {
+	s := New(ManualFlush)
+
+	s.Subscribe("test-channel", func(msg interface{}) {})
+	s.Subscribe("test-channel-other", func(msg interface{}) {})
+
+	s.Publish("test-channel", "test-message")
+	s.Publish("test-channel-other", "test-message")
+
+	assert.Equal(t, []string{"test-channel", "test-channel-other"}, s.Subjects(), "expected the sorted subjects")
+	assert.Equal(t, 1, s.Purge("test-channel"), "expected the subject's pending delivery to be purged")
+	assert.Equal(t, 1, s.Flush(), "expected the other subject's delivery to be kept")
+}
@@ -0,0 +1,125 @@
+package subpub
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SubjectMessage defines the single message of the transactional publishing.
+type SubjectMessage struct {
+	Subject string
+	Msg     interface{}
+	Opts    []PublishOpt
+}
+
+// validateTx checks the messages of the transaction and returns their publishing's configurations.
+func validateTx(msgs []SubjectMessage) ([]pubOpts, error) {
+	const op = "subpub.validateTx"
+
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("error of the %s: %w: try to publish the empty transaction", op, ErrInputData)
+	}
+
+	opts := make([]pubOpts, 0, len(msgs))
+	for i, m := range msgs {
+		o := applyPubOpts(m.Opts)
+
+		if m.Subject == "" {
+			return nil, fmt.Errorf("error of the %s: %w: try to publish the message %d into the empty subject", op, ErrInputData, i)
+		} else if m.Msg == nil {
+			return nil, fmt.Errorf("error of the %s: %w: try to publish the nil msg %d", op, ErrInputData, i)
+		} else if o.ttl < 0 {
+			return nil, fmt.Errorf("error of the %s: %w: try to publish the message %d with the negative ttl", op, ErrInputData, i)
+		}
+		opts = append(opts, o)
+	}
+	return opts, nil
+}
+
+// PublishTx defines the logic of the atomic publishing into the several subjects.
+func (e *eventChannel) PublishTx(ctx context.Context, msgs []SubjectMessage) error {
+	const op = "subpub.PublishTx"
+
+	if e.flagDone.Load() {
+		return fmt.Errorf("error of the %s: %w: try to publish after the work done", op, ErrSystemCondition)
+	}
+
+	opts, err := validateTx(msgs)
+	if err != nil {
+		return fmt.Errorf("error of the %s: %w", op, err)
+	}
+
+	e.mut.Lock()
+
+	if e.flagDone.Load() {
+		e.mut.Unlock()
+		return fmt.Errorf("error of the %s: %w: try to publish after the work done", op, ErrSystemCondition)
+	} else if err := ctx.Err(); err != nil {
+		e.mut.Unlock()
+		return fmt.Errorf("error of the %s: %w: %s", op, ErrSystemCondition, err)
+	}
+
+	for _, m := range msgs {
+		if _, ok := e.channels[m.Subject]; !ok {
+			e.mut.Unlock()
+			return fmt.Errorf("error of the %s: %w: try to publish into the unexisting channel '%s'", op, ErrInputData, m.Subject)
+		}
+	}
+
+	held := make(map[*channelSub]struct{})
+	for i, m := range msgs {
+		subs, _ := e.publish(m.Subject, m.Msg, opts[i], true)
+		for _, sub := range subs {
+			held[sub] = struct{}{}
+		}
+	}
+	events := e.takeEvents()
+
+	// the Close waits for the waking of the held messages
+	e.wg.Add(1)
+	defer e.wg.Done()
+
+	e.mut.Unlock()
+
+	e.reportAll(events)
+	for sub := range held {
+		sub.wake()
+	}
+
+	return nil
+}
+
+// PublishTxAt defines the logic of the atomic publishing into the several subjects at the definite time.
+func (e *eventChannel) PublishTxAt(ctx context.Context, msgs []SubjectMessage, at time.Time) (string, error) {
+	const op = "subpub.PublishTxAt"
+
+	if e.flagDone.Load() {
+		return "", fmt.Errorf("error of the %s: %w: try to publish after the work done", op, ErrSystemCondition)
+	}
+
+	opts, err := validateTx(msgs)
+	if err != nil {
+		return "", fmt.Errorf("error of the %s: %w", op, err)
+	} else if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("error of the %s: %w: %s", op, ErrSystemCondition, err)
+	}
+
+	scheduled := make([]ScheduledMessage, 0, len(msgs))
+	for i, m := range msgs {
+		scheduled = append(scheduled, ScheduledMessage{
+			Subject:   m.Subject,
+			Msg:       m.Msg,
+			DeliverAt: at,
+			TTL:       opts[i].ttl,
+			Priority:  opts[i].priority,
+			MsgID:     opts[i].msgID,
+		})
+	}
+
+	id, err := e.sched.scheduleTx(scheduled)
+	if err != nil {
+		return "", fmt.Errorf("error of the %s: %w", op, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,188 @@
+package subpub
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublishTx(t *testing.T) {
+	t.Run("TestPublishTxPositiveCases_AllSubjects",
+		func(t *testing.T) {
+			var (
+				mut   sync.Mutex
+				queue = make([]string, 0, 3)
+			)
+			e := newEventChannel()
+
+			handler := func(msg interface{}) {
+				mut.Lock()
+				defer mut.Unlock()
+
+				queue = append(queue, msg.(string))
+			}
+			e.Subscribe("orders.created", handler)
+			e.Subscribe("billing.pending", handler)
+
+			err := e.PublishTx(context.Background(), []SubjectMessage{
+				{Subject: "orders.created", Msg: "order-0"},
+				{Subject: "billing.pending", Msg: "bill-0"},
+				{Subject: "orders.created", Msg: "order-1"},
+			})
+			assert.NoError(t, err, "expected nil error after the transactional publishing")
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.ElementsMatch(t, []string{"order-0", "bill-0", "order-1"}, queue, "expected all the messages to be delivered")
+			assert.Equal(t, uint64(2), e.Stats("orders.created").Published, "expected the messages to be counted by their subjects")
+		})
+
+	t.Run("TestPublishTxPositiveCases_BusyHandlerWaitsForTx",
+		func(t *testing.T) {
+			var (
+				txDone  atomic.Bool
+				early   atomic.Bool
+				started = make(chan struct{})
+				release = make(chan struct{})
+			)
+			e := newEventChannel()
+
+			sub, _ := e.Subscribe("orders.created", func(msg interface{}) {
+				if msg == "order-warm" {
+					close(started)
+					<-release
+				} else if !txDone.Load() {
+					early.Store(true)
+				}
+			})
+			orders := sub.(*channelSub)
+
+			// the key is taken inside the transaction: the busy handler is finished between its messages.
+			e.Subscribe("billing.pending", func(interface{}) {}, WithKeyOrdered(func(interface{}) string {
+				close(release)
+				for {
+					orders.mut.Lock()
+					inflight := orders.inflight
+					orders.mut.Unlock()
+
+					if inflight == 0 {
+						return ""
+					}
+					runtime.Gosched()
+				}
+			}, 1))
+
+			e.Publish("orders.created", "order-warm")
+			<-started
+
+			err := e.PublishTx(context.Background(), []SubjectMessage{
+				{Subject: "orders.created", Msg: "order-0"},
+				{Subject: "billing.pending", Msg: "bill-0"},
+			})
+			txDone.Store(true)
+			assert.NoError(t, err, "expected nil error after the transactional publishing")
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.False(t, early.Load(), "expected the busy handler not to take the message before the transaction's end")
+		})
+
+	t.Run("TestPublishTxPositiveCases_ScheduledWithStore",
+		func(t *testing.T) {
+			var (
+				path  = filepath.Join(t.TempDir(), "schedule.json")
+				clock = newFakeClock(time.Now())
+				queue = make([]string, 0, 2)
+			)
+			store, _ := NewFileScheduleStore(path)
+
+			e := newEventChannel(WithClock(clock), WithScheduleStore(store))
+			id, err := e.PublishTxAt(context.Background(), []SubjectMessage{
+				{Subject: "orders.created", Msg: "order-0"},
+				{Subject: "billing.pending", Msg: "bill-0"},
+			}, clock.Now().Add(time.Minute))
+			assert.NoError(t, err, "expected nil error after the transaction's scheduling")
+			e.Close(context.Background())
+
+			msgs, _ := store.Load()
+			if assert.Len(t, msgs, 2, "expected the whole transaction to be stored") {
+				assert.Equal(t, id, msgs[0].TxID, "expected the messages to keep the transaction's id")
+			}
+
+			store, _ = NewFileScheduleStore(path)
+			clock = newFakeClock(clock.Now())
+			e = newEventChannel(WithClock(clock), WithScheduleStore(store))
+
+			e.Subscribe("orders.created", func(msg interface{}) { queue = append(queue, msg.(string)) })
+			e.Subscribe("billing.pending", func(msg interface{}) {})
+			clock.Advance(time.Minute)
+
+			assert.NoError(t, e.Close(context.Background()), "expected nil error after closing")
+			assert.Equal(t, []string{"order-0"}, queue, "expected the restored transaction to be published")
+			assert.Equal(t, uint64(1), e.Stats("billing.pending").Published, "expected the whole transaction to be published")
+
+			msgs, _ = store.Load()
+			assert.Empty(t, msgs, "expected the fired transaction to be removed from the store")
+		})
+
+	t.Run("TestPublishTxPositiveCases_CancelScheduled",
+		func(t *testing.T) {
+			clock := newFakeClock(time.Now())
+			e := newEventChannel(WithClock(clock))
+			defer e.Close(context.Background())
+
+			id, _ := e.PublishTxAt(context.Background(), []SubjectMessage{
+				{Subject: "orders.created", Msg: "order-0"},
+				{Subject: "billing.pending", Msg: "bill-0"},
+			}, clock.Now().Add(time.Minute))
+
+			assert.Len(t, e.Scheduled(), 2, "expected every message of the transaction to be listed")
+			assert.NoError(t, e.CancelScheduled(id), "expected nil error after the transaction's canceling")
+			assert.Empty(t, e.Scheduled(), "expected the whole transaction to be canceled")
+		})
+}
+
+func TestPublishTxNegativeCases(t *testing.T) {
+	var (
+		delivered = false
+		e         = newEventChannel()
+	)
+	defer e.Close(context.Background())
+
+	e.Subscribe("orders.created", func(msg interface{}) { delivered = true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		msgs []SubjectMessage
+	}{
+		{"TestPublishTxNegativeCases_Empty", context.Background(), nil},
+		{"TestPublishTxNegativeCases_UnexistingSubject", context.Background(), []SubjectMessage{
+			{Subject: "orders.created", Msg: "order-0"},
+			{Subject: "billing.pending", Msg: "bill-0"},
+		}},
+		{"TestPublishTxNegativeCases_NilMsg", context.Background(), []SubjectMessage{
+			{Subject: "orders.created", Msg: "order-0"},
+			{Subject: "orders.created", Msg: nil},
+		}},
+		{"TestPublishTxNegativeCases_CanceledCtx", ctx, []SubjectMessage{
+			{Subject: "orders.created", Msg: "order-0"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, e.PublishTx(tt.ctx, tt.msgs), "expected error after the incorrect transaction")
+		})
+	}
+
+	assert.False(t, delivered, "expected no messages of the failed transactions to be delivered")
+	assert.Equal(t, SubjectStats{}, e.Stats("orders.created"), "expected no messages of the failed transactions to be published")
+}
@@ -2,7 +2,10 @@ package client
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io"
 	"os"
 	"testing"
 	"time"
@@ -11,14 +14,22 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/suite"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type ClientSuite struct {
 	suite.Suite
 	client sprpc.PubSubClient
+	admin  sprpc.AdminClient
 
-	conn *grpc.ClientConn
+	conn      *grpc.ClientConn
+	adminConn *grpc.ClientConn
 }
 
 func newClientSuite() *ClientSuite {
@@ -33,8 +44,17 @@ func newClientSuite() *ClientSuite {
 		panic(fmt.Sprintf("error of the %s: the SOCKET var is empty", op))
 	}
 
-	conn, err := grpc.NewClient(os.Getenv("SOCKET"),
-		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	creds, err := transportCredentials()
+	if err != nil {
+		panic(fmt.Sprintf("error of the %s: %s", op, err))
+	}
+
+	dialOpts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if token := os.Getenv("CLIENT_TOKEN"); token != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(tokenCredentials(token)))
+	}
+
+	conn, err := grpc.NewClient(os.Getenv("SOCKET"), dialOpts...)
 
 	if err != nil {
 		panic(fmt.Sprintf("error of the %s: %s", op, err))
@@ -42,9 +62,63 @@ func newClientSuite() *ClientSuite {
 	c.client = sprpc.NewPubSubClient(conn)
 	c.conn = conn
 
+	adminSocket := os.Getenv("ADMIN_SOCKET")
+	if adminSocket == "" {
+		adminSocket = socket
+	}
+
+	adminConn, err := grpc.NewClient(adminSocket, dialOpts...)
+
+	if err != nil {
+		panic(fmt.Sprintf("error of the %s: %s", op, err))
+	}
+	c.admin = sprpc.NewAdminClient(adminConn)
+	c.adminConn = adminConn
+
 	return c
 }
 
+// tokenCredentials defines the bearer token sent with every request.
+type tokenCredentials string
+
+func (t tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + string(t)}, nil
+}
+
+func (t tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// transportCredentials returns the TLS credentials if the CLIENT_TLS_CA var is set,
+// the client's certificate is presented if the CLIENT_TLS_CERT and CLIENT_TLS_KEY vars are set.
+func transportCredentials() (credentials.TransportCredentials, error) {
+	caFile := os.Getenv("CLIENT_TLS_CA")
+	if caFile == "" {
+		return insecure.NewCredentials(), nil
+	}
+
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates were found in the %s", caFile)
+	}
+	conf := &tls.Config{RootCAs: pool}
+
+	if certFile, keyFile := os.Getenv("CLIENT_TLS_CERT"), os.Getenv("CLIENT_TLS_KEY"); certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(conf), nil
+}
+
 func (c *ClientSuite) startPublisherCommonWork(msg []*sprpc.PublishRequest) {
 	time.Sleep(time.Second * 5)
 	for _, req := range msg {
@@ -75,12 +149,21 @@ func (c *ClientSuite) TestPositiveCases_PubSubCommonWork() {
 
 	defer stream.CloseSend()
 
+	first, err := stream.Recv()
+	c.Suite.NoError(err, fmt.Sprintf("expected correct receiving of the subscription's id: error was got: %s", err))
+	c.Suite.NotZero(first.SubscriptionId, "expected the subscription's id as the first event")
+	c.Suite.Equal(sprpc.EventType_EVENT_TYPE_SUBSCRIBED, first.Type, "expected the subscription's confirmation as the first event")
+
 	go c.startPublisherCommonWork(msg)
 
 	for _, req := range msg {
 		ev, err := stream.Recv()
+		for err == nil && ev.Type == sprpc.EventType_EVENT_TYPE_HEARTBEAT {
+			ev, err = stream.Recv()
+		}
 
 		c.Suite.NoError(err, fmt.Sprintf("expected correct receiving from Recv: error was got: %s", err))
+		c.Suite.Equal(sprpc.EventType_EVENT_TYPE_DATA, ev.Type, "expected the type of the message's event")
 
 		c.Suite.Equal(req.Data, ev.Data,
 			fmt.Sprintf("expected correct order for the corresponding data msg: \nExp: %s <=> Got: %s",
@@ -111,6 +194,20 @@ func (c *ClientSuite) TestPublishNegativeCases_PublishEmptyChannel() {
 	c.Suite.Error(err, "expected error after the publishing with empty channel")
 }
 
+func (c *ClientSuite) TestPublishNegativeCases_PublishNegativeTTL() {
+	var (
+		testChannel = "test-channel-ttl"
+		testMessage = "test-message"
+	)
+
+	_, err := c.client.Publish(context.Background(), &sprpc.PublishRequest{
+		Key:  testChannel,
+		Data: testMessage,
+		Ttl:  durationpb.New(-time.Second),
+	})
+	c.Suite.Error(err, "expected error after the publishing with the negative ttl")
+}
+
 func (c *ClientSuite) TestSubscribeNegativeCases_SubscribeEmptySubject() {
 	stream, err := c.client.Subscribe(context.Background(), &sprpc.SubscribeRequest{
 		Key: "",
@@ -121,8 +218,221 @@ func (c *ClientSuite) TestSubscribeNegativeCases_SubscribeEmptySubject() {
 	c.Suite.Error(err, "expected error after using the stream with incorrect channel config")
 }
 
+func (c *ClientSuite) TestPauseNegativeCases_PauseUnexistingSubscription() {
+	_, err := c.admin.PauseSubscription(context.Background(), &sprpc.SubscriptionRequest{
+		Id: -1,
+	})
+	c.Suite.Error(err, "expected error after the pausing of the unexisting subscription")
+
+	_, err = c.admin.ResumeSubscription(context.Background(), &sprpc.SubscriptionRequest{
+		Id: -1,
+	})
+	c.Suite.Error(err, "expected error after the resuming of the unexisting subscription")
+}
+
+func (c *ClientSuite) TestPositiveCases_ScheduledPublishing() {
+	var (
+		testChannel = "test-channel-scheduled"
+		testMessage = "test-message"
+	)
+
+	resp, err := c.client.Publish(context.Background(), &sprpc.PublishRequest{
+		Key:       testChannel,
+		Data:      testMessage,
+		DeliverAt: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	c.Suite.NoError(err, "expected no error after the scheduled publishing")
+	c.Suite.NotEmpty(resp.ScheduleId, "expected the id of the scheduled message")
+
+	list, err := c.client.ListScheduled(context.Background(), &emptypb.Empty{})
+	c.Suite.NoError(err, "expected no error after the scheduled messages' listing")
+
+	found := false
+	for _, msg := range list.Messages {
+		if msg.Id == resp.ScheduleId {
+			found = true
+			c.Suite.Equal(testMessage, msg.Data, "expected the scheduled message's data")
+		}
+	}
+	c.Suite.True(found, "expected the scheduled message in the list")
+
+	_, err = c.client.CancelScheduled(context.Background(), &sprpc.ScheduledRequest{Id: resp.ScheduleId})
+	c.Suite.NoError(err, "expected no error after the canceling")
+
+	_, err = c.client.CancelScheduled(context.Background(), &sprpc.ScheduledRequest{Id: resp.ScheduleId})
+	c.Suite.Error(err, "expected error after the canceling of the unexisting scheduled message")
+}
+
+func (c *ClientSuite) TestPublishTxNegativeCases_UnexistingChannel() {
+	_, err := c.client.PublishTx(context.Background(), &sprpc.PublishTxRequest{
+		Messages: []*sprpc.PublishRequest{
+			{Key: "test-channel-tx-unexists-0", Data: "test-message"},
+			{Key: "test-channel-tx-unexists-1", Data: "test-message"},
+		},
+	})
+	c.Suite.Error(err, "expected error after the transactional publishing into the unexisting channels")
+}
+
+func (c *ClientSuite) TestPositiveCases_ScheduledPublishTx() {
+	resp, err := c.client.PublishTx(context.Background(), &sprpc.PublishTxRequest{
+		Messages: []*sprpc.PublishRequest{
+			{Key: "test-channel-tx-0", Data: "test-message"},
+			{Key: "test-channel-tx-1", Data: "test-message"},
+		},
+		DeliverAt: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	c.Suite.NoError(err, "expected no error after the transaction's scheduling")
+
+	_, err = c.client.CancelScheduled(context.Background(), &sprpc.ScheduledRequest{Id: resp.ScheduleId})
+	c.Suite.NoError(err, "expected no error after the transaction's canceling")
+}
+
+func (c *ClientSuite) TestPositiveCases_Connect() {
+	var (
+		testChannel = "test-channel-connect"
+		testMessage = "test-message"
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	defer cancel()
+
+	stream, err := c.client.Connect(ctx)
+	c.Suite.NoError(err, "expected no error after the connecting")
+
+	c.Suite.NoError(stream.Send(&sprpc.ClientFrame{
+		CorrelationId: "flow",
+		Op:            &sprpc.ClientFrame_Flow{Flow: &sprpc.FlowOp{Window: 1}},
+	}))
+	c.Suite.NoError(stream.Send(&sprpc.ClientFrame{
+		CorrelationId: "sub",
+		Op:            &sprpc.ClientFrame_Subscribe{Subscribe: &sprpc.SubscribeRequest{Key: testChannel}},
+	}))
+
+	results := make(map[string]*sprpc.OpResult)
+	for len(results) != 2 {
+		frame, err := stream.Recv()
+		c.Suite.Require().NoError(err, "expected no error after the receiving")
+		results[frame.GetResult().CorrelationId] = frame.GetResult()
+	}
+	c.Suite.Zero(results["sub"].Code, "expected the successful subscribing")
+
+	for i := 0; i != 2; i++ {
+		c.Suite.NoError(stream.Send(&sprpc.ClientFrame{
+			CorrelationId: fmt.Sprintf("pub-%d", i),
+			Op:            &sprpc.ClientFrame_Publish{Publish: &sprpc.PublishRequest{Key: testChannel, Data: testMessage}},
+		}))
+	}
+
+	events := make([]*sprpc.Delivery, 0, 2)
+	for len(events) != 2 {
+		frame, err := stream.Recv()
+		c.Suite.Require().NoError(err, "expected no error after the receiving")
+
+		if event := frame.GetEvent(); event != nil {
+			c.Suite.Equal(results["sub"].SubscriptionId, event.SubscriptionId, "expected the event of the subscription")
+			c.Suite.Equal(testMessage, event.Data, "expected the published data")
+			events = append(events, event)
+
+			c.Suite.NoError(stream.Send(&sprpc.ClientFrame{
+				Op: &sprpc.ClientFrame_Ack{Ack: &sprpc.AckOp{DeliveryId: event.DeliveryId}},
+			}))
+		} else {
+			c.Suite.Zero(frame.GetResult().Code, "expected the successful publishing")
+		}
+	}
+
+	c.Suite.NoError(stream.Send(&sprpc.ClientFrame{
+		CorrelationId: "unsub",
+		Op:            &sprpc.ClientFrame_Unsubscribe{Unsubscribe: &sprpc.UnsubscribeOp{SubscriptionId: -1}},
+	}))
+	for {
+		frame, err := stream.Recv()
+		c.Suite.Require().NoError(err, "expected no error after the receiving")
+
+		if frame.GetResult().GetCorrelationId() == "unsub" {
+			c.Suite.NotZero(frame.GetResult().Code, "expected error after the unsubscribing of the unexisting subscription")
+			break
+		}
+	}
+
+	c.Suite.NoError(stream.CloseSend(), "expected no error after the stream's closing")
+}
+
+func (c *ClientSuite) TestPositiveCases_Unsubscribe() {
+	testChannel := "test-channel-unsubscribe"
+
+	stream, err := c.client.Subscribe(context.Background(), &sprpc.SubscribeRequest{
+		Key: testChannel,
+	})
+	c.Suite.NoError(err, "expected no error after the subscribing")
+
+	first, err := stream.Recv()
+	c.Suite.Require().NoError(err, "expected no error after the receiving of the subscription's id")
+
+	list, err := c.admin.ListSubscriptions(context.Background(), &emptypb.Empty{})
+	c.Suite.NoError(err, "expected no error after the subscriptions' listing")
+
+	found := false
+	for _, info := range list.Subscriptions {
+		if info.Id == first.SubscriptionId {
+			found = true
+			c.Suite.Equal(testChannel, info.Key, "expected the subscription's subject")
+			c.Suite.NotEmpty(info.ClientAddr, "expected the client's address")
+		}
+	}
+	c.Suite.True(found, "expected the subscription in the list")
+
+	_, err = c.client.Unsubscribe(context.Background(), &sprpc.SubscriptionRequest{Id: first.SubscriptionId})
+	c.Suite.NoError(err, "expected no error after the unsubscribing")
+
+	_, err = stream.Recv()
+	c.Suite.ErrorIs(err, io.EOF, "expected the stream to be finished after the unsubscribing")
+
+	_, err = c.client.Unsubscribe(context.Background(), &sprpc.SubscriptionRequest{Id: first.SubscriptionId})
+	c.Suite.Error(err, "expected error after the second unsubscribing")
+}
+
+func (c *ClientSuite) TestPositiveCases_Admin() {
+	testChannel := "test-channel-admin"
+
+	stream, err := c.client.Subscribe(context.Background(), &sprpc.SubscribeRequest{
+		Key: testChannel,
+	})
+	c.Suite.NoError(err, "expected no error after the subscribing")
+
+	first, err := stream.Recv()
+	c.Suite.Require().NoError(err, "expected no error after the receiving of the subscription's id")
+
+	subjects, err := c.admin.ListSubjects(context.Background(), &emptypb.Empty{})
+	c.Suite.NoError(err, "expected no error after the subjects' listing")
+	c.Suite.Contains(subjects.Keys, testChannel, "expected the subscribed subject in the list")
+
+	_, err = c.admin.PauseSubscription(context.Background(), &sprpc.SubscriptionRequest{Id: first.SubscriptionId})
+	c.Suite.NoError(err, "expected no error after the pausing")
+
+	_, err = c.client.Publish(context.Background(), &sprpc.PublishRequest{Key: testChannel, Data: "test-message"})
+	c.Suite.NoError(err, "expected no error after the publishing")
+
+	purged, err := c.admin.PurgeSubject(context.Background(), &sprpc.SubjectRequest{Key: testChannel})
+	c.Suite.NoError(err, "expected no error after the purging")
+	c.Suite.Equal(uint64(1), purged.Purged, "expected the message of the paused subscription to be purged")
+
+	stats, err := c.admin.GetSubjectStats(context.Background(), &sprpc.SubjectRequest{Key: testChannel})
+	c.Suite.NoError(err, "expected no error after the stats' getting")
+	c.Suite.Equal(uint64(1), stats.Published, "expected the published message to be counted")
+	c.Suite.Equal(uint64(1), stats.Dropped, "expected the purged message to be counted")
+	c.Suite.Equal(uint32(1), stats.Subscriptions, "expected the single subscription on the subject")
+
+	_, err = c.admin.KickSubscription(context.Background(), &sprpc.SubscriptionRequest{Id: first.SubscriptionId})
+	c.Suite.NoError(err, "expected no error after the kicking")
+
+	_, err = stream.Recv()
+	c.Suite.Equal(codes.Aborted, status.Code(err), "expected the kicked subscription's stream to be aborted")
+}
+
 func (c *ClientSuite) close() {
 	c.conn.Close()
+	c.adminConn.Close()
 }
 
 func TestClient(t *testing.T) {